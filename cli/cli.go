@@ -6,12 +6,16 @@ func NewViewCreatorCommand() *cobra.Command {
 	view := MakeViewCommand()
 	tool := MakeToolsCommand()
 	wallet := MakeWalletCommand()
+	lens := MakeLensCommand()
+	config := MakeConfigCommand()
 
 	root := MakeRootCommand()
 	root.AddCommand(
 		view,
 		tool,
 		wallet,
+		lens,
+		config,
 	)
 
 	return root
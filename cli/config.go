@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"encoding/json"
+
+	"github.com/shinzonetwork/view-creator/core/config"
+	"github.com/spf13/cobra"
+)
+
+func MakeConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Show or change viewkit settings",
+		Long:  "Settings are stored in ~/.shinzo/config.json. Keys are dotted paths such as deploy.maxPayload.",
+	}
+
+	cmd.AddCommand(MakeConfigShowCommand())
+	cmd.AddCommand(MakeConfigSetCommand())
+
+	return cmd
+}
+
+func MakeConfigShowCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show",
+		Short: "Print the effective configuration",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			encoded, err := json.MarshalIndent(cfg, "", "  ")
+			if err != nil {
+				return err
+			}
+			cmd.Println(string(encoded))
+			return nil
+		},
+	}
+}
+
+func MakeConfigSetCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Set a configuration value",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			cfg, err = config.Set(cfg, args[0], args[1])
+			if err != nil {
+				return err
+			}
+
+			if err := config.Save(cfg); err != nil {
+				return err
+			}
+			cmd.Printf("✅ %s set to %s\n", args[0], args[1])
+			return nil
+		},
+	}
+}
@@ -0,0 +1,17 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func MakeLensCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lens",
+		Short: "Commands for developing lenses",
+		Long:  "Use this command group to create WebAssembly lens projects that can be added to views.",
+	}
+
+	cmd.AddCommand(MakeLensNewCommand())
+
+	return cmd
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/spf13/cobra"
+)
+
+func MakeLensNewCommand() *cobra.Command {
+	var lang string
+	var dir string
+
+	cmd := &cobra.Command{
+		Use:   "new <name>",
+		Short: "Generate a ready-to-build lens project",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			target, err := service.ScaffoldLens(name, lang, dir)
+			if err != nil {
+				return fmt.Errorf("failed to create lens project: %w", err)
+			}
+
+			cmd.Printf("✅ Lens project created at %s\n", target)
+			cmd.Println("Next steps:")
+			cmd.Printf("  cd %s && make build\n", target)
+			cmd.Printf("  viewkit view add lens --label %s --path %s --args \"$(cat %s)\" --name <view>\n",
+				name,
+				filepath.Join(target, "build", name+".wasm"),
+				filepath.Join(target, "args.json"),
+			)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&lang, "lang", "", "Lens language: "+strings.Join(service.LensLanguages(), ", ")+" (required)")
+	cmd.Flags().StringVar(&dir, "dir", ".", "Directory to create the project in")
+
+	cmd.MarkFlagRequired("lang")
+	return cmd
+}
@@ -0,0 +1,68 @@
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/cli"
+)
+
+func TestLensNewCommandScaffoldsEachLanguage(t *testing.T) {
+	expectedFiles := map[string][]string{
+		"rust":           {"Cargo.toml", "Makefile", "src/lib.rs", "src/lens.rs"},
+		"assemblyscript": {"package.json", "asconfig.json", "Makefile", "assembly/index.ts", "assembly/lens.ts"},
+		"tinygo":         {"go.mod", "Makefile", "main.go", "lens.go"},
+	}
+
+	for lang, files := range expectedFiles {
+		t.Run(lang, func(t *testing.T) {
+			tempDir := t.TempDir()
+
+			cmd := cli.MakeLensNewCommand()
+			cmd.SetArgs([]string{"my-filter", "--lang", lang, "--dir", tempDir})
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+			cmd.SetErr(&out)
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("lens new failed: %v", err)
+			}
+
+			project := filepath.Join(tempDir, "my-filter")
+			for _, f := range append(files, "README.md", "args.json", "fixtures/input.json") {
+				if _, err := os.Stat(filepath.Join(project, f)); err != nil {
+					t.Errorf("expected %s to exist: %v", f, err)
+				}
+			}
+
+			makefile, err := os.ReadFile(filepath.Join(project, "Makefile"))
+			if err != nil {
+				t.Fatalf("failed to read Makefile: %v", err)
+			}
+			if !strings.Contains(string(makefile), "build/$(NAME).wasm") || !strings.Contains(string(makefile), "NAME") {
+				t.Errorf("Makefile does not produce a wasm output:\n%s", makefile)
+			}
+
+			if !strings.Contains(out.String(), "view add lens --label my-filter") {
+				t.Errorf("unexpected output:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func TestLensNewCommandRejectsUnknownLanguage(t *testing.T) {
+	cmd := cli.MakeLensNewCommand()
+	cmd.SetArgs([]string{"my-filter", "--lang", "cobol", "--dir", t.TempDir()})
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "unsupported lens language") {
+		t.Errorf("expected unsupported language error, got: %v", err)
+	}
+}
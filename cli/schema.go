@@ -1,6 +1,9 @@
 package cli
 
 import (
+	"fmt"
+
+	"github.com/shinzonetwork/view-creator/core/service"
 	"github.com/spf13/cobra"
 )
 
@@ -12,6 +15,10 @@ func MakeSchemaCommand() *cobra.Command {
 			if err := setContextSchemaStore(cmd); err != nil {
 				return err
 			}
+			// views are scanned for usages before the schema changes
+			if err := setContextViewStore(cmd); err != nil {
+				return err
+			}
 
 			return nil
 		},
@@ -23,6 +30,23 @@ func MakeSchemaCommand() *cobra.Command {
 	cmd.AddCommand(MakeSchemaRemoveCommand())
 	cmd.AddCommand(MakeSchemaInspectCommand())
 	cmd.AddCommand(MakeSchemaResetCommand())
+	cmd.AddCommand(MakeSchemaFieldCommand())
+	cmd.AddCommand(MakeSchemaEditCommand())
+	cmd.AddCommand(MakeSchemaUsagesCommand())
+	cmd.AddCommand(MakeSchemaProfileCommand())
+	cmd.AddCommand(MakeSchemaLintCommand())
+	cmd.AddCommand(MakeSchemaDocsCommand())
+	cmd.AddCommand(MakeSchemaGraphCommand())
 
 	return cmd
 }
+
+func schemaChangeOptions(cmd *cobra.Command, force bool) service.SchemaChangeOptions {
+	return service.SchemaChangeOptions{
+		Views: getContextViewStore(cmd),
+		Force: force,
+		Warn: func(v service.SkippedView) {
+			fmt.Fprintf(cmd.OutOrStdout(), "⚠️  Not checked, query does not resolve: %s\n", v)
+		},
+	}
+}
@@ -10,8 +10,10 @@ import (
 func MakeSchemaAddCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "add <schema>",
-		Short: "Add a custom schema type to the viewkit schema",
-		Args:  cobra.ExactArgs(1),
+		Short: "Add custom schema definitions (type, interface, union, enum, scalar, input) to the viewkit schema",
+		Example: `  viewkit tools schema add 'enum TokenStandard { ERC20 ERC721 }'
+  viewkit tools schema add 'scalar Hex'`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			schema := args[0]
 			schemastore := mustGetContextSchemaStore(cmd)
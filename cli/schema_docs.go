@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/spf13/cobra"
+)
+
+func MakeSchemaDocsCommand() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "docs",
+		Short: "Render documentation for every schema type",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schemastore := mustGetContextSchemaStore(cmd)
+
+			docs, err := service.SchemaDocs(schemastore, format)
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(cmd.OutOrStdout(), docs)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "markdown", "Output format: markdown or html")
+	return cmd
+}
+
+func MakeSchemaGraphCommand() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "graph",
+		Short: "Render the relation graph derived from @relation directives",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schemastore := mustGetContextSchemaStore(cmd)
+
+			graph, err := service.SchemaGraph(schemastore, format)
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(cmd.OutOrStdout(), graph)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "dot", "Output format: dot or mermaid")
+	return cmd
+}
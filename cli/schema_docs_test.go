@@ -0,0 +1,51 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/cli"
+	"github.com/shinzonetwork/view-creator/core/schema/store/fileschema"
+	"github.com/spf13/cobra"
+)
+
+func TestMakeSchemaDocsAndGraphCommands(t *testing.T) {
+	store, err := fileschema.NewFileSchemaStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+
+	run := func(cmd *cobra.Command, args ...string) (string, error) {
+		cmd.SetArgs(args)
+
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetContext(cli.WithSchemaStore(context.Background(), store))
+
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	out, err := run(cli.MakeSchemaDocsCommand())
+	if err != nil {
+		t.Fatalf("docs failed: %v", err)
+	}
+	if !strings.Contains(out, "## Log\n") || !strings.Contains(out, "- `events` → many [Event](#event) via `log_events`") {
+		t.Errorf("unexpected docs:\n%s", out)
+	}
+
+	out, err = run(cli.MakeSchemaGraphCommand(), "--format", "mermaid")
+	if err != nil {
+		t.Fatalf("graph failed: %v", err)
+	}
+	if !strings.Contains(out, `Block ||--o{ Transaction : "block_transactions"`) || !strings.Contains(out, `Log ||--o{ Event : "log_events"`) {
+		t.Errorf("unexpected graph:\n%s", out)
+	}
+
+	if _, err := run(cli.MakeSchemaGraphCommand(), "--format", "png"); err == nil {
+		t.Error("expected an unknown format to be rejected")
+	}
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/shinzonetwork/view-creator/core/schema/store"
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/spf13/cobra"
+)
+
+const editErrorPrefix = "# error: "
+
+func MakeSchemaEditCommand() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "edit <type>",
+		Short: "Edit a custom schema definition in $EDITOR",
+		Long: `Opens the definition in $VISUAL or $EDITOR (vi by default) and saves it back when the
+editor exits. An invalid definition is reopened with the error at the top; saving it
+unchanged, or leaving the original untouched, aborts the edit.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			schemastore := mustGetContextSchemaStore(cmd)
+
+			def, err := schemastore.GetDefinition(name)
+			if err != nil {
+				return err
+			}
+			if def.Origin == store.OriginDefault {
+				return fmt.Errorf("%s %s belongs to the default schema and cannot be edited", def.Keyword(), name)
+			}
+
+			file, err := os.CreateTemp("", name+"-*.graphql")
+			if err != nil {
+				return fmt.Errorf("failed to create temp file: %w", err)
+			}
+			path := file.Name()
+			file.Close()
+			defer os.Remove(path)
+
+			content := def.Source + "\n"
+			var lastErr error
+			for {
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					return fmt.Errorf("failed to write temp file: %w", err)
+				}
+				if err := runEditor(cmd, path); err != nil {
+					return err
+				}
+
+				edited, err := os.ReadFile(path)
+				if err != nil {
+					return fmt.Errorf("failed to read temp file: %w", err)
+				}
+				if string(edited) == content {
+					if lastErr != nil {
+						return fmt.Errorf("edit aborted: %w", lastErr)
+					}
+					fmt.Fprintln(cmd.OutOrStdout(), "No changes made.")
+					return nil
+				}
+
+				sdl := stripEditErrors(string(edited))
+				lastErr = service.ReplaceCustomDefinition(schemastore, name, sdl, schemaChangeOptions(cmd, force))
+				if lastErr == nil {
+					break
+				}
+
+				fmt.Fprintf(cmd.ErrOrStderr(), "❌ %v\n", lastErr)
+				content = editErrorPrefix + strings.ReplaceAll(lastErr.Error(), "\n", "\n"+editErrorPrefix) + "\n" + sdl
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), "Schema updated.")
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// runEditor opens path in the user's editor. The editor variable may carry arguments,
+// such as "code --wait".
+func runEditor(cmd *cobra.Command, path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	c := exec.Command("sh", "-c", editor+` "$1"`, "--", path)
+	c.Stdin = cmd.InOrStdin()
+	c.Stdout = cmd.OutOrStdout()
+	c.Stderr = cmd.ErrOrStderr()
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+	return nil
+}
+
+func stripEditErrors(content string) string {
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		if !strings.HasPrefix(line, editErrorPrefix) {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,80 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/cli"
+	"github.com/shinzonetwork/view-creator/core/schema/store/fileschema"
+)
+
+// fakeEditor installs a shell script as $EDITOR that runs the given sed expression on the file.
+func fakeEditor(t *testing.T, sedExpr string) {
+	t.Helper()
+	script := filepath.Join(t.TempDir(), "editor.sh")
+	content := "#!/bin/sh\nsed -i '" + sedExpr + "' \"$1\"\n"
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatalf("failed to write editor script: %v", err)
+	}
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", script)
+}
+
+func TestMakeSchemaEditCommand(t *testing.T) {
+	store, err := fileschema.NewFileSchemaStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+	if err := store.SaveCustom("type Token { address: String }\n\ntype Pool { id: String }"); err != nil {
+		t.Fatalf("failed to save custom schema: %v", err)
+	}
+
+	run := func(args ...string) (string, error) {
+		cmd := cli.MakeSchemaEditCommand()
+		cmd.SetArgs(args)
+
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetContext(cli.WithSchemaStore(context.Background(), store))
+
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	fakeEditor(t, `s/address: String/address: String\n  decimals: Int/`)
+	out, err := run("Token")
+	if err != nil {
+		t.Fatalf("edit failed: %v", err)
+	}
+	if !strings.Contains(out, "Schema updated.") {
+		t.Errorf("unexpected output: %s", out)
+	}
+	def, err := store.GetDefinition("Token")
+	if err != nil || def.Field("decimals") == nil {
+		t.Errorf("expected decimals field after edit, got %+v (err: %v)", def.Fields, err)
+	}
+
+	fakeEditor(t, `s/id: String/id: Missing/`)
+	out, err = run("Pool")
+	if err == nil || !strings.Contains(err.Error(), "undefined type Missing") {
+		t.Errorf("expected edit to be aborted with a validation error, got %v (output: %s)", err, out)
+	}
+	custom, _ := store.LoadCustom()
+	if strings.Contains(custom, "Missing") {
+		t.Error("invalid edit should not have been saved")
+	}
+
+	fakeEditor(t, `s/nothing/to change/`)
+	if out, err := run("Token"); err != nil || !strings.Contains(out, "No changes made.") {
+		t.Errorf("expected no-op edit, got %v (output: %s)", err, out)
+	}
+
+	if _, err := run("Block"); err == nil || !strings.Contains(err.Error(), "belongs to the default schema") {
+		t.Errorf("expected default type edit to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,18 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func MakeSchemaFieldCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "field",
+		Short: "Add, remove or rename fields of a custom type",
+	}
+
+	cmd.AddCommand(MakeSchemaFieldAddCommand())
+	cmd.AddCommand(MakeSchemaFieldRemoveCommand())
+	cmd.AddCommand(MakeSchemaFieldRenameCommand())
+
+	return cmd
+}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/spf13/cobra"
+)
+
+func MakeSchemaFieldAddCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <type> <field: Type @directives>",
+		Short: "Add a field to a custom type, or a value to a custom enum",
+		Example: `  viewkit tools schema field add Token "symbol: String @index"
+  viewkit tools schema field add Status ARCHIVED`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			typeName := args[0]
+			field := strings.Join(args[1:], " ")
+			schemastore := mustGetContextSchemaStore(cmd)
+
+			if err := service.AddSchemaField(schemastore, typeName, field); err != nil {
+				return fmt.Errorf("failed to add field: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Field added to %s.\n", typeName)
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/spf13/cobra"
+)
+
+func MakeSchemaFieldRemoveCommand() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "remove <type> <field>",
+		Short: "Remove a field from a custom type, or a value from a custom enum",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			typeName, field := args[0], args[1]
+			schemastore := mustGetContextSchemaStore(cmd)
+
+			if err := service.RemoveSchemaField(schemastore, typeName, field, schemaChangeOptions(cmd, force)); err != nil {
+				return fmt.Errorf("failed to remove field: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Field %s removed from %s.\n", field, typeName)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Apply the change even if it breaks stored views")
+	return cmd
+}
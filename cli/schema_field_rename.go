@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/spf13/cobra"
+)
+
+func MakeSchemaFieldRenameCommand() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "rename <type> <field> <new-name>",
+		Short: "Rename a field of a custom type, or a value of a custom enum",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			typeName, oldName, newName := args[0], args[1], args[2]
+			schemastore := mustGetContextSchemaStore(cmd)
+
+			if err := service.RenameSchemaField(schemastore, typeName, oldName, newName, schemaChangeOptions(cmd, force)); err != nil {
+				return fmt.Errorf("failed to rename field: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Field %s.%s renamed to %s.\n", typeName, oldName, newName)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Apply the change even if it breaks stored views")
+	return cmd
+}
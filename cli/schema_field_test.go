@@ -0,0 +1,60 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/cli"
+	"github.com/shinzonetwork/view-creator/core/schema/store/fileschema"
+)
+
+func TestMakeSchemaFieldCommand(t *testing.T) {
+	store, err := fileschema.NewFileSchemaStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+	if err := store.SaveCustom("type Token { address: String }"); err != nil {
+		t.Fatalf("failed to save custom schema: %v", err)
+	}
+
+	run := func(args ...string) (string, error) {
+		cmd := cli.MakeSchemaFieldCommand()
+		cmd.SetArgs(args)
+
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetContext(cli.WithSchemaStore(context.Background(), store))
+
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	out, err := run("add", "Token", "symbol:", "String", "@index")
+	if err != nil {
+		t.Fatalf("field add failed: %v", err)
+	}
+	if !strings.Contains(out, "Field added to Token.") {
+		t.Errorf("unexpected output: %s", out)
+	}
+
+	if _, err := run("rename", "Token", "address", "contract"); err != nil {
+		t.Fatalf("field rename failed: %v", err)
+	}
+	if _, err := run("remove", "Token", "symbol"); err != nil {
+		t.Fatalf("field remove failed: %v", err)
+	}
+	if _, err := run("add", "Token", "owner: Wallet"); err == nil {
+		t.Error("expected a field of an undefined type to be rejected")
+	}
+
+	def, err := store.GetDefinition("Token")
+	if err != nil {
+		t.Fatalf("failed to get definition: %v", err)
+	}
+	if def.Source != "type Token {\n  contract: String\n}" {
+		t.Errorf("unexpected definition:\n%s", def.Source)
+	}
+}
@@ -10,7 +10,7 @@ import (
 func MakeSchemaInspectCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "inspect <type>",
-		Short: "Show the full definition of a schema type",
+		Short: "Show the full definition of a schema type, enum, interface, union, scalar or input",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			schemastore := mustGetContextSchemaStore(cmd)
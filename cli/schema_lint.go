@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shinzonetwork/view-creator/core/config"
+	"github.com/shinzonetwork/view-creator/core/schema"
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/spf13/cobra"
+)
+
+func MakeSchemaLintCommand() *cobra.Command {
+	var overrides []string
+	var customOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Check the schema against lint rules",
+		Long: `Lint the default and custom schema. Rules are relation-name, relation-pair,
+foreign-key-index, type-name-case and shadowed-type; set each to error, warning or off
+under schema.lint.rules in ~/.shinzo/config.json, or per run with --rule. The command
+fails when a rule set to error is violated.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schemastore := mustGetContextSchemaStore(cmd)
+
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			rules := map[string]schema.Severity{}
+			for rule, severity := range cfg.Schema.Lint.Rules {
+				rules[rule] = severity
+			}
+			for _, o := range overrides {
+				rule, severity, ok := strings.Cut(o, "=")
+				if !ok {
+					return fmt.Errorf("invalid --rule %q, expected name=severity", o)
+				}
+				rules[rule] = schema.Severity(severity)
+			}
+
+			findings, err := service.LintSchema(schemastore, rules, customOnly)
+			if err != nil {
+				return fmt.Errorf("failed to lint schema: %w", err)
+			}
+
+			out := cmd.OutOrStdout()
+			if len(findings) == 0 {
+				fmt.Fprintln(out, "✅ No lint problems found.")
+				return nil
+			}
+
+			errors := 0
+			for _, f := range findings {
+				fmt.Fprintln(out, f)
+				if f.Severity == schema.SeverityError {
+					errors++
+				}
+			}
+			fmt.Fprintf(out, "\n%d error(s), %d warning(s)\n", errors, len(findings)-errors)
+
+			if errors > 0 {
+				return fmt.Errorf("schema lint failed with %d error(s)", errors)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&overrides, "rule", nil, "Override a rule severity, e.g. --rule foreign-key-index=error")
+	cmd.Flags().BoolVar(&customOnly, "custom-only", false, "Only report problems in the custom schema")
+	return cmd
+}
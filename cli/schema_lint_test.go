@@ -0,0 +1,57 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/cli"
+	"github.com/shinzonetwork/view-creator/core/schema/store/fileschema"
+)
+
+func TestMakeSchemaLintCommand(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+
+	store, err := fileschema.NewFileSchemaStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+	if err := store.SaveCustom("type Pool {\n  owner: Owner\n}\n\ntype Owner { id: String }"); err != nil {
+		t.Fatalf("failed to save custom schema: %v", err)
+	}
+
+	run := func(args ...string) (string, error) {
+		cmd := cli.MakeSchemaLintCommand()
+		cmd.SetArgs(args)
+
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetContext(cli.WithSchemaStore(context.Background(), store))
+
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	out, err := run("--custom-only")
+	if err == nil {
+		t.Fatal("expected lint to fail")
+	}
+	if !strings.Contains(out, "custom_schema.graphql:2:3: error: relation field Pool.owner has no @relation name (relation-name)") {
+		t.Errorf("unexpected output: %s", out)
+	}
+	if strings.Contains(out, "default_schema.graphql") {
+		t.Errorf("expected default schema findings to be skipped: %s", out)
+	}
+
+	out, err = run("--custom-only", "--rule", "relation-name=off", "--rule", "foreign-key-index=off")
+	if err != nil || !strings.Contains(out, "✅ No lint problems found.") {
+		t.Errorf("expected a clean run: %v\n%s", err, out)
+	}
+
+	if _, err := run("--rule", "relation-name"); err == nil {
+		t.Error("expected a malformed --rule to be rejected")
+	}
+}
@@ -10,21 +10,18 @@ import (
 func MakeSchemaListCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "list",
-		Short: "List all schema types in the Viewkit schema (default and custom)",
+		Short: "List all schema types in the Viewkit schema (default and custom) with their kind",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			schemastore := mustGetContextSchemaStore(cmd)
 
-			defaults, customs, err := service.ListSchemas(schemastore)
+			defs, err := service.ListSchemaDefinitions(schemastore)
 			if err != nil {
 				return err
 			}
 
 			fmt.Fprintln(cmd.OutOrStdout(), "Schemas:")
-			for _, d := range defaults {
-				fmt.Fprintf(cmd.OutOrStdout(), "• %s (default)\n", d)
-			}
-			for _, c := range customs {
-				fmt.Fprintf(cmd.OutOrStdout(), "• %s (custom)\n", c)
+			for _, def := range defs {
+				fmt.Fprintf(cmd.OutOrStdout(), "• %s %s (%s)\n", def.Keyword(), def.Name, def.Origin)
 			}
 
 			return nil
@@ -18,7 +18,7 @@ func TestMakeSchemaListCommand(t *testing.T) {
 		t.Fatalf("failed to create schema store: %v", err)
 	}
 
-	customSchema := "type CustomListType { name: String }"
+	customSchema := "type CustomListType { name: String }\n\nenum CustomSide { BUY SELL }"
 	if err := store.SaveCustom(customSchema); err != nil {
 		t.Fatalf("failed to save custom schema: %v", err)
 	}
@@ -44,4 +44,10 @@ func TestMakeSchemaListCommand(t *testing.T) {
 	if !strings.Contains(result, "CustomListType (custom)") {
 		t.Errorf("expected custom type 'CustomListType' in output, got:\n%s", result)
 	}
+	if !strings.Contains(result, "• enum CustomSide (custom)") {
+		t.Errorf("expected custom enum 'CustomSide' in output, got:\n%s", result)
+	}
+	if !strings.Contains(result, "• type Block (default)") {
+		t.Errorf("expected default type 'Block' in output, got:\n%s", result)
+	}
 }
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/shinzonetwork/view-creator/core/config"
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/spf13/cobra"
+)
+
+func MakeSchemaProfileCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage schema profiles, such as one per chain or network",
+		Long: `Each profile has its own default and custom schema. Schema commands and new views use
+the active profile (schema.profile in ~/.shinzo/config.json); a view keeps the profile it
+was created with.`,
+	}
+
+	cmd.AddCommand(MakeSchemaProfileListCommand())
+	cmd.AddCommand(MakeSchemaProfileUseCommand())
+	cmd.AddCommand(MakeSchemaProfileAddCommand())
+
+	return cmd
+}
+
+func MakeSchemaProfileListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List schema profiles",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schemastore := mustGetContextSchemaStore(cmd)
+
+			profiles, err := schemastore.Profiles()
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), "Profiles:")
+			for _, profile := range profiles {
+				if profile == schemastore.Profile() {
+					fmt.Fprintf(cmd.OutOrStdout(), "• %s (active)\n", profile)
+				} else {
+					fmt.Fprintf(cmd.OutOrStdout(), "• %s\n", profile)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func MakeSchemaProfileUseCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "use <name>",
+		Short: "Make a schema profile the active one",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schemastore := mustGetContextSchemaStore(cmd)
+
+			if _, err := schemastore.WithProfile(args[0]); err != nil {
+				return err
+			}
+
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			cfg.Schema.Profile = args[0]
+			if err := config.Save(cfg); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Using schema profile %s.\n", args[0])
+			return nil
+		},
+	}
+}
+
+func MakeSchemaProfileAddCommand() *cobra.Command {
+	var fromFile string
+
+	cmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Create a schema profile from a default schema file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schemastore := mustGetContextSchemaStore(cmd)
+
+			if fromFile == "" {
+				return fmt.Errorf("--from-file is required")
+			}
+			if err := service.AddSchemaProfile(schemastore, args[0], fromFile); err != nil {
+				return fmt.Errorf("failed to add profile: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Profile %s added.\n", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&fromFile, "from-file", "", "Default schema of the profile (required)")
+	return cmd
+}
@@ -0,0 +1,68 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/cli"
+	"github.com/shinzonetwork/view-creator/core/config"
+	"github.com/shinzonetwork/view-creator/core/schema/store/fileschema"
+	"github.com/spf13/cobra"
+)
+
+func TestMakeSchemaProfileCommands(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+
+	schemaStore, err := fileschema.NewFileSchemaStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+	ctx := cli.WithSchemaStore(context.Background(), schemaStore)
+
+	run := func(cmd *cobra.Command, args ...string) (string, error) {
+		var out bytes.Buffer
+		cmd.SetArgs(args)
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetContext(ctx)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	path := filepath.Join(tempDir, "solana.graphql")
+	if err := os.WriteFile(path, []byte("type Account { id: String }"), 0644); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+
+	out, err := run(cli.MakeSchemaProfileAddCommand(), "solana", "--from-file", path)
+	if err != nil || !strings.Contains(out, "Profile solana added.") {
+		t.Fatalf("add failed: %v\n%s", err, out)
+	}
+
+	out, err = run(cli.MakeSchemaProfileListCommand())
+	if err != nil || !strings.Contains(out, "• default (active)\n• solana\n") {
+		t.Errorf("unexpected list output: %v\n%s", err, out)
+	}
+
+	if _, err := run(cli.MakeSchemaProfileUseCommand(), "missing"); err == nil {
+		t.Error("expected an unknown profile to be rejected")
+	}
+
+	out, err = run(cli.MakeSchemaProfileUseCommand(), "solana")
+	if err != nil || !strings.Contains(out, "Using schema profile solana.") {
+		t.Fatalf("use failed: %v\n%s", err, out)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if cfg.Schema.Profile != "solana" {
+		t.Errorf("expected the config to select solana, got %q", cfg.Schema.Profile)
+	}
+}
@@ -8,15 +8,17 @@ import (
 )
 
 func MakeSchemaRemoveCommand() *cobra.Command {
+	var force bool
+
 	cmd := &cobra.Command{
 		Use:   "remove <type>",
-		Short: "Remove a custom schema type from the viewkit schema",
+		Short: "Remove a custom schema definition of any kind from the viewkit schema",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			name := args[0]
 			schemastore := mustGetContextSchemaStore(cmd)
 
-			if err := service.RemoveCustomSchema(schemastore, name); err != nil {
+			if err := service.RemoveCustomSchema(schemastore, name, schemaChangeOptions(cmd, force)); err != nil {
 				return fmt.Errorf("failed to remove schema: %w", err)
 			}
 
@@ -25,5 +27,6 @@ func MakeSchemaRemoveCommand() *cobra.Command {
 		},
 	}
 
+	cmd.Flags().BoolVar(&force, "force", false, "Apply the change even if it breaks stored views")
 	return cmd
 }
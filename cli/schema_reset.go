@@ -8,18 +8,21 @@ import (
 )
 
 func MakeSchemaResetCommand() *cobra.Command {
+	var force bool
+
 	cmd := &cobra.Command{
 		Use:   "reset",
 		Short: "Clear all custom schema types (does not affect defaults)",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			schemastore := mustGetContextSchemaStore(cmd)
 
-			if err := service.ResetCustomSchemas(schemastore); err != nil {
+			if err := service.ResetCustomSchemas(schemastore, schemaChangeOptions(cmd, force)); err != nil {
 				return fmt.Errorf("failed to reset custom schemas: %w", err)
 			}
 			fmt.Fprintln(cmd.OutOrStdout(), "Custom schema cleared.")
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&force, "force", false, "Apply the change even if it breaks stored views")
 	return cmd
 }
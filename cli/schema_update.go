@@ -3,28 +3,113 @@ package cli
 import (
 	"fmt"
 
+	"github.com/shinzonetwork/view-creator/core/config"
+	"github.com/shinzonetwork/view-creator/core/schema/source"
 	"github.com/shinzonetwork/view-creator/core/service"
 	"github.com/spf13/cobra"
 )
 
 func MakeSchemaUpdateCommand() *cobra.Command {
 	var version string
+	var fromFile string
+	var sha string
+	var dryRun bool
+	var revert bool
+	var force bool
 
 	cmd := &cobra.Command{
 		Use:   "update",
 		Short: "Update the default schemas from a remote source",
+		Long: `Fetches the default schema from schema.source in config.json (a URL template or a
+git repository) or from a local file, and replaces the local copy. Every change is listed and
+classified as breaking or safe, together with the stored views it would break; the update
+is refused when a view would break, unless --force is given. The replaced schema is kept
+and can be restored with --revert.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			schemaStore := mustGetContextSchemaStore(cmd)
+			opts := schemaChangeOptions(cmd, force)
 
-			if err := service.UpdateDefaultSchemas(schemaStore, version); err != nil {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			fetch := source.FetchOptions{Ref: version, File: fromFile, SHA256: sha}
+
+			var plan service.SchemaUpdatePlan
+			switch {
+			case revert && dryRun:
+				return fmt.Errorf("--revert cannot be combined with --dry-run")
+			case revert:
+				plan, err = service.RevertDefaultSchema(schemaStore, opts)
+			case dryRun:
+				plan, err = service.PlanDefaultSchemaUpdate(schemaStore, cfg.Schema.Source, fetch, opts.Views)
+			default:
+				plan, err = service.UpdateDefaultSchemas(schemaStore, cfg.Schema.Source, fetch, opts)
+			}
+
+			if plan.Schema != "" {
+				// a refused update lists the affected views in its error
+				printSchemaUpdatePlan(cmd, plan, err == nil)
+			}
+			if err != nil {
+				if revert {
+					return fmt.Errorf("failed to revert default schema: %w", err)
+				}
 				return fmt.Errorf("failed to update default schemas: %w", err)
 			}
 
-			fmt.Fprintln(cmd.OutOrStdout(), "Default schemas updated from remote source.")
+			switch {
+			case dryRun:
+				fmt.Fprintln(cmd.OutOrStdout(), "Dry run, default schema not changed.")
+			case revert:
+				fmt.Fprintln(cmd.OutOrStdout(), "Default schema reverted to the previous version.")
+			default:
+				fmt.Fprintln(cmd.OutOrStdout(), "Default schemas updated from remote source.")
+			}
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVar(&version, "version", "", "Git branch or tag to fetch the default schema from (default: main)")
+	cmd.Flags().StringVar(&version, "version", "", "Git branch, tag or commit to fetch the default schema from (default: main)")
+	cmd.Flags().StringVar(&fromFile, "from-file", "", "Read the default schema from a local file instead of the configured source")
+	cmd.Flags().StringVar(&sha, "sha256", "", "Expected sha256 of the fetched schema")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the changes and affected views without saving")
+	cmd.Flags().BoolVar(&revert, "revert", false, "Restore the default schema replaced by the last update")
+	cmd.Flags().BoolVar(&force, "force", false, "Apply the change even if it breaks stored views")
 	return cmd
 }
+
+func printSchemaUpdatePlan(cmd *cobra.Command, plan service.SchemaUpdatePlan, showAffected bool) {
+	out := cmd.OutOrStdout()
+
+	if plan.Location != "" {
+		fmt.Fprintf(out, "📥 Fetched %s\n", plan.Location)
+	}
+
+	if len(plan.Changes) == 0 {
+		fmt.Fprintln(out, "No schema changes.")
+		return
+	}
+
+	fmt.Fprintln(out, "🔍 Schema changes:")
+	for _, c := range plan.Changes {
+		label := "safe    "
+		if c.Breaking {
+			label = "breaking"
+		}
+		fmt.Fprintf(out, "  %s  %s\n", label, c)
+	}
+
+	if showAffected && len(plan.Affected) > 0 {
+		fmt.Fprintln(out, "⚠️  Affected views:")
+		for _, u := range plan.Affected {
+			fmt.Fprintf(out, "  • %s\n", u)
+		}
+	}
+	if len(plan.Skipped) > 0 {
+		fmt.Fprintln(out, "⚠️  Views not checked, their query does not resolve:")
+		for _, v := range plan.Skipped {
+			fmt.Fprintf(out, "  • %s\n", v)
+		}
+	}
+}
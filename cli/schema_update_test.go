@@ -1,15 +1,32 @@
 package cli_test
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/shinzonetwork/view-creator/cli"
+	"github.com/shinzonetwork/view-creator/core/config"
 	"github.com/shinzonetwork/view-creator/core/schema/store/fileschema"
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/shinzonetwork/view-creator/core/view/store/local"
+	"github.com/shinzonetwork/view-creator/tools"
 )
 
 func TestMakeSchemaUpdateCommand(t *testing.T) {
-	t.Skip("TODO: implement test for MakeSchemaUpdateCommand")
+	// the remote schema drops Log.data
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/main/default_schema.graphql" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, strings.Replace(tools.DefaultSchema, "    data: String\n", "", 1))
+	}))
+	defer server.Close()
 
 	tempDir := t.TempDir()
 
@@ -18,22 +35,72 @@ func TestMakeSchemaUpdateCommand(t *testing.T) {
 		t.Fatalf("failed to create schema store: %v", err)
 	}
 
-	cmd := cli.MakeSchemaUpdateCommand()
-	cmd.SetArgs([]string{"--version", "main"})
+	// the source comes from config.json in the home directory
+	t.Setenv("HOME", tempDir)
+	cfg := config.Default()
+	cfg.Schema.Source.URL = server.URL + "/{ref}/default_schema.graphql"
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	viewStore, err := local.NewLocalStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create view store: %v", err)
+	}
+	if _, err := service.InitView("logs", viewStore); err != nil {
+		t.Fatalf("failed to init view: %v", err)
+	}
+	if _, err := service.UpdateQuery("logs", "Log { data }", viewStore, store, service.QueryOptions{}); err != nil {
+		t.Fatalf("failed to set query: %v", err)
+	}
 
-	ctx := cli.WithSchemaStore(context.Background(), store)
-	cmd.SetContext(ctx)
+	ctx := cli.WithViewStore(cli.WithSchemaStore(context.Background(), store), viewStore)
+	run := func(args ...string) (string, error) {
+		cmd := cli.MakeSchemaUpdateCommand()
+		cmd.SetArgs(args)
 
-	// TODO: stub HTTP call or test against real GitHub branch (but url is not public)
-	// var out bytes.Buffer
-	// cmd.SetOut(&out)
-	// cmd.SetErr(&out)
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetContext(ctx)
 
-	// err = cmd.Execute()
-	// if err != nil {
-	//     t.Fatalf("command execution failed: %v", err)
-	// }
+		err := cmd.Execute()
+		return out.String(), err
+	}
 
-	// TODO: assert output contains expected update message
-	// TODO: assert schema file was updated
+	out, err := run("--version", "main", "--dry-run")
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	for _, want := range []string{
+		"breaking  field Log.data removed",
+		"• logs: Log.data (Log.data)",
+		"Dry run, default schema not changed.",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in dry run output:\n%s", want, out)
+		}
+	}
+
+	if _, err := run(); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected the update to be refused, got %v", err)
+	}
+	if def, err := store.GetDefinition("Log"); err != nil || def.Field("data") == nil {
+		t.Fatal("a refused update must not change the default schema")
+	}
+
+	if out, err := run("--force"); err != nil || !strings.Contains(out, "Default schemas updated from remote source.") {
+		t.Fatalf("forced update failed: %v\n%s", err, out)
+	}
+	if def, _ := store.GetDefinition("Log"); def.Field("data") != nil {
+		t.Error("expected Log.data to be gone after the update")
+	}
+
+	out, err = run("--revert")
+	if err != nil || !strings.Contains(out, "Default schema reverted to the previous version.") || !strings.Contains(out, "safe      field Log.data: String added") {
+		t.Fatalf("revert failed: %v\n%s", err, out)
+	}
+	if def, _ := store.GetDefinition("Log"); def.Field("data") == nil {
+		t.Error("expected Log.data to be back after the revert")
+	}
 }
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/spf13/cobra"
+)
+
+func MakeSchemaUsagesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "usages <type>",
+		Short: "List the stored views whose query uses a schema type",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			schemastore := mustGetContextSchemaStore(cmd)
+			viewstore := mustGetContextViewStore(cmd)
+
+			usages, skipped, err := service.FindSchemaUsages(schemastore, viewstore, name)
+			if err != nil {
+				return fmt.Errorf("failed to find usages: %w", err)
+			}
+
+			out := cmd.OutOrStdout()
+			for _, v := range skipped {
+				fmt.Fprintf(out, "⚠️  Not searched, query does not resolve: %s\n", v)
+			}
+			if len(usages) == 0 {
+				fmt.Fprintf(out, "No views use %s.\n", name)
+				return nil
+			}
+
+			fmt.Fprintf(out, "🔎 Usages of %s:\n", name)
+			for _, u := range usages {
+				fmt.Fprintf(out, "  • %s\n", u)
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
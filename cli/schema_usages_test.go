@@ -0,0 +1,66 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/cli"
+	"github.com/shinzonetwork/view-creator/core/schema/store/fileschema"
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/shinzonetwork/view-creator/core/view/store/local"
+)
+
+func TestMakeSchemaUsagesCommand(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaStore, err := fileschema.NewFileSchemaStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+	viewStore, err := local.NewLocalStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create view store: %v", err)
+	}
+
+	if err := schemaStore.SaveCustom("type Token { address: String }"); err != nil {
+		t.Fatalf("failed to save custom schema: %v", err)
+	}
+	if _, err := service.InitView("tokens", viewStore); err != nil {
+		t.Fatalf("failed to init view: %v", err)
+	}
+	if _, err := service.UpdateQuery("tokens", "Token { address }", viewStore, schemaStore, service.QueryOptions{}); err != nil {
+		t.Fatalf("failed to set query: %v", err)
+	}
+
+	ctx := cli.WithViewStore(cli.WithSchemaStore(context.Background(), schemaStore), viewStore)
+	usages := cli.MakeSchemaUsagesCommand()
+	usages.SetArgs([]string{"Token"})
+	var out bytes.Buffer
+	usages.SetOut(&out)
+	usages.SetContext(ctx)
+	if err := usages.Execute(); err != nil {
+		t.Fatalf("usages failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "🔎 Usages of Token:") || !strings.Contains(out.String(), "• tokens: Token.address (Token.address)") {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+
+	remove := cli.MakeSchemaRemoveCommand()
+	remove.SetArgs([]string{"Token"})
+	remove.SetOut(&bytes.Buffer{})
+	remove.SetErr(&bytes.Buffer{})
+	remove.SetContext(ctx)
+	if err := remove.Execute(); err == nil || !strings.Contains(err.Error(), "• tokens: Token") {
+		t.Fatalf("expected removal of a used type to be refused, got %v", err)
+	}
+
+	remove = cli.MakeSchemaRemoveCommand()
+	remove.SetArgs([]string{"Token", "--force"})
+	remove.SetOut(&bytes.Buffer{})
+	remove.SetContext(ctx)
+	if err := remove.Execute(); err != nil {
+		t.Fatalf("forced removal failed: %v", err)
+	}
+}
@@ -4,11 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/shinzonetwork/view-creator/core/cache"
+	"github.com/shinzonetwork/view-creator/core/config"
 	"github.com/shinzonetwork/view-creator/core/models"
+	"github.com/shinzonetwork/view-creator/core/schema"
 	schemastore "github.com/shinzonetwork/view-creator/core/schema/store"
 	"github.com/shinzonetwork/view-creator/core/schema/store/fileschema"
+	"github.com/shinzonetwork/view-creator/core/service"
 	viewstore "github.com/shinzonetwork/view-creator/core/view/store"
 	"github.com/shinzonetwork/view-creator/core/view/store/local"
 	"github.com/spf13/cobra"
@@ -25,10 +30,22 @@ func mustGetContextViewStore(cmd *cobra.Command) viewstore.ViewStore {
 	return cmd.Context().Value(viewStoreContextKey).(viewstore.ViewStore)
 }
 
+// getContextViewStore returns the view store, or nil when the command runs without one.
+func getContextViewStore(cmd *cobra.Command) viewstore.ViewStore {
+	s, _ := cmd.Context().Value(viewStoreContextKey).(viewstore.ViewStore)
+	return s
+}
+
 func mustGetContextSchemaStore(cmd *cobra.Command) schemastore.SchemaStore {
 	return cmd.Context().Value(schemaStoreContextKey).(schemastore.SchemaStore)
 }
 
+// getContextSchemaStore returns the schema store, or nil when the command runs without one.
+func getContextSchemaStore(cmd *cobra.Command) schemastore.SchemaStore {
+	s, _ := cmd.Context().Value(schemaStoreContextKey).(schemastore.SchemaStore)
+	return s
+}
+
 func setContextViewStore(cmd *cobra.Command) error {
 	store, err := local.NewLocalStore()
 	if err != nil {
@@ -39,16 +56,37 @@ func setContextViewStore(cmd *cobra.Command) error {
 	return nil
 }
 
+// setContextSchemaStore opens the active schema profile.
 func setContextSchemaStore(cmd *cobra.Command) error {
-	store, err := fileschema.NewFileSchemaStore()
+	fileStore, err := fileschema.NewFileSchemaStore()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
+
+	var store schemastore.SchemaStore = fileStore
+	if cfg.Schema.Profile != "" && cfg.Schema.Profile != fileStore.Profile() {
+		store, err = fileStore.WithProfile(cfg.Schema.Profile)
+		if err != nil {
+			return err
+		}
+	}
+
 	ctx := context.WithValue(cmd.Context(), schemaStoreContextKey, store)
 	cmd.SetContext(ctx)
 	return nil
 }
 
+func newDefraDownloadCache(offline bool) (*cache.DownloadCache, error) {
+	opts := service.DefraDownloadOptions()
+	opts.Offline = offline
+	return cache.NewDownloadCache(opts)
+}
+
 func WithViewStore(ctx context.Context, s viewstore.ViewStore) context.Context {
 	return context.WithValue(ctx, viewStoreContextKey, s)
 }
@@ -64,10 +102,12 @@ func printViewPretty(cmd *cobra.Command, view models.View, verbose bool, jsonOut
 			output = view
 		} else {
 			output = struct {
-				Name      string           `json:"name"`
-				Query     *string          `json:"query"`
-				Sdl       *string          `json:"sdl"`
-				Transform models.Transform `json:"transform"`
+				Name      string            `json:"name"`
+				Query     *string           `json:"query"`
+				Sdl       *string           `json:"sdl"`
+				Transform models.Transform  `json:"transform"`
+				Schema    *models.SchemaPin `json:"schema,omitempty"`
+				Profile   string            `json:"profile,omitempty"`
 				Metadata  struct {
 					Version   int    `json:"_v"`
 					Total     int    `json:"_t"`
@@ -79,6 +119,8 @@ func printViewPretty(cmd *cobra.Command, view models.View, verbose bool, jsonOut
 				Query:     view.Query,
 				Sdl:       view.Sdl,
 				Transform: view.Transform,
+				Schema:    view.Schema,
+				Profile:   view.Profile,
 				Metadata: struct {
 					Version   int    `json:"_v"`
 					Total     int    `json:"_t"`
@@ -105,6 +147,9 @@ func printViewPretty(cmd *cobra.Command, view models.View, verbose bool, jsonOut
 	// === Pretty Output ===
 
 	cmd.Printf("📄 View: %s\n", view.Name)
+	if view.Profile != "" {
+		cmd.Printf("🧬 Schema profile: %s\n", view.Profile)
+	}
 
 	if view.Query != nil && *view.Query != "" {
 		cmd.Printf("🔍 Query:\n%s\n\n", *view.Query)
@@ -114,6 +159,10 @@ func printViewPretty(cmd *cobra.Command, view models.View, verbose bool, jsonOut
 
 	if view.Sdl != nil && *view.Sdl != "" {
 		cmd.Printf("📐 SDL:\n%s\n\n", *view.Sdl)
+		// a single type needs no listing; several each become a collection
+		if types, err := schema.ViewTypes(*view.Sdl); err == nil && len(types) > 1 {
+			cmd.Printf("📦 Collections: %s\n\n", strings.Join(types, ", "))
+		}
 	} else {
 		cmd.Println("📐 SDL: <none>")
 	}
@@ -124,6 +173,9 @@ func printViewPretty(cmd *cobra.Command, view models.View, verbose bool, jsonOut
 	} else {
 		for _, lens := range view.Transform.Lenses {
 			cmd.Printf(" - %s (%s)\n", lens.Label, lens.Path)
+			if lens.Output != "" {
+				cmd.Printf("   Output: %s\n", lens.Output)
+			}
 			if len(lens.Arguments) > 0 {
 				cmd.Println("   Arguments:")
 				for k, v := range lens.Arguments {
@@ -144,6 +196,11 @@ func printViewPretty(cmd *cobra.Command, view models.View, verbose bool, jsonOut
 		time.Unix(updatedAt, 0).UTC(),
 	)
 
+	if verbose && view.Schema != nil {
+		validatedAt, _ := strconv.ParseInt(view.Schema.ValidatedAt, 10, 64)
+		cmd.Printf("🔒 Schema: %s (validated at %s)\n", view.Schema.Fingerprint, time.Unix(validatedAt, 0).UTC())
+	}
+
 	if verbose && len(view.Metadata.Revisions) > 0 {
 		cmd.Printf("📝 Revisions (%d):\n", len(view.Metadata.Revisions))
 		for i, rev := range view.Metadata.Revisions {
@@ -151,3 +208,19 @@ func printViewPretty(cmd *cobra.Command, view models.View, verbose bool, jsonOut
 		}
 	}
 }
+
+// printLensSources prints the text source of every lens that was added from WAT.
+func printLensSources(cmd *cobra.Command, view models.View, s viewstore.ViewStore) {
+	for _, lens := range view.Transform.Lenses {
+		if lens.Source == "" {
+			continue
+		}
+
+		source, err := s.GetAssetSource(view.Name, lens.Label)
+		if err != nil {
+			cmd.Printf("❌ Failed to read source of lens %s: %v\n", lens.Label, err)
+			continue
+		}
+		cmd.Printf("📜 Source of %s (%s):\n%s\n", lens.Label, lens.Source, strings.TrimRight(source, "\n"))
+	}
+}
@@ -13,6 +13,10 @@ func MakeViewCommand() *cobra.Command {
 			if err := setContextViewStore(cmd); err != nil {
 				return err
 			}
+			// view check and view analyze read the schema
+			if err := setContextSchemaStore(cmd); err != nil {
+				return err
+			}
 			return nil
 		},
 	}
@@ -25,6 +29,12 @@ func MakeViewCommand() *cobra.Command {
 	cmd.AddCommand(MakeViewRemoveCommand())
 	cmd.AddCommand(MakeViewDeployCommand())
 	cmd.AddCommand(MakeViewTestCommand())
+	cmd.AddCommand(MakeViewSizeCommand())
+	cmd.AddCommand(MakeViewLensCommand())
+	cmd.AddCommand(MakeViewCheckCommand())
+	cmd.AddCommand(MakeViewFmtCommand())
+	cmd.AddCommand(MakeViewIdCommand())
+	cmd.AddCommand(MakeViewAnalyzeCommand())
 
 	return cmd
 }
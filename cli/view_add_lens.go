@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/shinzonetwork/view-creator/core/cache"
+	"github.com/shinzonetwork/view-creator/core/config"
 	"github.com/shinzonetwork/view-creator/core/service"
 	"github.com/spf13/cobra"
 )
@@ -13,10 +15,14 @@ func MakeAddLensCommand(viewName *string) *cobra.Command {
 	var wasmURL string
 	var argsJson string
 	var label string
+	var sha string
+	var offline bool
+	var output string
 
 	cmd := &cobra.Command{
 		Use:   "lens",
 		Short: "Add lenses in a view",
+		Long:  "Add a lens to a view. The module must satisfy the sandbox policy under lens.policy in ~/.shinzo/config.json, which is global and applies to every view.",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var path string
 
@@ -41,8 +47,25 @@ func MakeAddLensCommand(viewName *string) *cobra.Command {
 
 			store := mustGetContextViewStore(cmd)
 
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			opts := service.LensOptions{SHA256: sha, Policy: &cfg.Lens.Policy, Output: output}
+			if wasmURL != "" {
+				downloadOpts := cache.DefaultOptions()
+				downloadOpts.Offline = offline
+
+				downloads, err := cache.NewDownloadCache(downloadOpts)
+				if err != nil {
+					return err
+				}
+				opts.Downloads = downloads
+			}
+
 			// function to add lens
-			view, err := service.InitLens(*viewName, label, path, argsMap, store)
+			view, err := service.InitLens(*viewName, label, path, argsMap, store, opts)
 			if err != nil {
 				return err
 			}
@@ -54,9 +77,12 @@ func MakeAddLensCommand(viewName *string) *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&label, "label", "", "name of lens")
-	cmd.Flags().StringVar(&wasmPath, "path", "", "Path to the WASM file (local)")
+	cmd.Flags().StringVar(&wasmPath, "path", "", "Path to the WASM or WAT file (local)")
 	cmd.Flags().StringVar(&wasmURL, "url", "", "URL to download the WASM file from")
 	cmd.Flags().StringVar(&argsJson, "args", "", "arguments of the lens transform")
+	cmd.Flags().StringVar(&sha, "sha256", "", "Expected sha256 of the WASM file")
+	cmd.Flags().BoolVar(&offline, "offline", false, "Only use lenses already in the download cache")
+	cmd.Flags().StringVar(&output, "output", "", "Fields the lens produces, e.g. 'hash: String, blockNumber: Int', checked against the SDL by view check")
 
 	return cmd
 }
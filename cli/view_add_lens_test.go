@@ -75,3 +75,60 @@ func TestAddLensToExistingView(t *testing.T) {
 		t.Errorf("unexpected output.\nGot:\n%s\nExpected prefix:\n%s", out, expected)
 	}
 }
+
+func TestAddWatLensStoresSource(t *testing.T) {
+	tempDir := t.TempDir()
+
+	store, err := local.NewLocalStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create temp store: %v", err)
+	}
+
+	viewName := "testview"
+
+	cmd := cli.MakeViewInitCommand()
+	cmd.SetArgs([]string{viewName})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetContext(cli.WithViewStore(context.Background(), store))
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("view init command failed: %v", err)
+	}
+
+	source := `(module
+  (memory (export "memory") 1)
+  (func (export "alloc") (param i32) (result i32) (i32.const 0)))`
+
+	watPath := filepath.Join(tempDir, "noop.wat")
+	if err := os.WriteFile(watPath, []byte(source), 0644); err != nil {
+		t.Fatalf("failed to write wat: %v", err)
+	}
+
+	cmd = cli.MakeAddLensCommand(&viewName)
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--label", "noop", "--path", watPath})
+	cmd.SetContext(cli.WithViewStore(context.Background(), store))
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("add lens command failed: %v", err)
+	}
+
+	blob, err := os.ReadFile(filepath.Join(tempDir, ".shinzo", "views", viewName, "assets", "noop.wasm"))
+	if err != nil {
+		t.Fatalf("expected assembled asset: %v", err)
+	}
+	if !bytes.HasPrefix(blob, []byte("\x00asm")) {
+		t.Errorf("expected a binary wasm asset, got %q", blob)
+	}
+
+	cmd = cli.MakeViewInspectCommand()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{viewName, "--verbose"})
+	cmd.SetContext(cli.WithViewStore(context.Background(), store))
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("inspect command failed: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "📜 Source of noop (assets/noop.wat):\n"+source) {
+		t.Errorf("expected inspect to show the lens source, got:\n%s", buf.String())
+	}
+}
@@ -1,19 +1,72 @@
 package cli
 
 import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/shinzonetwork/view-creator/core/schema"
 	"github.com/shinzonetwork/view-creator/core/service"
 	"github.com/spf13/cobra"
 )
 
 func MakeAddQueryCommand(viewName *string) *cobra.Command {
-	return &cobra.Command{
+	var snapshot bool
+	var fromFile string
+	var spec schema.QuerySpec
+
+	cmd := &cobra.Command{
 		Use:   "query '<query>'",
 		Short: "Add or update the query of the view",
-		Args:  cobra.ExactArgs(1),
+		Long: `Add or update the query of the view. The query is either the root selections, such as
+'Log { address }', or a full GraphQL document with variables (each with a default value),
+named fragments and several root selections. Long documents can be read with --from-file.
+
+The query can also be built from field paths, following relations through the schema:
+
+  viewkit view add query --type Log --fields address,transaction.block.number --filter 'address=0x..'
+
+Filters take the form path<op>value, with op one of =, !=, >, >=, <, <=.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			viewstore := mustGetContextViewStore(cmd)
 			schemastore := mustGetContextSchemaStore(cmd)
-			view, err := service.UpdateQuery(*viewName, args[0], viewstore, schemastore)
+
+			sources := len(args)
+			if fromFile != "" {
+				sources++
+			}
+			if spec.Type != "" {
+				sources++
+			}
+			if sources > 1 {
+				return fmt.Errorf("pass only one of a query, --from-file or --type")
+			}
+			if spec.Type == "" && (len(spec.Fields) > 0 || len(spec.Filters) > 0) {
+				return fmt.Errorf("--fields and --filter require --type")
+			}
+
+			var query string
+			switch {
+			case spec.Type != "":
+				built, err := service.BuildViewQuery(*viewName, spec, viewstore, schemastore)
+				if err != nil {
+					return err
+				}
+				query = built
+			case fromFile != "":
+				content, err := os.ReadFile(fromFile)
+				if err != nil {
+					return fmt.Errorf("failed to read query file: %w", err)
+				}
+				query = strings.TrimSpace(string(content))
+			case len(args) == 1:
+				query = args[0]
+			default:
+				return fmt.Errorf("a query, --from-file or --type is required")
+			}
+
+			view, err := service.UpdateQuery(*viewName, query, viewstore, schemastore, service.QueryOptions{Snapshot: snapshot})
 			if err != nil {
 				return err
 			}
@@ -23,4 +76,11 @@ func MakeAddQueryCommand(viewName *string) *cobra.Command {
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&snapshot, "snapshot", false, "Embed the SDL of the types the query uses, so later schema drift can be reported per type")
+	cmd.Flags().StringVar(&fromFile, "from-file", "", "Read the query from a .graphql file")
+	cmd.Flags().StringVar(&spec.Type, "type", "", "Build the query on this type")
+	cmd.Flags().StringSliceVar(&spec.Fields, "fields", nil, "Field paths to select with --type, e.g. address,transaction.hash")
+	cmd.Flags().StringArrayVar(&spec.Filters, "filter", nil, "Filter for --type, e.g. 'address=0x..' (repeatable)")
+	return cmd
 }
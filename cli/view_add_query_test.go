@@ -3,11 +3,14 @@ package cli_test
 import (
 	"bytes"
 	"context"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/shinzonetwork/view-creator/cli"
 	"github.com/shinzonetwork/view-creator/core/schema/store/fileschema"
+	"github.com/shinzonetwork/view-creator/core/service"
 	"github.com/shinzonetwork/view-creator/core/view/store/local"
 )
 
@@ -155,3 +158,117 @@ Log {address topics data transactionHash blockNumber}
 		t.Errorf("unexpected output.\nGot:\n%s\nExpected prefix:\n%s", out, expected)
 	}
 }
+
+func TestAddQueryFromFile(t *testing.T) {
+	tempDir := t.TempDir()
+
+	store, err := local.NewLocalStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create temp store: %v", err)
+	}
+	schemastore, err := fileschema.NewFileSchemaStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create temp store: %v", err)
+	}
+	if _, err := service.InitView("decoded", store); err != nil {
+		t.Fatalf("failed to init view: %v", err)
+	}
+
+	document := `query ($address: String = "0x1e3a") {
+  Log(filter: {address: {_eq: $address}}) { ...LogFields }
+}
+
+fragment LogFields on Log { address data }
+`
+	path := filepath.Join(tempDir, "decoded.graphql")
+	if err := os.WriteFile(path, []byte(document), 0644); err != nil {
+		t.Fatalf("failed to write query: %v", err)
+	}
+
+	run := func(args ...string) error {
+		viewName := "decoded"
+		cmd := cli.MakeAddQueryCommand(&viewName)
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetErr(&buf)
+		cmd.SetArgs(args)
+		cmd.SetContext(cli.WithSchemaStore(cli.WithViewStore(context.Background(), store), schemastore))
+		return cmd.Execute()
+	}
+
+	if err := run("--from-file", path); err != nil {
+		t.Fatalf("add query failed: %v", err)
+	}
+	if err := run("Log { address }", "--from-file", path); err == nil {
+		t.Error("expected a query together with --from-file to be rejected")
+	}
+	if err := run(); err == nil {
+		t.Error("expected a missing query to be rejected")
+	}
+
+	view, err := store.Load("decoded")
+	if err != nil {
+		t.Fatalf("failed to load view: %v", err)
+	}
+	if *view.Query != strings.TrimSpace(document) {
+		t.Errorf("expected the document to be stored as written, got:\n%s", *view.Query)
+	}
+
+	payload, err := service.ConvertViewToDefraJson(view)
+	if err != nil {
+		t.Fatalf("ConvertViewToDefraJson failed: %v", err)
+	}
+	if !strings.Contains(payload, `"Query":"Log(filter: {address: {_eq: \"0x1e3a\"}}) {\n  address\n  data\n}"`) {
+		t.Errorf("expected a normalized query in the payload, got %s", payload)
+	}
+}
+
+func TestAddQueryFromFieldPaths(t *testing.T) {
+	tempDir := t.TempDir()
+
+	store, err := local.NewLocalStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create temp store: %v", err)
+	}
+	schemastore, err := fileschema.NewFileSchemaStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create temp store: %v", err)
+	}
+	if _, err := service.InitView("built", store); err != nil {
+		t.Fatalf("failed to init view: %v", err)
+	}
+
+	run := func(args ...string) error {
+		viewName := "built"
+		cmd := cli.MakeAddQueryCommand(&viewName)
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetErr(&buf)
+		cmd.SetArgs(args)
+		cmd.SetContext(cli.WithSchemaStore(cli.WithViewStore(context.Background(), store), schemastore))
+		return cmd.Execute()
+	}
+
+	if err := run("--type", "Log", "--fields", "address,transaction.hash", "--filter", "address=0x1e3a"); err != nil {
+		t.Fatalf("add query failed: %v", err)
+	}
+
+	view, err := store.Load("built")
+	if err != nil {
+		t.Fatalf("failed to load view: %v", err)
+	}
+	want := "Log(filter: {address: {_eq: \"0x1e3a\"}}) {\n  address\n  transaction {\n    hash\n  }\n}"
+	if *view.Query != want {
+		t.Errorf("unexpected query:\n%s", *view.Query)
+	}
+
+	if err := run("--type", "Log", "--fields", "transaction.nope"); err == nil || !strings.Contains(err.Error(), "Transaction has no field nope") {
+		t.Errorf("expected an unknown path to be rejected, got %v", err)
+	}
+	if err := run("Log { address }", "--type", "Log", "--fields", "address"); err == nil {
+		t.Error("expected a query together with --type to be rejected")
+	}
+	if err := run("--fields", "address"); err == nil {
+		t.Error("expected --fields without --type to be rejected")
+	}
+}
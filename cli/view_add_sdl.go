@@ -1,18 +1,55 @@
 package cli
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/shinzonetwork/view-creator/core/schema"
 	"github.com/shinzonetwork/view-creator/core/service"
 	"github.com/spf13/cobra"
 )
 
 func MakeAddSdlCommand(viewName *string) *cobra.Command {
-	return &cobra.Command{
+	var infer bool
+	var opts schema.InferOptions
+	var renames []string
+
+	cmd := &cobra.Command{
 		Use:   "sdl '<sdl>'",
 		Short: "Add or update the sdl of the view",
-		Args:  cobra.ExactArgs(1),
+		Long: `Add or update the sdl of the view. With --infer the SDL is derived from the fields the
+view's query selects, typed as in the schema. Nested relation fields are flattened into
+camelCase names (transaction.hash becomes transactionHash) unless renamed with
+--rename transaction.hash=txHash.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			store := mustGetContextViewStore(cmd)
-			view, err := service.UpdateSDL(*viewName, args[0], store)
+
+			if infer == (len(args) == 1) {
+				return fmt.Errorf("pass either an SDL or --infer")
+			}
+
+			sdl := ""
+			if infer {
+				opts.Rename = map[string]string{}
+				for _, r := range renames {
+					path, name, ok := strings.Cut(r, "=")
+					if !ok {
+						return fmt.Errorf("invalid --rename %q, expected path=name", r)
+					}
+					opts.Rename[path] = name
+				}
+
+				inferred, err := service.InferViewSDL(*viewName, opts, store, mustGetContextSchemaStore(cmd))
+				if err != nil {
+					return fmt.Errorf("failed to infer SDL: %w", err)
+				}
+				sdl = inferred
+			} else {
+				sdl = args[0]
+			}
+
+			view, err := service.UpdateSDL(*viewName, sdl, store)
 			if err != nil {
 				return err
 			}
@@ -22,4 +59,10 @@ func MakeAddSdlCommand(viewName *string) *cobra.Command {
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&infer, "infer", false, "Derive the SDL from the view's query")
+	cmd.Flags().StringVar(&opts.TypeName, "type-name", "", "Name of the inferred type (defaults to the view name)")
+	cmd.Flags().BoolVar(&opts.Materialized, "materialized", false, "Set @materialized(if: true) on the inferred type")
+	cmd.Flags().StringArrayVar(&renames, "rename", nil, "Name an inferred field, e.g. transaction.hash=txHash (repeatable)")
+	return cmd
 }
@@ -7,6 +7,8 @@ import (
 	"testing"
 
 	"github.com/shinzonetwork/view-creator/cli"
+	"github.com/shinzonetwork/view-creator/core/schema/store/fileschema"
+	"github.com/shinzonetwork/view-creator/core/service"
 	"github.com/shinzonetwork/view-creator/core/view/store/local"
 )
 
@@ -138,3 +140,89 @@ type FilteredAndDecodedLogs @materialized(if: false) {hash: String block: String
 		t.Errorf("unexpected output.\nGot:\n%s\nExpected prefix:\n%s", out, expected)
 	}
 }
+
+func TestAddSdlInferredFromQuery(t *testing.T) {
+	tempDir := t.TempDir()
+
+	store, err := local.NewLocalStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create temp store: %v", err)
+	}
+	schemastore, err := fileschema.NewFileSchemaStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create temp store: %v", err)
+	}
+	if _, err := service.InitView("inferred", store); err != nil {
+		t.Fatalf("failed to init view: %v", err)
+	}
+
+	run := func(args ...string) error {
+		viewName := "inferred"
+		cmd := cli.MakeAddSdlCommand(&viewName)
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetErr(&buf)
+		cmd.SetArgs(args)
+		cmd.SetContext(cli.WithSchemaStore(cli.WithViewStore(context.Background(), store), schemastore))
+		return cmd.Execute()
+	}
+
+	if err := run("--infer"); err == nil || !strings.Contains(err.Error(), "has no query") {
+		t.Errorf("expected a view without a query to be rejected, got %v", err)
+	}
+
+	if _, err := service.UpdateQuery("inferred", "Log { address transaction { hash block { number } } }", store, schemastore, service.QueryOptions{}); err != nil {
+		t.Fatalf("failed to add query: %v", err)
+	}
+
+	if err := run("type X {a: String}", "--infer"); err == nil || !strings.Contains(err.Error(), "either an SDL or --infer") {
+		t.Errorf("expected an SDL with --infer to be rejected, got %v", err)
+	}
+
+	if err := run("--infer", "--type-name", "FilteredLogs", "--materialized", "--rename", "transaction.hash=txHash"); err != nil {
+		t.Fatalf("add sdl --infer failed: %v", err)
+	}
+
+	view, err := store.Load("inferred")
+	if err != nil {
+		t.Fatalf("failed to load view: %v", err)
+	}
+	want := "type FilteredLogs @materialized(if: true) {\n  address: String\n  txHash: String\n  transactionBlockNumber: Int\n}"
+	if view.Sdl == nil || *view.Sdl != want {
+		t.Errorf("unexpected SDL:\n%v", view.Sdl)
+	}
+
+	if err := run("--infer"); err != nil {
+		t.Fatalf("add sdl --infer failed: %v", err)
+	}
+	view, _ = store.Load("inferred")
+	if !strings.HasPrefix(*view.Sdl, "type Inferred @materialized(if: false)") {
+		t.Errorf("expected the type to be named after the view, got:\n%s", *view.Sdl)
+	}
+}
+
+func TestAddSdlWithSeveralTypes(t *testing.T) {
+	store, err := local.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create temp store: %v", err)
+	}
+	if _, err := service.InitView("transfers", store); err != nil {
+		t.Fatalf("failed to init view: %v", err)
+	}
+
+	viewName := "transfers"
+	cmd := cli.MakeAddSdlCommand(&viewName)
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{`type Transfer @materialized(if: true) { amount: Int from: Account @relation(name: "account_transfers") }
+type Account @materialized(if: true) { address: String transfers: [Transfer] @relation(name: "account_transfers") }`})
+	cmd.SetContext(cli.WithViewStore(context.Background(), store))
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("add sdl failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "📦 Collections: Transfer, Account") {
+		t.Errorf("expected the collections to be listed, got:\n%s", buf.String())
+	}
+}
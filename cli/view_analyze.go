@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/shinzonetwork/view-creator/core/config"
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/spf13/cobra"
+)
+
+func MakeViewAnalyzeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "analyze <name>",
+		Short: "Estimate how expensive the view query is for indexers",
+		Long: `Estimate how expensive the view query is for indexers. Reports the relation depth, the
+paths through list relations (each multiplies the documents read), the filtered fields
+with and without an @index in the schema, and a cost class: low, medium, high or extreme.
+
+The command fails, like view deploy, when the cost class is above deploy.maxCost in
+config.json (high by default).`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			viewstore := mustGetContextViewStore(cmd)
+			schemastore := mustGetContextSchemaStore(cmd)
+
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			analysis, err := service.AnalyzeView(args[0], viewstore, schemastore)
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("🔎 Analysis of %s:\n", args[0])
+			cmd.Printf(" - Depth: %d\n", analysis.Depth)
+			if len(analysis.FanOut) > 0 {
+				cmd.Printf(" - Fan-out: %s\n", strings.Join(analysis.FanOut, ", "))
+			} else {
+				cmd.Println(" - Fan-out: none")
+			}
+			if len(analysis.Indexed) > 0 {
+				cmd.Printf(" - Indexed filters: %s\n", strings.Join(analysis.Indexed, ", "))
+			}
+			if len(analysis.Unindexed) > 0 {
+				cmd.Printf(" - Unindexed filters: %s\n", strings.Join(analysis.Unindexed, ", "))
+			}
+			limit := string(cfg.Deploy.MaxCost)
+			if limit == "" {
+				limit = "none"
+			}
+			cmd.Printf(" - Cost: %s (deploy limit: %s)\n", analysis.Cost, limit)
+
+			for _, warning := range analysis.Warnings {
+				cmd.Printf("⚠️  %s\n", warning)
+			}
+
+			return service.CheckQueryCost(analysis, cfg.Deploy.MaxCost)
+		},
+	}
+
+	return cmd
+}
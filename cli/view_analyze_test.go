@@ -0,0 +1,77 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/cli"
+	"github.com/shinzonetwork/view-creator/core/config"
+	"github.com/shinzonetwork/view-creator/core/schema/store/fileschema"
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/shinzonetwork/view-creator/core/view/store/local"
+)
+
+func TestViewAnalyze(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+
+	viewStore, err := local.NewLocalStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create view store: %v", err)
+	}
+	schemaStore, err := fileschema.NewFileSchemaStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+
+	if _, err := service.InitView("blocks", viewStore); err != nil {
+		t.Fatalf("failed to init view: %v", err)
+	}
+	query := `Block(filter: {miner: {_eq: "0x1"}}) { hash transactions { logs { address } } }`
+	if _, err := service.UpdateQuery("blocks", query, viewStore, schemaStore, service.QueryOptions{}); err != nil {
+		t.Fatalf("failed to set query: %v", err)
+	}
+
+	run := func() (string, error) {
+		cmd := cli.MakeViewAnalyzeCommand()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs([]string{"blocks"})
+		cmd.SetContext(cli.WithSchemaStore(cli.WithViewStore(context.Background(), viewStore), schemaStore))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	out, err := run()
+	if err != nil {
+		t.Fatalf("analyze failed: %v", err)
+	}
+	for _, want := range []string{
+		" - Depth: 3",
+		" - Fan-out: Block.transactions.logs",
+		" - Unindexed filters: Block.miner",
+		" - Cost: high (deploy limit: high)",
+		"⚠️  Block.transactions.logs nests 2 list relations",
+		"⚠️  filter on Block.miner has no @index",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	cfg, err := config.Set(config.Default(), "deploy.maxCost", "medium")
+	if err != nil {
+		t.Fatalf("failed to set config: %v", err)
+	}
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	if _, err := run(); !errors.Is(err, service.ErrQueryTooExpensive) {
+		t.Errorf("expected the configured limit to fail the command, got %v", err)
+	}
+}
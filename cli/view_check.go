@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/spf13/cobra"
+)
+
+func MakeViewCheckCommand() *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "check [name]",
+		Short: "Revalidate views against the current schema and report schema drift",
+		Long: `Revalidate views against the current schema and report schema drift. The SDL of each
+view is also compared with the fields that reach it: the query output, with nested fields
+flattened as by 'view add sdl --infer', or the output a lens declares with
+'view add lens --output'. SDL fields nothing produces or produces with another type fail
+the check; produced fields the SDL leaves out are reported as unused.`,
+		Args: cobra.MaximumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if all == (len(args) == 1) {
+				return fmt.Errorf("pass either a view name or --all")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			viewstore := mustGetContextViewStore(cmd)
+			schemastore := mustGetContextSchemaStore(cmd)
+
+			var checks []service.ViewCheck
+			if all {
+				var err error
+				checks, err = service.CheckAllViews(viewstore, schemastore)
+				if err != nil {
+					return err
+				}
+			} else {
+				check, err := service.CheckView(args[0], viewstore, schemastore)
+				if err != nil {
+					return err
+				}
+				checks = append(checks, check)
+			}
+
+			invalid, unmapped := 0, 0
+			for _, check := range checks {
+				printViewCheck(cmd, check)
+				if check.Status == service.CheckInvalid {
+					invalid++
+				}
+				if check.Fields != nil && !check.Fields.OK() {
+					unmapped++
+				}
+			}
+
+			if invalid > 0 {
+				return fmt.Errorf("%d view(s) no longer validate against the current schema", invalid)
+			}
+			if unmapped > 0 {
+				return fmt.Errorf("%d view(s) have SDL fields their query or lenses do not produce", unmapped)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Check every stored view")
+	return cmd
+}
+
+func printViewCheck(cmd *cobra.Command, check service.ViewCheck) {
+	out := cmd.OutOrStdout()
+
+	switch check.Status {
+	case service.CheckValid:
+		fmt.Fprintf(out, "✅ %s: valid, schema unchanged\n", check.View)
+	case service.CheckDrifted:
+		fmt.Fprintf(out, "⚠️  %s: valid, but the schema changed since the query was validated\n", check.View)
+	case service.CheckUnpinned:
+		fmt.Fprintf(out, "✅ %s: valid (no schema recorded)\n", check.View)
+	case service.CheckInvalid:
+		fmt.Fprintf(out, "❌ %s: invalid\n", check.View)
+		for _, line := range strings.Split(check.Err.Error(), "\n") {
+			fmt.Fprintf(out, "   %s\n", line)
+		}
+	case service.CheckNoQuery:
+		fmt.Fprintf(out, "➖ %s: no query\n", check.View)
+	}
+
+	if len(check.Changed) > 0 {
+		fmt.Fprintf(out, "   changed: %s\n", strings.Join(check.Changed, ", "))
+	}
+	if len(check.Removed) > 0 {
+		fmt.Fprintf(out, "   removed: %s\n", strings.Join(check.Removed, ", "))
+	}
+
+	if fields := check.Fields; fields != nil {
+		if fields.OK() {
+			fmt.Fprintf(out, "   SDL fields all produced by the %s\n", fields.Source)
+		}
+		if len(fields.Unmapped) > 0 {
+			fmt.Fprintf(out, "   unmapped (not produced by the %s): %s\n", fields.Source, strings.Join(fields.Unmapped, ", "))
+		}
+		if len(fields.Mismatched) > 0 {
+			fmt.Fprintf(out, "   mismatched: %s\n", strings.Join(fields.Mismatched, ", "))
+		}
+		if len(fields.Unused) > 0 {
+			fmt.Fprintf(out, "   unused: %s\n", strings.Join(fields.Unused, ", "))
+		}
+	}
+	if check.FieldsNote != "" {
+		fmt.Fprintf(out, "   %s\n", check.FieldsNote)
+	}
+}
@@ -0,0 +1,154 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/cli"
+	"github.com/shinzonetwork/view-creator/core/schema/store/fileschema"
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/shinzonetwork/view-creator/core/view/store/local"
+)
+
+func TestViewCheckAll(t *testing.T) {
+	tempDir := t.TempDir()
+
+	viewStore, err := local.NewLocalStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create view store: %v", err)
+	}
+	schemaStore, err := fileschema.NewFileSchemaStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+
+	if err := schemaStore.SaveCustom("type Token { address: String }"); err != nil {
+		t.Fatalf("failed to save custom schema: %v", err)
+	}
+	for _, name := range []string{"logs", "tokens"} {
+		if _, err := service.InitView(name, viewStore); err != nil {
+			t.Fatalf("failed to init view: %v", err)
+		}
+	}
+	if _, err := service.UpdateQuery("logs", "Log { address }", viewStore, schemaStore, service.QueryOptions{}); err != nil {
+		t.Fatalf("failed to set query: %v", err)
+	}
+	if _, err := service.UpdateQuery("tokens", "Token { address }", viewStore, schemaStore, service.QueryOptions{Snapshot: true}); err != nil {
+		t.Fatalf("failed to set query: %v", err)
+	}
+
+	if err := schemaStore.SaveCustom("type Token { contract: String }"); err != nil {
+		t.Fatalf("failed to save custom schema: %v", err)
+	}
+
+	cmd := cli.MakeViewCheckCommand()
+	cmd.SetArgs([]string{"--all"})
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetContext(cli.WithSchemaStore(cli.WithViewStore(context.Background(), viewStore), schemaStore))
+
+	err = cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "1 view(s) no longer validate") {
+		t.Errorf("expected check to fail for one view, got %v", err)
+	}
+
+	result := out.String()
+	for _, want := range []string{
+		"⚠️  logs: valid, but the schema changed since the query was validated",
+		"❌ tokens: invalid",
+		"   changed: Token",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in output:\n%s", want, result)
+		}
+	}
+}
+
+func TestViewCheckReportsUnmappedFields(t *testing.T) {
+	tempDir := t.TempDir()
+
+	viewStore, err := local.NewLocalStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create view store: %v", err)
+	}
+	schemaStore, err := fileschema.NewFileSchemaStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+
+	if _, err := service.InitView("logs", viewStore); err != nil {
+		t.Fatalf("failed to init view: %v", err)
+	}
+	if _, err := service.UpdateQuery("logs", "Log { address transactionHash blockNumber }", viewStore, schemaStore, service.QueryOptions{}); err != nil {
+		t.Fatalf("failed to set query: %v", err)
+	}
+	if _, err := service.UpdateSDL("logs", "type Logs @materialized(if: false) { address: String hash: String blockNumber: String }", viewStore); err != nil {
+		t.Fatalf("failed to set sdl: %v", err)
+	}
+
+	cmd := cli.MakeViewCheckCommand()
+	cmd.SetArgs([]string{"logs"})
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetContext(cli.WithSchemaStore(cli.WithViewStore(context.Background(), viewStore), schemaStore))
+
+	err = cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "1 view(s) have SDL fields their query or lenses do not produce") {
+		t.Errorf("expected check to fail on unmapped fields, got %v", err)
+	}
+
+	result := out.String()
+	for _, want := range []string{
+		"unmapped (not produced by the query): hash",
+		"mismatched: blockNumber (SDL String, query Int)",
+		"unused: transactionHash",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, result)
+		}
+	}
+}
+
+func TestViewCheckNotesQueriesWithSeveralRoots(t *testing.T) {
+	tempDir := t.TempDir()
+
+	viewStore, err := local.NewLocalStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create view store: %v", err)
+	}
+	schemaStore, err := fileschema.NewFileSchemaStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+
+	if _, err := service.InitView("chain", viewStore); err != nil {
+		t.Fatalf("failed to init view: %v", err)
+	}
+	if _, err := service.UpdateQuery("chain", "Log { address } Block { hash }", viewStore, schemaStore, service.QueryOptions{}); err != nil {
+		t.Fatalf("failed to set query: %v", err)
+	}
+	if _, err := service.UpdateSDL("chain", "type Chain @materialized(if: false) { address: String }", viewStore); err != nil {
+		t.Fatalf("failed to set sdl: %v", err)
+	}
+
+	cmd := cli.MakeViewCheckCommand()
+	cmd.SetArgs([]string{"--all"})
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetContext(cli.WithSchemaStore(cli.WithViewStore(context.Background(), viewStore), schemaStore))
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("expected check to pass, got %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "SDL fields not compared: can only derive fields from a query with one root selection, found 2") {
+		t.Errorf("expected a note on the skipped field comparison, got:\n%s", out.String())
+	}
+}
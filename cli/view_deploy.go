@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 
+	"github.com/shinzonetwork/view-creator/core/config"
 	"github.com/shinzonetwork/view-creator/core/schema/store/fileschema"
 	"github.com/shinzonetwork/view-creator/core/service"
 	"github.com/spf13/cobra"
@@ -10,10 +11,14 @@ import (
 
 func MakeViewDeployCommand() *cobra.Command {
 	var target string
+	var offline bool
+	var maxPayload int
+	var maxCost string
 
 	cmd := &cobra.Command{
 		Use:   "deploy <name>",
 		Short: "Deploy a view to local, devnet, or mainnet",
+		Long:  "Deploy a view to local, devnet, or mainnet. Every lens is checked again against the sandbox policy under lens.policy in ~/.shinzo/config.json, which is global and applies to every view.",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			viewstore := mustGetContextViewStore(cmd)
@@ -22,17 +27,42 @@ func MakeViewDeployCommand() *cobra.Command {
 				return err
 			}
 
+			downloads, err := newDefraDownloadCache(offline)
+			if err != nil {
+				return err
+			}
+
 			viewName := args[0]
 
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			if err := service.CheckLensPolicy(viewName, viewstore, cfg.Lens.Policy); err != nil {
+				return err
+			}
+
 			switch target {
 			case "local":
-				return service.StartLocalNodeAndDeployView(viewName, viewstore, schemastore)
+				return service.StartLocalNodeAndDeployView(viewName, viewstore, schemastore, downloads)
 			case "devnet":
 				wallet, err := service.LoadWallet()
 				if err != nil {
 					return err
 				}
-				return service.StartLocalNodeTestAndDeploy(viewName, viewstore, schemastore, wallet)
+
+				opts := service.DeployOptions{MaxPayload: cfg.Deploy.MaxPayload, MaxCost: cfg.Deploy.MaxCost}
+				if cmd.Flags().Changed("max-payload") {
+					opts.MaxPayload = maxPayload
+				}
+				if cmd.Flags().Changed("max-cost") {
+					if err := opts.MaxCost.UnmarshalText([]byte(maxCost)); err != nil {
+						return err
+					}
+				}
+
+				return service.StartLocalNodeTestAndDeploy(viewName, viewstore, schemastore, downloads, wallet, opts)
 			case "mainnet":
 				return fmt.Errorf("target '%s' not yet supported", target)
 			default:
@@ -42,6 +72,9 @@ func MakeViewDeployCommand() *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&target, "target", "", "Where to deploy the view: local, devnet, or mainnet (required)")
+	cmd.Flags().BoolVar(&offline, "offline", false, "Only use a DefraDB binary already in the download cache")
+	cmd.Flags().IntVar(&maxPayload, "max-payload", 0, "Maximum view payload size in bytes, overriding deploy.maxPayload in config.json (0 disables the check)")
+	cmd.Flags().StringVar(&maxCost, "max-cost", "", "Most expensive query cost class to deploy (low, medium, high or extreme), overriding deploy.maxCost in config.json (\"\" disables the check)")
 
 	cmd.MarkFlagRequired("target")
 	return cmd
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/spf13/cobra"
+)
+
+func MakeViewFmtCommand() *cobra.Command {
+	var all bool
+	var check bool
+
+	cmd := &cobra.Command{
+		Use:   "fmt [name]",
+		Short: "Pretty-print the query and SDL of a view",
+		Long: `Pretty-print the stored query and SDL and save the result as a revision. With --check
+nothing is saved, and the command fails when a view is not formatted.`,
+		Args: cobra.MaximumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if all == (len(args) == 1) {
+				return fmt.Errorf("pass either a view name or --all")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			viewstore := mustGetContextViewStore(cmd)
+			opts := service.FormatOptions{Check: check}
+
+			var results []service.ViewFormat
+			if all {
+				var err error
+				results, err = service.FormatAllViews(viewstore, opts)
+				if err != nil {
+					return err
+				}
+			} else {
+				result, err := service.FormatView(args[0], viewstore, opts)
+				if err != nil {
+					return err
+				}
+				results = append(results, result)
+			}
+
+			out := cmd.OutOrStdout()
+			unformatted := 0
+			for _, result := range results {
+				if !result.Changed() {
+					fmt.Fprintf(out, "✅ %s: already formatted\n", result.View)
+					continue
+				}
+
+				var parts []string
+				if result.Query {
+					parts = append(parts, "query")
+				}
+				if result.SDL {
+					parts = append(parts, "sdl")
+				}
+				if check {
+					unformatted++
+					fmt.Fprintf(out, "❌ %s: not formatted (%s)\n", result.View, strings.Join(parts, ", "))
+				} else {
+					fmt.Fprintf(out, "✨ %s: formatted %s\n", result.View, strings.Join(parts, ", "))
+				}
+			}
+
+			if unformatted > 0 {
+				return fmt.Errorf("%d view(s) are not formatted; run `viewkit view fmt` to fix", unformatted)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Format every stored view")
+	cmd.Flags().BoolVar(&check, "check", false, "Report unformatted views without saving them")
+	return cmd
+}
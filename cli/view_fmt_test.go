@@ -0,0 +1,89 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/cli"
+	"github.com/shinzonetwork/view-creator/core/schema/store/fileschema"
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/shinzonetwork/view-creator/core/view/store/local"
+)
+
+func TestMakeViewFmtCommand(t *testing.T) {
+	tempDir := t.TempDir()
+
+	viewStore, err := local.NewLocalStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create view store: %v", err)
+	}
+	schemaStore, err := fileschema.NewFileSchemaStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+
+	if _, err := service.InitView("logs", viewStore); err != nil {
+		t.Fatalf("failed to init view: %v", err)
+	}
+	if _, err := service.UpdateQuery("logs", "Log {address topics}", viewStore, schemaStore, service.QueryOptions{}); err != nil {
+		t.Fatalf("failed to set query: %v", err)
+	}
+	if _, err := service.UpdateSDL("logs", "type Logs {address: String}", viewStore); err != nil {
+		t.Fatalf("failed to set SDL: %v", err)
+	}
+
+	run := func(args ...string) (string, error) {
+		cmd := cli.MakeViewFmtCommand()
+		cmd.SetArgs(args)
+
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetContext(cli.WithViewStore(context.Background(), viewStore))
+
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	out, err := run("logs", "--check")
+	if err == nil || !strings.Contains(out, "❌ logs: not formatted (query, sdl)") {
+		t.Fatalf("expected the check to fail: %v\n%s", err, out)
+	}
+	before, _ := viewStore.Load("logs")
+
+	out, err = run("logs")
+	if err != nil || !strings.Contains(out, "✨ logs: formatted query, sdl") {
+		t.Fatalf("fmt failed: %v\n%s", err, out)
+	}
+
+	view, err := viewStore.Load("logs")
+	if err != nil {
+		t.Fatalf("failed to load view: %v", err)
+	}
+	if *view.Query != "Log {\n  address\n  topics\n}" || *view.Sdl != "type Logs {\n  address: String\n}" {
+		t.Errorf("unexpected formatted view:\n%s\n%s", *view.Query, *view.Sdl)
+	}
+	if view.Metadata.Version != before.Metadata.Version+1 {
+		t.Errorf("expected fmt to add one revision, version went from %d to %d", before.Metadata.Version, view.Metadata.Version)
+	}
+
+	out, err = run("--all", "--check")
+	if err != nil || !strings.Contains(out, "✅ logs: already formatted") {
+		t.Errorf("expected the view to be formatted: %v\n%s", err, out)
+	}
+
+	// re-adding the query with other whitespace keeps the formatted text and adds no revision
+	if _, err := service.UpdateQuery("logs", "Log { address   topics }", viewStore, schemaStore, service.QueryOptions{}); err != nil {
+		t.Fatalf("failed to set query: %v", err)
+	}
+	after, _ := viewStore.Load("logs")
+	if after.Metadata.Version != view.Metadata.Version || *after.Query != *view.Query {
+		t.Errorf("expected a cosmetic change to be ignored, got version %d and query:\n%s", after.Metadata.Version, *after.Query)
+	}
+
+	if _, err := run(); err == nil {
+		t.Error("expected a missing view name to be rejected")
+	}
+}
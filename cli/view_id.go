@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/spf13/cobra"
+)
+
+func MakeViewIdCommand() *cobra.Command {
+	var address string
+
+	cmd := &cobra.Command{
+		Use:   "id <name>",
+		Short: "Compute the ID a view would be deployed under, without deploying it",
+		Long: `Compute the ID a view would be deployed under, without deploying it. The ID joins the name
+of the first SDL type with the keccak256 hash of the deployer address and the canonical
+deploy payload, so it only changes when the view does. The address of the local wallet is
+used unless --address is given.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			viewstore := mustGetContextViewStore(cmd)
+
+			if address == "" {
+				wallet, err := service.LoadWallet()
+				if err != nil {
+					return err
+				}
+				address = wallet.Address
+			}
+			if !common.IsHexAddress(address) {
+				return fmt.Errorf("invalid deployer address %q", address)
+			}
+
+			viewHash, viewid, err := service.ViewID(args[0], viewstore, common.HexToAddress(address))
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("🔑 View ID:  %s\n", viewid)
+			cmd.Printf("🔑 View Key: %s\n", viewHash)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&address, "address", "", "Deployer address (defaults to the local wallet)")
+	return cmd
+}
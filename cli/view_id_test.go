@@ -0,0 +1,53 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/cli"
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/shinzonetwork/view-creator/core/view/store/local"
+)
+
+func TestViewIdCommand(t *testing.T) {
+	store, err := local.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create temp store: %v", err)
+	}
+	if _, err := service.InitView("logs", store); err != nil {
+		t.Fatalf("failed to init view: %v", err)
+	}
+	if _, err := service.UpdateSDL("logs", "type FilteredLogs @materialized(if: false) {hash: String}", store); err != nil {
+		t.Fatalf("failed to set sdl: %v", err)
+	}
+
+	run := func(args ...string) (string, error) {
+		cmd := cli.MakeViewIdCommand()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetErr(&buf)
+		cmd.SetArgs(args)
+		cmd.SetContext(cli.WithViewStore(context.Background(), store))
+		err := cmd.Execute()
+		return buf.String(), err
+	}
+
+	out, err := run("logs", "--address", "0x1e3aA9fE4Ef01D3cB3189c129a49E3C03126C636")
+	if err != nil {
+		t.Fatalf("view id failed: %v", err)
+	}
+	if !strings.Contains(out, "🔑 View ID:  FilteredLogs_0x") || !strings.Contains(out, "🔑 View Key: 0x") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	again, err := run("logs", "--address", "0x1e3aA9fE4Ef01D3cB3189c129a49E3C03126C636")
+	if err != nil || again != out {
+		t.Errorf("expected the same ID on every run, got %v:\n%s", err, again)
+	}
+
+	if _, err := run("logs", "--address", "nope"); err == nil || !strings.Contains(err.Error(), "invalid deployer address") {
+		t.Errorf("expected an invalid address to be rejected, got %v", err)
+	}
+}
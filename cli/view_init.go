@@ -1,6 +1,9 @@
 package cli
 
 import (
+	"fmt"
+
+	schemastore "github.com/shinzonetwork/view-creator/core/schema/store"
 	"github.com/shinzonetwork/view-creator/core/service"
 	"github.com/spf13/cobra"
 )
@@ -8,6 +11,7 @@ import (
 func MakeViewInitCommand() *cobra.Command {
 	var verbose bool
 	var jsonOutput bool
+	var profile string
 
 	cmd := &cobra.Command{
 		Use:   "init [name]",
@@ -15,11 +19,32 @@ func MakeViewInitCommand() *cobra.Command {
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			storeImpl := mustGetContextViewStore(cmd)
+
+			// new views target the active profile unless told otherwise
+			schemaStore := getContextSchemaStore(cmd)
+			if schemaStore != nil {
+				if profile == "" {
+					profile = schemaStore.Profile()
+				}
+				if _, err := schemaStore.WithProfile(profile); err != nil {
+					return err
+				}
+			} else if profile != "" {
+				return fmt.Errorf("no schema store to resolve profile %s", profile)
+			}
+
 			view, err := service.InitView(args[0], storeImpl)
 			if err != nil {
 				return err
 			}
 
+			if profile != "" && profile != schemastore.DefaultProfile {
+				view, err = service.SetViewProfile(args[0], profile, storeImpl, schemaStore)
+				if err != nil {
+					return err
+				}
+			}
+
 			printViewPretty(cmd, view, verbose, jsonOutput)
 			return nil
 		},
@@ -27,6 +52,7 @@ func MakeViewInitCommand() *cobra.Command {
 
 	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the view in raw JSON format")
 	cmd.Flags().BoolVar(&verbose, "verbose", false, "Show full output including revision history")
+	cmd.Flags().StringVar(&profile, "profile", "", "Schema profile the view targets (default: the active profile)")
 
 	return cmd
 }
@@ -21,12 +21,15 @@ func MakeViewInspectCommand() *cobra.Command {
 			}
 
 			printViewPretty(cmd, view, verbose, jsonOutput)
+			if verbose && !jsonOutput {
+				printLensSources(cmd, view, storeImpl)
+			}
 			return nil
 		},
 	}
 
 	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the view in raw JSON format")
-	cmd.Flags().BoolVar(&verbose, "verbose", false, "Show full output including revision history")
+	cmd.Flags().BoolVar(&verbose, "verbose", false, "Show full output including revision history and lens sources")
 
 	return cmd
 }
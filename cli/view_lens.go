@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func MakeViewLensCommand() *cobra.Command {
+	var viewName string
+
+	cmd := &cobra.Command{
+		Use:   "lens",
+		Short: "Work with the lens assets of an existing view",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := setContextViewStore(cmd); err != nil {
+				return err
+			}
+
+			if viewName == "" {
+				return fmt.Errorf("view name is required (use --name)")
+			}
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&viewName, "name", "", "Name of the view")
+
+	cmd.AddCommand(MakeViewLensStripCommand(&viewName))
+
+	return cmd
+}
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/spf13/cobra"
+)
+
+func MakeViewLensStripCommand(viewName *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "strip <label>",
+		Short: "Remove custom, name and debug sections from a lens",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store := mustGetContextViewStore(cmd)
+
+			result, err := service.StripLens(*viewName, args[0], store)
+			if err != nil {
+				return err
+			}
+
+			if len(result.Removed) == 0 {
+				cmd.Printf("✅ Lens %s has no sections to strip (%d bytes)\n", args[0], result.Before)
+				return nil
+			}
+
+			cmd.Printf("✅ Stripped lens %s: %d → %d bytes\n", args[0], result.Before, result.After)
+			cmd.Printf(" - Removed sections: %s\n", strings.Join(result.Removed, ", "))
+			cmd.Printf(" - Saved as %s; roll back the view to restore the original\n", result.Path)
+			return nil
+		},
+	}
+
+	return cmd
+}
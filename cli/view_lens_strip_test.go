@@ -0,0 +1,65 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/cli"
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/shinzonetwork/view-creator/core/view/store/local"
+)
+
+func TestViewLensStripAndSize(t *testing.T) {
+	tempDir := t.TempDir()
+
+	store, err := local.NewLocalStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create temp store: %v", err)
+	}
+
+	viewName := "testview"
+	if _, err := service.InitView(viewName, store); err != nil {
+		t.Fatalf("failed to init view: %v", err)
+	}
+
+	watPath := filepath.Join(tempDir, "noop.wat")
+	if err := os.WriteFile(watPath, []byte(`(module (func $transform (export "transform")))`), 0644); err != nil {
+		t.Fatalf("failed to write wat: %v", err)
+	}
+	if _, err := service.InitLens(viewName, "noop", watPath, nil, store, service.LensOptions{}); err != nil {
+		t.Fatalf("failed to add lens: %v", err)
+	}
+
+	cmd := cli.MakeViewLensStripCommand(&viewName)
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"noop"})
+	cmd.SetContext(cli.WithViewStore(context.Background(), store))
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("strip command failed: %v", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "✅ Stripped lens noop: ") || !strings.Contains(buf.String(), "Removed sections: name") ||
+		!strings.Contains(buf.String(), "Saved as assets/noop.stripped.wasm") {
+		t.Errorf("unexpected strip output:\n%s", buf.String())
+	}
+
+	cmd = cli.MakeViewSizeCommand()
+	buf.Reset()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{viewName})
+	cmd.SetContext(cli.WithViewStore(context.Background(), store))
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("size command failed: %v", err)
+	}
+
+	for _, want := range []string{"📦 Size of testview (bytes):", " query ", " sdl ", " lens noop ", " Deploy payload "} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected size output to contain %q, got:\n%s", want, buf.String())
+		}
+	}
+}
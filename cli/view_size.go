@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/spf13/cobra"
+)
+
+func MakeViewSizeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "size <name>",
+		Short: "Show how many bytes each part of a view adds to the deploy payload",
+		Long:  "Lists the raw size of the query, SDL and every lens next to its encoded size in the deploy payload. Lenses are inlined as base64, so their encoded size is about a third larger.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			storeImpl := mustGetContextViewStore(cmd)
+
+			report, err := service.ViewSize(args[0], storeImpl)
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("📦 Size of %s (bytes):\n", args[0])
+			cmd.Printf(" %-24s %10s %10s\n", "Part", "Raw", "Encoded")
+			for _, entry := range report.Entries {
+				cmd.Printf(" %-24s %10d %10d\n", entry.Part, entry.Raw, entry.Encoded)
+			}
+			cmd.Printf(" %-24s %21d\n", "Deploy payload", report.Payload)
+			return nil
+		},
+	}
+
+	return cmd
+}
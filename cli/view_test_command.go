@@ -7,6 +7,8 @@ import (
 )
 
 func MakeViewTestCommand() *cobra.Command {
+	var offline bool
+
 	cmd := &cobra.Command{
 		Use:   "test <name>",
 		Short: "Test if the view can build and compile successfully",
@@ -18,11 +20,18 @@ func MakeViewTestCommand() *cobra.Command {
 				return err
 			}
 
+			downloads, err := newDefraDownloadCache(offline)
+			if err != nil {
+				return err
+			}
+
 			viewName := args[0]
 
-			return service.StartLocalNodeAndTestView(viewName, viewstore, schemastore)
+			return service.StartLocalNodeAndTestView(viewName, viewstore, schemastore, downloads)
 		},
 	}
 
+	cmd.Flags().BoolVar(&offline, "offline", false, "Only use a DefraDB binary already in the download cache")
+
 	return cmd
 }
@@ -0,0 +1,410 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Options controls how the download cache talks to the network.
+type Options struct {
+	// Timeout bounds a single HTTP request, including reading the body.
+	Timeout time.Duration
+	// Retries is the number of additional attempts after a failed request.
+	Retries int
+	// Backoff is the delay before the first retry; it doubles on every attempt.
+	Backoff time.Duration
+	// MaxSize caps the number of bytes accepted for a single download. Zero disables the cap.
+	MaxSize int64
+	// Offline serves only cached content and never touches the network.
+	Offline bool
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Timeout: 60 * time.Second,
+		Retries: 3,
+		Backoff: 500 * time.Millisecond,
+		MaxSize: 32 << 20,
+	}
+}
+
+// Entry records what is known about a cached URL.
+type Entry struct {
+	URL          string `json:"url"`
+	SHA256       string `json:"sha256"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Size         int64  `json:"size"`
+	FetchedAt    string `json:"fetchedAt"`
+}
+
+// partialState keeps the validators of an interrupted download so it can be resumed.
+type partialState struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// DownloadCache stores downloaded blobs by sha256 and remembers which URL produced them.
+//
+// Layout under BasePath:
+//   - index.json         URL -> Entry
+//   - blobs/<sha256>     content-addressed blobs
+//   - partial/<key>      in-flight downloads that can be resumed with a Range request
+type DownloadCache struct {
+	BasePath string
+	Options  Options
+	Client   *http.Client
+}
+
+type retryableError struct {
+	err error
+}
+
+func (e retryableError) Error() string { return e.err.Error() }
+func (e retryableError) Unwrap() error { return e.err }
+
+func NewDownloadCache(opts Options, dir ...string) (*DownloadCache, error) {
+	var base string
+
+	if len(dir) == 0 || dir[0] == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("unable to get home directory: %w", err)
+		}
+		base = filepath.Join(home, ".shinzo", "cache")
+	} else {
+		base = filepath.Join(dir[0], ".shinzo", "cache")
+	}
+
+	for _, sub := range []string{"blobs", "partial"} {
+		if err := os.MkdirAll(filepath.Join(base, sub), 0755); err != nil {
+			return nil, fmt.Errorf("unable to create cache directory: %w", err)
+		}
+	}
+
+	return &DownloadCache{BasePath: base, Options: opts}, nil
+}
+
+// Fetch returns the local path of the blob served at url, downloading it when needed.
+//
+// If expectedSHA is set, a blob with that digest is returned straight from the cache
+// regardless of URL, and freshly downloaded content must match it.
+func (c *DownloadCache) Fetch(url string, expectedSHA string) (string, error) {
+	expectedSHA = strings.ToLower(strings.TrimSpace(expectedSHA))
+
+	if expectedSHA != "" && c.hasBlob(expectedSHA) {
+		return c.blobPath(expectedSHA), nil
+	}
+
+	index, err := c.loadIndex()
+	if err != nil {
+		return "", err
+	}
+
+	entry, cached := index[url]
+	if cached && (!c.hasBlob(entry.SHA256) || (expectedSHA != "" && entry.SHA256 != expectedSHA)) {
+		cached = false
+	}
+
+	if c.Options.Offline {
+		if cached {
+			return c.blobPath(entry.SHA256), nil
+		}
+		return "", fmt.Errorf("%w: %s", ErrNotCached, url)
+	}
+
+	var validator *Entry
+	if cached {
+		validator = &entry
+	}
+
+	var fetched *Entry
+	for attempt := 0; ; attempt++ {
+		fetched, err = c.fetchOnce(url, validator)
+		if err == nil {
+			break
+		}
+
+		var retry retryableError
+		if !errors.As(err, &retry) || attempt >= c.Options.Retries {
+			return "", err
+		}
+		time.Sleep(c.Options.Backoff << attempt)
+	}
+
+	// not modified since the cached copy was fetched
+	if fetched == nil {
+		return c.blobPath(entry.SHA256), nil
+	}
+
+	partialPath := c.partialPath(url)
+	if expectedSHA != "" && fetched.SHA256 != expectedSHA {
+		c.clearPartial(url)
+		return "", fmt.Errorf("%w: %s (expected %s, got %s)", ErrChecksumMismatch, url, expectedSHA, fetched.SHA256)
+	}
+
+	if err := os.Rename(partialPath, c.blobPath(fetched.SHA256)); err != nil {
+		return "", fmt.Errorf("failed to store downloaded blob: %w", err)
+	}
+	c.clearPartial(url)
+
+	index[url] = *fetched
+	if err := c.saveIndex(index); err != nil {
+		return "", err
+	}
+
+	return c.blobPath(fetched.SHA256), nil
+}
+
+// Lookup returns the cache entry recorded for url, if any.
+func (c *DownloadCache) Lookup(url string) (Entry, bool, error) {
+	index, err := c.loadIndex()
+	if err != nil {
+		return Entry{}, false, err
+	}
+	entry, ok := index[url]
+	if ok && !c.hasBlob(entry.SHA256) {
+		return Entry{}, false, nil
+	}
+	return entry, ok, nil
+}
+
+// fetchOnce performs a single request. It returns a nil entry when the server reports
+// that the cached copy is still current.
+func (c *DownloadCache) fetchOnce(url string, cached *Entry) (*Entry, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	partialPath := c.partialPath(url)
+	partial := c.loadPartial(url)
+
+	var offset int64
+	if info, err := os.Stat(partialPath); err == nil && info.Size() > 0 && (partial.ETag != "" || partial.LastModified != "") {
+		offset = info.Size()
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		if partial.ETag != "" {
+			req.Header.Set("If-Range", partial.ETag)
+		} else {
+			req.Header.Set("If-Range", partial.LastModified)
+		}
+	}
+
+	resp, err := c.client().Do(req)
+	if err != nil {
+		return nil, retryableError{fmt.Errorf("failed to download %s: %w", url, err)}
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		c.clearPartial(url)
+		return nil, nil
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// the partial copy no longer matches the remote file; start over without a range
+		c.clearPartial(url)
+		return c.fetchOnce(url, cached)
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		if start, ok := contentRangeStart(resp.Header.Get("Content-Range")); !ok || start != offset {
+			c.clearPartial(url)
+			return c.fetchOnce(url, cached)
+		}
+	case resp.StatusCode == http.StatusOK:
+		offset = 0
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return nil, retryableError{fmt.Errorf("unexpected response from %s: %s", url, resp.Status)}
+	default:
+		return nil, fmt.Errorf("unexpected response from %s: %s", url, resp.Status)
+	}
+
+	maxSize := c.Options.MaxSize
+	if maxSize > 0 && resp.ContentLength > 0 && offset+resp.ContentLength > maxSize {
+		c.clearPartial(url)
+		return nil, fmt.Errorf("%w: %s is %d bytes (limit %d)", ErrTooLarge, url, offset+resp.ContentLength, maxSize)
+	}
+
+	state := partialState{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	if err := c.savePartial(url, state); err != nil {
+		return nil, err
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_WRONLY | os.O_APPEND
+	}
+
+	out, err := os.OpenFile(partialPath, flags, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open partial download: %w", err)
+	}
+
+	var body io.Reader = resp.Body
+	if maxSize > 0 {
+		body = io.LimitReader(resp.Body, maxSize-offset+1)
+	}
+
+	written, copyErr := io.Copy(out, body)
+	if err := out.Close(); err != nil && copyErr == nil {
+		copyErr = err
+	}
+	if copyErr != nil {
+		return nil, retryableError{fmt.Errorf("failed to read response from %s: %w", url, copyErr)}
+	}
+
+	if maxSize > 0 && offset+written > maxSize {
+		c.clearPartial(url)
+		return nil, fmt.Errorf("%w: %s exceeds %d bytes", ErrTooLarge, url, maxSize)
+	}
+
+	sum, size, err := hashFile(partialPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Entry{
+		URL:          url,
+		SHA256:       sum,
+		ETag:         state.ETag,
+		LastModified: state.LastModified,
+		Size:         size,
+		FetchedAt:    strconv.FormatInt(time.Now().Unix(), 10),
+	}, nil
+}
+
+// contentRangeStart reads the first byte position of a "bytes <start>-<end>/<size>" header.
+func contentRangeStart(header string) (int64, bool) {
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return 0, false
+	}
+	start, _, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(start), 10, 64)
+	return n, err == nil
+}
+
+func (c *DownloadCache) client() *http.Client {
+	if c.Client != nil {
+		return c.Client
+	}
+	return &http.Client{Timeout: c.Options.Timeout}
+}
+
+func (c *DownloadCache) blobPath(sum string) string {
+	return filepath.Join(c.BasePath, "blobs", sum)
+}
+
+func (c *DownloadCache) hasBlob(sum string) bool {
+	if sum == "" {
+		return false
+	}
+	info, err := os.Stat(c.blobPath(sum))
+	return err == nil && info.Mode().IsRegular()
+}
+
+func (c *DownloadCache) partialPath(url string) string {
+	key := sha256.Sum256([]byte(url))
+	return filepath.Join(c.BasePath, "partial", hex.EncodeToString(key[:]))
+}
+
+func (c *DownloadCache) loadPartial(url string) partialState {
+	var state partialState
+	data, err := os.ReadFile(c.partialPath(url) + ".json")
+	if err != nil {
+		return state
+	}
+	_ = json.Unmarshal(data, &state)
+	return state
+}
+
+func (c *DownloadCache) savePartial(url string, state partialState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to encode partial download state: %w", err)
+	}
+	if err := os.WriteFile(c.partialPath(url)+".json", data, 0644); err != nil {
+		return fmt.Errorf("failed to write partial download state: %w", err)
+	}
+	return nil
+}
+
+func (c *DownloadCache) clearPartial(url string) {
+	path := c.partialPath(url)
+	_ = os.Remove(path)
+	_ = os.Remove(path + ".json")
+}
+
+func (c *DownloadCache) loadIndex() (map[string]Entry, error) {
+	index := map[string]Entry{}
+
+	data, err := os.ReadFile(filepath.Join(c.BasePath, "index.json"))
+	if os.IsNotExist(err) {
+		return index, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read cache index: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse cache index: %w", err)
+	}
+	return index, nil
+}
+
+func (c *DownloadCache) saveIndex(index map[string]Entry) error {
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cache index: %w", err)
+	}
+
+	path := filepath.Join(c.BasePath, "index.json")
+	temp := path + ".tmp"
+
+	if err := os.WriteFile(temp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write temp cache index: %w", err)
+	}
+	if err := os.Rename(temp, path); err != nil {
+		return fmt.Errorf("failed to replace cache index: %w", err)
+	}
+	return nil
+}
+
+func hashFile(path string) (string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to open download: %w", err)
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, file)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to hash download: %w", err)
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), size, nil
+}
@@ -0,0 +1,238 @@
+package cache_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shinzonetwork/view-creator/core/cache"
+)
+
+var wasmBlob = []byte("\x00asm\x01\x00\x00\x00")
+
+func newTestCache(t *testing.T, opts cache.Options) *cache.DownloadCache {
+	t.Helper()
+	c, err := cache.NewDownloadCache(opts, t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	return c
+}
+
+func TestDownloadCacheRevalidatesWithETag(t *testing.T) {
+	var full, notModified int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&full, 1)
+		w.Header().Set("ETag", `"v1"`)
+		w.Write(wasmBlob)
+	}))
+	defer server.Close()
+
+	c := newTestCache(t, cache.DefaultOptions())
+
+	first, err := c.Fetch(server.URL+"/lens.wasm", "")
+	if err != nil {
+		t.Fatalf("first fetch failed: %v", err)
+	}
+
+	second, err := c.Fetch(server.URL+"/lens.wasm", "")
+	if err != nil {
+		t.Fatalf("second fetch failed: %v", err)
+	}
+
+	if first != second {
+		t.Errorf("expected the same cached blob, got %s and %s", first, second)
+	}
+	if full != 1 || notModified != 1 {
+		t.Errorf("expected 1 full and 1 conditional request, got %d and %d", full, notModified)
+	}
+
+	data, err := os.ReadFile(second)
+	if err != nil {
+		t.Fatalf("failed to read cached blob: %v", err)
+	}
+	if string(data) != string(wasmBlob) {
+		t.Errorf("unexpected cached content: %q", data)
+	}
+}
+
+func TestDownloadCacheOffline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(wasmBlob)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	online, err := cache.NewDownloadCache(cache.DefaultOptions(), dir)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+
+	if _, err := online.Fetch(server.URL+"/cached.wasm", ""); err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	server.Close()
+
+	opts := cache.DefaultOptions()
+	opts.Offline = true
+	offline, err := cache.NewDownloadCache(opts, dir)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+
+	if _, err := offline.Fetch(server.URL+"/cached.wasm", ""); err != nil {
+		t.Errorf("expected cached lens to be served offline, got: %v", err)
+	}
+
+	if _, err := offline.Fetch(server.URL+"/missing.wasm", ""); !errors.Is(err, cache.ErrNotCached) {
+		t.Errorf("expected ErrNotCached, got: %v", err)
+	}
+}
+
+func TestDownloadCacheVerifiesChecksum(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(wasmBlob)
+	}))
+	defer server.Close()
+
+	c := newTestCache(t, cache.DefaultOptions())
+
+	wrong := strings.Repeat("0", 64)
+	if _, err := c.Fetch(server.URL+"/lens.wasm", wrong); !errors.Is(err, cache.ErrChecksumMismatch) {
+		t.Fatalf("expected ErrChecksumMismatch, got: %v", err)
+	}
+
+	sum := sha256.Sum256(wasmBlob)
+	if _, err := c.Fetch(server.URL+"/lens.wasm", hex.EncodeToString(sum[:])); err != nil {
+		t.Fatalf("expected matching checksum to succeed, got: %v", err)
+	}
+}
+
+func TestDownloadCacheEnforcesMaxSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(make([]byte, 1024))
+	}))
+	defer server.Close()
+
+	opts := cache.DefaultOptions()
+	opts.MaxSize = 512
+	c := newTestCache(t, opts)
+
+	if _, err := c.Fetch(server.URL+"/big.wasm", ""); !errors.Is(err, cache.ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge, got: %v", err)
+	}
+}
+
+func TestDownloadCacheRetriesAndResumes(t *testing.T) {
+	var attempts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			// promise the full blob but hang up halfway through
+			w.Header().Set("Content-Length", "8")
+			w.Write(wasmBlob[:4])
+			return
+		}
+
+		if r.Header.Get("Range") != "bytes=4-" || r.Header.Get("If-Range") != `"v1"` {
+			w.Write(wasmBlob)
+			return
+		}
+
+		w.Header().Set("Content-Range", "bytes 4-7/8")
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(wasmBlob[4:])
+	}))
+	defer server.Close()
+
+	opts := cache.DefaultOptions()
+	opts.Backoff = time.Millisecond
+	c := newTestCache(t, opts)
+
+	path, err := c.Fetch(server.URL+"/lens.wasm", "")
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read cached blob: %v", err)
+	}
+	if string(data) != string(wasmBlob) {
+		t.Errorf("unexpected resumed content: %q", data)
+	}
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestDownloadCacheRestartsUnusableResume(t *testing.T) {
+	cases := map[string]func(w http.ResponseWriter){
+		"range not satisfiable": func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+		},
+		"range from another offset": func(w http.ResponseWriter) {
+			w.Header().Set("Content-Range", "bytes 0-7/8")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(wasmBlob)
+		},
+	}
+
+	for name, rangeResponse := range cases {
+		t.Run(name, func(t *testing.T) {
+			var attempts int32
+			var ranges []string
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("ETag", `"v1"`)
+				ranges = append(ranges, r.Header.Get("Range"))
+
+				if atomic.AddInt32(&attempts, 1) == 1 {
+					w.Header().Set("Content-Length", "8")
+					w.Write(wasmBlob[:4])
+					return
+				}
+				if r.Header.Get("Range") != "" {
+					rangeResponse(w)
+					return
+				}
+				w.Write(wasmBlob)
+			}))
+			defer server.Close()
+
+			opts := cache.DefaultOptions()
+			opts.Backoff = time.Millisecond
+			c := newTestCache(t, opts)
+
+			path, err := c.Fetch(server.URL+"/lens.wasm", "")
+			if err != nil {
+				t.Fatalf("fetch failed: %v", err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read cached blob: %v", err)
+			}
+			if string(data) != string(wasmBlob) {
+				t.Errorf("unexpected content: %q", data)
+			}
+			if len(ranges) != 3 || ranges[1] != "bytes=4-" || ranges[2] != "" {
+				t.Errorf("expected a resume and then a full download, got ranges %q", ranges)
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package cache
+
+import "errors"
+
+var ErrNotCached = errors.New("resource is not cached and offline mode is enabled")
+var ErrChecksumMismatch = errors.New("downloaded content does not match expected sha256")
+var ErrTooLarge = errors.New("download exceeds maximum allowed size")
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shinzonetwork/view-creator/core/schema"
+	"github.com/shinzonetwork/view-creator/core/schema/source"
+	"github.com/shinzonetwork/view-creator/core/schema/store"
+	"github.com/shinzonetwork/view-creator/core/wasm"
+)
+
+// DefaultMaxPayload is the default cap, in bytes, on the view blob sent to the registry.
+const DefaultMaxPayload = 128 * 1024
+
+type Deploy struct {
+	// MaxPayload is the largest view blob deploy will sign, in bytes. Zero disables the check.
+	MaxPayload int `json:"maxPayload"`
+	// MaxCost is the most expensive query cost class deploy accepts, see `view analyze`.
+	// Empty disables the check.
+	MaxCost schema.CostClass `json:"maxCost"`
+}
+
+type Lens struct {
+	// Policy is the sandbox policy every lens module must satisfy.
+	Policy wasm.Policy `json:"policy"`
+}
+
+type Schema struct {
+	// Source is where `tools schema update` fetches the default schema from.
+	Source source.Source `json:"source"`
+	// Profile is the active schema profile, used by schema commands and new views.
+	Profile string `json:"profile"`
+	// Lint sets the severity of each `tools schema lint` rule.
+	Lint SchemaLint `json:"lint"`
+}
+
+type SchemaLint struct {
+	Rules map[string]schema.Severity `json:"rules"`
+}
+
+type Config struct {
+	Deploy Deploy `json:"deploy"`
+	Lens   Lens   `json:"lens"`
+	Schema Schema `json:"schema"`
+}
+
+func Default() Config {
+	return Config{
+		Deploy: Deploy{MaxPayload: DefaultMaxPayload, MaxCost: schema.CostHigh},
+		Lens:   Lens{Policy: wasm.DefaultPolicy()},
+		Schema: Schema{
+			Source:  source.Default(),
+			Profile: store.DefaultProfile,
+			Lint:    SchemaLint{Rules: schema.DefaultLintRules()},
+		},
+	}
+}
+
+// Path returns the location of config.json, under the home directory unless dir is given.
+func Path(dir ...string) (string, error) {
+	var base string
+	if len(dir) == 0 || dir[0] == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("could not get home directory: %w", err)
+		}
+		base = filepath.Join(home, ".shinzo")
+	} else {
+		base = filepath.Join(dir[0], ".shinzo")
+	}
+	return filepath.Join(base, "config.json"), nil
+}
+
+// Load reads config.json, falling back to defaults for a missing file or missing keys.
+func Load(dir ...string) (Config, error) {
+	cfg := Default()
+
+	path, err := Path(dir...)
+	if err != nil {
+		return cfg, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	} else if err != nil {
+		return cfg, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func Save(cfg Config, dir ...string) error {
+	path, err := Path(dir...)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config dir: %w", err)
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Set assigns a dotted key such as "deploy.maxPayload". The value is read as JSON when
+// possible and as a plain string otherwise.
+func Set(cfg Config, key string, value string) (Config, error) {
+	raw, err := json.Marshal(cfg)
+	if err != nil {
+		return cfg, err
+	}
+
+	var tree map[string]any
+	if err := json.Unmarshal(raw, &tree); err != nil {
+		return cfg, err
+	}
+
+	var parsed any
+	if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+		parsed = value
+	}
+
+	parts := strings.Split(key, ".")
+	node := tree
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := node[part].(map[string]any)
+		if !ok {
+			return cfg, fmt.Errorf("unknown config key %q", key)
+		}
+		node = child
+	}
+	last := parts[len(parts)-1]
+	if _, ok := node[last]; !ok {
+		return cfg, fmt.Errorf("unknown config key %q", key)
+	}
+	node[last] = parsed
+
+	raw, err = json.Marshal(tree)
+	if err != nil {
+		return cfg, err
+	}
+
+	var updated Config
+	if err := json.Unmarshal(raw, &updated); err != nil {
+		return cfg, fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	return updated, nil
+}
@@ -0,0 +1,55 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/core/config"
+)
+
+func TestLoadDefaultsWhenMissing(t *testing.T) {
+	cfg, err := config.Load(t.TempDir())
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if cfg.Deploy.MaxPayload != config.DefaultMaxPayload {
+		t.Errorf("expected default max payload %d, got %d", config.DefaultMaxPayload, cfg.Deploy.MaxPayload)
+	}
+}
+
+func TestSetAndSaveRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg, err := config.Set(config.Default(), "deploy.maxPayload", "4096")
+	if err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+	if err := config.Save(cfg, dir); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	loaded, err := config.Load(dir)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if loaded.Deploy.MaxPayload != 4096 {
+		t.Errorf("expected max payload 4096, got %d", loaded.Deploy.MaxPayload)
+	}
+}
+
+func TestSetRejectsUnknownKeysAndBadValues(t *testing.T) {
+	if _, err := config.Set(config.Default(), "deploy.maxPaylod", "1"); err == nil {
+		t.Error("expected an error for an unknown key")
+	}
+	if _, err := config.Set(config.Default(), "deploy.maxPayload", "lots"); err == nil {
+		t.Error("expected an error for a non-numeric payload size")
+	}
+	if _, err := config.Set(config.Default(), "deploy.maxCost", "cheap"); err == nil {
+		t.Error("expected an error for an unknown cost class")
+	}
+	if cfg, err := config.Set(config.Default(), "deploy.maxCost", "extreme"); err != nil || cfg.Deploy.MaxCost != "extreme" {
+		t.Errorf("expected the cost class to be set, got %q, %v", cfg.Deploy.MaxCost, err)
+	}
+	if cfg, err := config.Set(config.Default(), "deploy.maxCost", ""); err != nil || cfg.Deploy.MaxCost != "" {
+		t.Errorf("expected an empty cost class to disable the limit, got %q, %v", cfg.Deploy.MaxCost, err)
+	}
+}
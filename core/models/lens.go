@@ -4,4 +4,7 @@ type Lens struct {
 	Label     string         `json:"label"`
 	Path      string         `json:"path"`
 	Arguments map[string]any `json:"arguments"`
+	Source    string         `json:"source,omitempty"`
+	Output    string         `json:"output,omitempty"`
+	Digest    string         `json:"digest,omitempty"`
 }
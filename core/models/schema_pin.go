@@ -0,0 +1,9 @@
+package models
+
+// SchemaPin records the schema a view's query was last validated against.
+type SchemaPin struct {
+	Fingerprint string `json:"fingerprint"`
+	ValidatedAt string `json:"validatedAt"`
+	// Snapshot holds the SDL of the types the query used, when requested.
+	Snapshot string `json:"snapshot,omitempty"`
+}
@@ -1,9 +1,11 @@
 package models
 
 type View struct {
-	Name      string    `json:"name"`
-	Query     *string   `json:"query"`
-	Sdl       *string   `json:"sdl"`
-	Transform Transform `json:"transform"`
-	Metadata  Metadata  `json:"metadata"`
+	Name      string     `json:"name"`
+	Query     *string    `json:"query"`
+	Sdl       *string    `json:"sdl"`
+	Transform Transform  `json:"transform"`
+	Schema    *SchemaPin `json:"schema,omitempty"`
+	Profile   string     `json:"profile,omitempty"` // schema profile; the default profile when empty
+	Metadata  Metadata   `json:"metadata"`
 }
@@ -0,0 +1,270 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// CostClass is a coarse estimate of how expensive a view query is for the indexers that
+// run it.
+type CostClass string
+
+const (
+	CostLow     CostClass = "low"
+	CostMedium  CostClass = "medium"
+	CostHigh    CostClass = "high"
+	CostExtreme CostClass = "extreme"
+)
+
+var costClasses = []CostClass{CostLow, CostMedium, CostHigh, CostExtreme}
+
+// maxDepth is the relation depth past which a query is flagged.
+const maxDepth = 4
+
+func (c CostClass) rank() int {
+	for i, class := range costClasses {
+		if class == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// Exceeds reports whether c is more expensive than max.
+func (c CostClass) Exceeds(max CostClass) bool {
+	return c.rank() > max.rank()
+}
+
+// UnmarshalText accepts a cost class name, or an empty string for no limit.
+func (c *CostClass) UnmarshalText(text []byte) error {
+	class := CostClass(text)
+	if class != "" && class.rank() < 0 {
+		return fmt.Errorf("invalid cost class %q. Must be one of: low, medium, high, extreme", text)
+	}
+	*c = class
+	return nil
+}
+
+// QueryAnalysis describes the shape of a view query and what it costs to run.
+type QueryAnalysis struct {
+	Depth     int      // relation levels of the deepest selection, counting the root as 1
+	FanOut    []string // selection paths through list relations, such as "Block.transactions.logs"
+	MaxFanOut int      // most list relations along one path
+	Indexed   []string // filtered fields backed by @index, as "Type.field"
+	Unindexed []string // filtered fields without an index
+	Cost      CostClass
+	Warnings  []string
+}
+
+// AnalyzeQuery measures the depth and relation fan-out of a query and checks its filters
+// against the @index directives of the schema.
+//
+// The cost class scores two points per list relation on the widest path, one point when any
+// filter field is unindexed and one point past a depth of 4: up to 1 is low, up to 3 medium,
+// up to 5 high and anything above extreme.
+func AnalyzeQuery(schemaSource string, rawQuery string) (QueryAnalysis, error) {
+	normalized, err := NormalizeQuery(rawQuery)
+	if err != nil {
+		return QueryAnalysis{}, err
+	}
+	doc, err := ParseQuery(normalized)
+	if err != nil {
+		return QueryAnalysis{}, err
+	}
+
+	fullSchema, err := buildSchemaWithRoot(schemaSource)
+	if err != nil {
+		return QueryAnalysis{}, fmt.Errorf("invalid schema: %w", err)
+	}
+	schemaAST, err := gqlparser.LoadSchema(&ast.Source{Name: "combined.graphql", Input: fullSchema})
+	if err != nil {
+		return QueryAnalysis{}, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	a := &analyzer{schema: schemaAST, fanOutCounts: map[string]int{}, indexed: map[string]bool{}, unindexed: map[string]bool{}}
+	a.selections(schemaAST.Query, doc.Operations[0].SelectionSet, nil, 0, 0)
+
+	result := QueryAnalysis{
+		Depth:     a.depth,
+		FanOut:    a.fanOut,
+		MaxFanOut: a.maxFanOut,
+		Indexed:   sortedKeys(a.indexed),
+		Unindexed: sortedKeys(a.unindexed),
+	}
+
+	score := 2 * result.MaxFanOut
+	for _, path := range result.FanOut {
+		if n := a.fanOutCounts[path]; n > 1 {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s nests %d list relations; each level multiplies the documents read", path, n))
+		}
+	}
+	for _, field := range result.Unindexed {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("filter on %s has no @index and scans the whole collection", field))
+	}
+	if len(result.Unindexed) > 0 {
+		score++
+	}
+	if result.Depth > maxDepth {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("query nests %d levels of relations, more than %d", result.Depth, maxDepth))
+		score++
+	}
+
+	switch {
+	case score <= 1:
+		result.Cost = CostLow
+	case score <= 3:
+		result.Cost = CostMedium
+	case score <= 5:
+		result.Cost = CostHigh
+	default:
+		result.Cost = CostExtreme
+	}
+	return result, nil
+}
+
+type analyzer struct {
+	schema *ast.Schema
+
+	depth        int
+	fanOut       []string
+	fanOutCounts map[string]int
+	maxFanOut    int
+	indexed      map[string]bool
+	unindexed    map[string]bool
+}
+
+// selections walks a selection set of def. lists counts the list relations crossed to
+// reach it, the root collection excluded.
+func (a *analyzer) selections(def *ast.Definition, set ast.SelectionSet, path []string, depth int, lists int) {
+	nested := false
+	for _, sel := range set {
+		switch s := sel.(type) {
+		case *ast.Field:
+			if a.field(def, s, path, depth, lists) {
+				nested = true
+			}
+		case *ast.InlineFragment:
+			a.selections(def, s.SelectionSet, path, depth, lists)
+		}
+	}
+
+	// record each widest path once, at its deepest relation
+	if !nested && lists > 0 {
+		joined := strings.Join(path, ".")
+		if _, seen := a.fanOutCounts[joined]; !seen {
+			a.fanOut = append(a.fanOut, joined)
+			a.fanOutCounts[joined] = lists
+		}
+		if lists > a.maxFanOut {
+			a.maxFanOut = lists
+		}
+	}
+}
+
+// field walks one field and reports whether it is a relation with a selection of its own.
+func (a *analyzer) field(def *ast.Definition, f *ast.Field, path []string, depth int, lists int) bool {
+	fd := def.Fields.ForName(f.Name)
+	if fd == nil {
+		return false
+	}
+	target := a.schema.Types[fd.Type.Name()]
+
+	if filter := f.Arguments.ForName("filter"); filter != nil && target != nil {
+		a.filter(target, filter.Value)
+	}
+
+	if len(f.SelectionSet) == 0 || target == nil || target.Kind != ast.Object {
+		return false
+	}
+	if depth+1 > a.depth {
+		a.depth = depth + 1
+	}
+
+	// the root selection reads one collection; list relations below it fan out
+	if def != a.schema.Query && fd.Type.Elem != nil {
+		lists++
+	}
+	name := f.Name
+	if def == a.schema.Query {
+		name = fd.Type.Name()
+	}
+	a.selections(target, f.SelectionSet, append(append([]string{}, path...), name), depth+1, lists)
+	return true
+}
+
+// filter records the fields a filter argument on def compares, following relations and the
+// _and, _or and _not combinators.
+func (a *analyzer) filter(def *ast.Definition, v *ast.Value) {
+	if v == nil {
+		return
+	}
+	switch v.Kind {
+	case ast.ListValue:
+		for _, child := range v.Children {
+			a.filter(def, child.Value)
+		}
+		return
+	case ast.ObjectValue:
+	default:
+		return
+	}
+
+	for _, child := range v.Children {
+		switch child.Name {
+		case "_and", "_or", "_not":
+			a.filter(def, child.Value)
+			continue
+		case "_docID":
+			a.indexed[def.Name+"._docID"] = true
+			continue
+		}
+
+		fd := def.Fields.ForName(child.Name)
+		if fd == nil {
+			continue
+		}
+		if target := a.schema.Types[fd.Type.Name()]; target != nil && target.Kind == ast.Object {
+			a.filter(target, child.Value)
+			continue
+		}
+
+		key := def.Name + "." + fd.Name
+		if isIndexed(def, fd) {
+			a.indexed[key] = true
+		} else {
+			a.unindexed[key] = true
+		}
+	}
+}
+
+// isIndexed reports whether a field carries @index, or leads an @index on its type. A
+// composite index only serves filters on its first field.
+func isIndexed(def *ast.Definition, fd *ast.FieldDefinition) bool {
+	if fd.Directives.ForName("index") != nil {
+		return true
+	}
+	for _, d := range def.Directives.ForNames("index") {
+		includes := d.Arguments.ForName("includes")
+		if includes == nil || includes.Value == nil || len(includes.Value.Children) == 0 {
+			continue
+		}
+		first := includes.Value.Children[0].Value
+		if field := first.Children.ForName("field"); field != nil && field.Raw == fd.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
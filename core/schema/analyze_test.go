@@ -0,0 +1,98 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/core/schema"
+	"github.com/shinzonetwork/view-creator/tools"
+)
+
+func TestAnalyzeQuery(t *testing.T) {
+	a, err := schema.AnalyzeQuery(tools.DefaultSchema, `Log(filter: {blockNumber: {_gt: 100}}) { address transaction { hash } }`)
+	if err != nil {
+		t.Fatalf("AnalyzeQuery failed: %v", err)
+	}
+	if a.Depth != 2 || a.MaxFanOut != 0 || len(a.FanOut) != 0 || a.Cost != schema.CostLow || len(a.Warnings) != 0 {
+		t.Errorf("unexpected analysis of a flat query: %+v", a)
+	}
+	if !reflect.DeepEqual(a.Indexed, []string{"Log.blockNumber"}) || len(a.Unindexed) != 0 {
+		t.Errorf("unexpected filter fields: %+v", a)
+	}
+
+	a, err = schema.AnalyzeQuery(tools.DefaultSchema, `Block(filter: {_or: [{miner: {_eq: "0x1"}}, {number: {_eq: 1}}]}) {
+  hash
+  transactions(filter: {logs: {address: {_eq: "0x2"}}}) {
+    hash
+    logs { address events { blockNumber } }
+  }
+}`)
+	if err != nil {
+		t.Fatalf("AnalyzeQuery failed: %v", err)
+	}
+	if a.Depth != 4 || a.MaxFanOut != 3 || !reflect.DeepEqual(a.FanOut, []string{"Block.transactions.logs.events"}) {
+		t.Errorf("unexpected fan-out: %+v", a)
+	}
+	if !reflect.DeepEqual(a.Indexed, []string{"Block.number"}) || !reflect.DeepEqual(a.Unindexed, []string{"Block.miner", "Log.address"}) {
+		t.Errorf("unexpected filter fields: indexed %v, unindexed %v", a.Indexed, a.Unindexed)
+	}
+	if a.Cost != schema.CostExtreme {
+		t.Errorf("expected an extreme cost, got %s", a.Cost)
+	}
+	joined := strings.Join(a.Warnings, "\n")
+	for _, want := range []string{
+		"Block.transactions.logs.events nests 3 list relations",
+		"filter on Block.miner has no @index",
+		"filter on Log.address has no @index",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("expected warning %q, got:\n%s", want, joined)
+		}
+	}
+
+	a, err = schema.AnalyzeQuery(tools.DefaultSchema, `Block { transactions { hash } }`)
+	if err != nil {
+		t.Fatalf("AnalyzeQuery failed: %v", err)
+	}
+	if a.Cost != schema.CostMedium || len(a.Warnings) != 0 {
+		t.Errorf("expected one list relation to cost medium without warnings, got %+v", a)
+	}
+
+	a, err = schema.AnalyzeQuery(tools.DefaultSchema, `Block { transactions { logs { events { log { address } } } } }`)
+	if err != nil {
+		t.Fatalf("AnalyzeQuery failed: %v", err)
+	}
+	if a.Depth != 5 || !strings.Contains(strings.Join(a.Warnings, "\n"), "query nests 5 levels of relations, more than 4") {
+		t.Errorf("expected a depth warning, got %+v", a)
+	}
+
+	// a composite index serves filters on its first field only
+	pairs := `directive @index(includes: [IndexFieldInput]) on OBJECT
+input IndexFieldInput { field: String, direction: Ordering }
+
+type Pair @index(includes: [{field: "base"}, {field: "quote", direction: DESC}]) {
+  base: String
+  quote: String
+}`
+	a, err = schema.AnalyzeQuery(pairs, `Pair(filter: {base: {_eq: "ETH"}, quote: {_eq: "USDC"}}) { base }`)
+	if err != nil {
+		t.Fatalf("AnalyzeQuery failed: %v", err)
+	}
+	if !reflect.DeepEqual(a.Indexed, []string{"Pair.base"}) || !reflect.DeepEqual(a.Unindexed, []string{"Pair.quote"}) {
+		t.Errorf("unexpected filter fields: indexed %v, unindexed %v", a.Indexed, a.Unindexed)
+	}
+
+	if !schema.CostExtreme.Exceeds(schema.CostHigh) || schema.CostLow.Exceeds(schema.CostLow) {
+		t.Error("unexpected cost class ordering")
+	}
+	var class schema.CostClass
+	if err := json.Unmarshal([]byte(`"cheap"`), &class); err == nil {
+		t.Error("expected an unknown cost class to be rejected")
+	}
+	class = schema.CostHigh
+	if err := json.Unmarshal([]byte(`""`), &class); err != nil || class != "" {
+		t.Errorf("expected an empty cost class to mean no limit, got %q, %v", class, err)
+	}
+}
@@ -0,0 +1,244 @@
+package schema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/shinzonetwork/view-creator/core/schema/store"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// QuerySpec describes a query by field paths, such as "transaction.block.number", instead
+// of a selection set.
+type QuerySpec struct {
+	Type    string
+	Fields  []string
+	Filters []string // "path<op>value" with op one of =, !=, >, >=, <, <=
+}
+
+var filterOperators = []struct {
+	token string
+	name  string
+}{
+	// two-character tokens first so ">=" is not read as ">"
+	{"!=", "_ne"}, {">=", "_ge"}, {"<=", "_le"}, {"=", "_eq"}, {">", "_gt"}, {"<", "_lt"},
+}
+
+// BuildQuery builds the root selection for spec, following relations through the schema
+// and failing on any path that does not resolve to a scalar or enum field.
+func BuildQuery(defs []store.Definition, spec QuerySpec) (string, error) {
+	b := queryBuilder{defs: map[string]store.Definition{}}
+	for _, def := range defs {
+		b.defs[def.Name] = def
+	}
+
+	root, ok := b.defs[spec.Type]
+	if !ok || root.Kind != ast.Object {
+		return "", fmt.Errorf("type %s not found in schema", spec.Type)
+	}
+	if len(spec.Fields) == 0 {
+		return "", fmt.Errorf("at least one field is required")
+	}
+
+	selection := &selectionNode{}
+	for _, path := range spec.Fields {
+		if err := b.addField(selection, root, path); err != nil {
+			return "", err
+		}
+	}
+
+	filter := &filterNode{}
+	for _, expr := range spec.Filters {
+		if err := b.addFilter(filter, root, expr); err != nil {
+			return "", err
+		}
+	}
+
+	var out strings.Builder
+	out.WriteString(spec.Type)
+	if len(filter.keys) > 0 {
+		out.WriteString("(filter: " + filter.String() + ")")
+	}
+	selection.write(&out, "")
+	return out.String(), nil
+}
+
+type queryBuilder struct {
+	defs map[string]store.Definition
+}
+
+// resolve walks path from def and returns the field at its end and the type holding it.
+func (b queryBuilder) resolve(def store.Definition, path string) ([]string, store.Definition, *store.Field, error) {
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		field := def.Field(part)
+		if field == nil || def.Kind != ast.Object {
+			return nil, def, nil, fmt.Errorf("%s has no field %s (in %s)", def.Name, part, path)
+		}
+		if i == len(parts)-1 {
+			return parts, def, field, nil
+		}
+
+		next, ok := b.defs[strings.Trim(field.Type, "[]!")]
+		if !ok || next.Kind != ast.Object {
+			return nil, def, nil, fmt.Errorf("%s.%s is not a relation (in %s)", def.Name, part, path)
+		}
+		def = next
+	}
+	return nil, def, nil, fmt.Errorf("empty field path")
+}
+
+func (b queryBuilder) isLeaf(field *store.Field) bool {
+	target, ok := b.defs[strings.Trim(field.Type, "[]!")]
+	return !ok || target.Kind == ast.Scalar || target.Kind == ast.Enum
+}
+
+func (b queryBuilder) addField(selection *selectionNode, root store.Definition, path string) error {
+	parts, def, field, err := b.resolve(root, strings.TrimSpace(path))
+	if err != nil {
+		return err
+	}
+	if !b.isLeaf(field) {
+		return fmt.Errorf("%s.%s is a relation; select its fields, e.g. %s.<field>", def.Name, field.Name, path)
+	}
+
+	node := selection
+	for _, part := range parts {
+		node = node.child(part)
+	}
+	return nil
+}
+
+func (b queryBuilder) addFilter(filter *filterNode, root store.Definition, expr string) error {
+	at, token, op := -1, "", ""
+	for _, candidate := range filterOperators {
+		if i := strings.Index(expr, candidate.token); i >= 0 && (at < 0 || i < at || (i == at && len(candidate.token) > len(token))) {
+			at, token, op = i, candidate.token, candidate.name
+		}
+	}
+	if at <= 0 {
+		return fmt.Errorf("invalid filter %q, expected path=value", expr)
+	}
+
+	path := strings.TrimSpace(expr[:at])
+	raw := strings.TrimSpace(expr[at+len(token):])
+
+	parts, def, field, err := b.resolve(root, path)
+	if err != nil {
+		return err
+	}
+	if !b.isLeaf(field) {
+		return fmt.Errorf("cannot filter on relation %s.%s", def.Name, field.Name)
+	}
+
+	value, err := b.literal(field, raw)
+	if err != nil {
+		return fmt.Errorf("invalid filter %q: %w", expr, err)
+	}
+
+	node := filter
+	for _, part := range parts {
+		node = node.child(part)
+	}
+	// list fields match when any element matches
+	if strings.HasPrefix(field.Type, "[") {
+		node = node.child("_any")
+	}
+	if _, exists := node.children[op]; exists {
+		return fmt.Errorf("filter %q repeats %s on %s", expr, token, path)
+	}
+	node.child(op).value = value
+	return nil
+}
+
+// literal writes raw as a GraphQL value of the field's type.
+func (b queryBuilder) literal(field *store.Field, raw string) (string, error) {
+	named := strings.Trim(field.Type, "[]!")
+	switch named {
+	case "Int":
+		if _, err := strconv.ParseInt(raw, 10, 64); err != nil {
+			return "", fmt.Errorf("%s is an Int, got %q", field.Name, raw)
+		}
+		return raw, nil
+	case "Float":
+		if _, err := strconv.ParseFloat(raw, 64); err != nil {
+			return "", fmt.Errorf("%s is a Float, got %q", field.Name, raw)
+		}
+		return raw, nil
+	case "Boolean":
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return "", fmt.Errorf("%s is a Boolean, got %q", field.Name, raw)
+		}
+		return strconv.FormatBool(v), nil
+	}
+	if def, ok := b.defs[named]; ok && def.Kind == ast.Enum {
+		if def.Field(raw) == nil {
+			return "", fmt.Errorf("%s is not a value of %s", raw, named)
+		}
+		return raw, nil
+	}
+	return strconv.Quote(raw), nil
+}
+
+type selectionNode struct {
+	names    []string
+	children map[string]*selectionNode
+}
+
+func (n *selectionNode) child(name string) *selectionNode {
+	if n.children == nil {
+		n.children = map[string]*selectionNode{}
+	}
+	if c, ok := n.children[name]; ok {
+		return c
+	}
+	c := &selectionNode{}
+	n.children[name] = c
+	n.names = append(n.names, name)
+	return c
+}
+
+func (n *selectionNode) write(out *strings.Builder, indent string) {
+	if len(n.names) == 0 {
+		return
+	}
+	out.WriteString(" {\n")
+	for _, name := range n.names {
+		out.WriteString(indent + "  " + name)
+		n.children[name].write(out, indent+"  ")
+		out.WriteString("\n")
+	}
+	out.WriteString(indent + "}")
+}
+
+type filterNode struct {
+	keys     []string
+	children map[string]*filterNode
+	value    string
+}
+
+func (n *filterNode) child(key string) *filterNode {
+	if n.children == nil {
+		n.children = map[string]*filterNode{}
+	}
+	if c, ok := n.children[key]; ok {
+		return c
+	}
+	c := &filterNode{}
+	n.children[key] = c
+	n.keys = append(n.keys, key)
+	return c
+}
+
+func (n *filterNode) String() string {
+	if len(n.keys) == 0 {
+		return n.value
+	}
+	var fields []string
+	for _, key := range n.keys {
+		fields = append(fields, key+": "+n.children[key].String())
+	}
+	return "{" + strings.Join(fields, ", ") + "}"
+}
@@ -0,0 +1,59 @@
+package schema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/core/schema"
+	"github.com/shinzonetwork/view-creator/core/schema/store"
+	"github.com/shinzonetwork/view-creator/tools"
+)
+
+func TestBuildQuery(t *testing.T) {
+	defs, err := store.ParseDefinitions("default_schema.graphql", tools.DefaultSchema, store.OriginDefault)
+	if err != nil {
+		t.Fatalf("failed to parse default schema: %v", err)
+	}
+
+	got, err := schema.BuildQuery(defs, schema.QuerySpec{
+		Type:    "Log",
+		Fields:  []string{"address", "topics", "transaction.hash", "transaction.block.number"},
+		Filters: []string{"address=0x1e3a", "blockNumber>=100", "blockNumber<200", "topics=0xddf2", "transaction.from!=0x0"},
+	})
+	if err != nil {
+		t.Fatalf("BuildQuery failed: %v", err)
+	}
+	want := `Log(filter: {address: {_eq: "0x1e3a"}, blockNumber: {_ge: 100, _lt: 200}, topics: {_any: {_eq: "0xddf2"}}, transaction: {from: {_ne: "0x0"}}}) {
+  address
+  topics
+  transaction {
+    hash
+    block {
+      number
+    }
+  }
+}`
+	if got != want {
+		t.Errorf("unexpected query:\n%s\nwant:\n%s", got, want)
+	}
+
+	tests := []struct {
+		spec schema.QuerySpec
+		err  string
+	}{
+		{schema.QuerySpec{Type: "Nope", Fields: []string{"a"}}, "type Nope not found"},
+		{schema.QuerySpec{Type: "Log"}, "at least one field"},
+		{schema.QuerySpec{Type: "Log", Fields: []string{"addres"}}, "Log has no field addres"},
+		{schema.QuerySpec{Type: "Log", Fields: []string{"address.x"}}, "Log.address is not a relation"},
+		{schema.QuerySpec{Type: "Log", Fields: []string{"block"}}, "Log.block is a relation"},
+		{schema.QuerySpec{Type: "Log", Fields: []string{"address"}, Filters: []string{"blockNumber=abc"}}, "blockNumber is an Int"},
+		{schema.QuerySpec{Type: "Log", Fields: []string{"address"}, Filters: []string{"address"}}, "expected path=value"},
+		{schema.QuerySpec{Type: "Log", Fields: []string{"address"}, Filters: []string{"block=1"}}, "cannot filter on relation"},
+		{schema.QuerySpec{Type: "Log", Fields: []string{"address"}, Filters: []string{"address=a", "address=b"}}, "repeats ="},
+	}
+	for _, tt := range tests {
+		if _, err := schema.BuildQuery(defs, tt.spec); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("expected %+v to fail with %q, got %v", tt.spec, tt.err, err)
+		}
+	}
+}
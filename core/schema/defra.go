@@ -0,0 +1,260 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shinzonetwork/view-creator/core/schema/store"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// comparableScalars accept range operators in filters.
+var comparableScalars = map[string]bool{"Int": true, "Float": true, "DateTime": true}
+
+var numericScalars = map[string]bool{"Int": true, "Float": true}
+
+// queryRoot generates the query surface a DefraDB node exposes for a set of collections:
+// filter, order and groupBy arguments on every collection and relation, the _docID,
+// _deleted and _group fields, and the _count, _sum, _avg, _min and _max aggregates.
+type queryRoot struct {
+	doc         *ast.SchemaDocument
+	collections ast.DefinitionList
+	kinds       map[string]ast.DefinitionKind
+
+	// operator blocks needed by the filters, keyed by scalar or enum name
+	operators     map[string]bool
+	listOperators map[string]bool
+}
+
+func newQueryRoot(doc *ast.SchemaDocument) *queryRoot {
+	r := &queryRoot{
+		doc:           doc,
+		kinds:         map[string]ast.DefinitionKind{},
+		operators:     map[string]bool{"ID": true},
+		listOperators: map[string]bool{},
+	}
+	for _, name := range []string{"Int", "Float", "String", "Boolean", "ID"} {
+		r.kinds[name] = ast.Scalar
+	}
+	for _, def := range doc.Definitions {
+		r.kinds[def.Name] = def.Kind
+		if def.Kind == ast.Object && def.Name != "Query" {
+			r.collections = append(r.collections, def)
+		}
+	}
+	return r
+}
+
+func (r *queryRoot) isCollection(name string) bool {
+	return r.kinds[name] == ast.Object && name != "Query"
+}
+
+func (r *queryRoot) isLeaf(name string) bool {
+	return r.kinds[name] == ast.Scalar || r.kinds[name] == ast.Enum
+}
+
+func (r *queryRoot) numericFields(def *ast.Definition) []string {
+	var names []string
+	for _, f := range def.Fields {
+		if f.Type.Elem == nil && numericScalars[f.Type.NamedType] {
+			names = append(names, f.Name)
+		}
+	}
+	return names
+}
+
+// build returns the generated SDL and adds relation arguments to the parsed collections.
+func (r *queryRoot) build() (string, error) {
+	var b strings.Builder
+
+	b.WriteString("enum Ordering {\n  ASC\n  DESC\n}\n")
+	for _, def := range r.collections {
+		r.writeInputs(&b, def)
+	}
+	r.writeOperators(&b)
+
+	for _, def := range r.collections {
+		if err := r.addRelationArguments(def); err != nil {
+			return "", err
+		}
+		if ext := r.extension(def); ext != "" {
+			fmt.Fprintf(&b, "\nextend type %s {\n%s}\n", def.Name, ext)
+		}
+	}
+
+	b.WriteString("\nschema {\n  query: Query\n}\n\ntype Query {\n")
+	for _, def := range r.collections {
+		fmt.Fprintf(&b, "  %s(%s, docID: [ID], cid: String, showDeleted: Boolean): [%s]\n", def.Name, listArguments(def.Name), def.Name)
+	}
+	var count []string
+	for _, def := range r.collections {
+		count = append(count, def.Name+": "+def.Name+"AggregateArg")
+	}
+	if len(count) > 0 {
+		fmt.Fprintf(&b, "  _count(%s): Int\n", strings.Join(count, ", "))
+	}
+	var numeric []string
+	for _, def := range r.collections {
+		if len(r.numericFields(def)) > 0 {
+			numeric = append(numeric, def.Name+": "+def.Name+"NumericAggregateArg")
+		}
+	}
+	writeNumericAggregates(&b, numeric)
+	b.WriteString("}\n")
+
+	return b.String(), nil
+}
+
+func listArguments(name string) string {
+	return fmt.Sprintf("filter: %[1]sFilterArg, order: [%[1]sOrderArg], limit: Int, offset: Int, groupBy: [%[1]sField!]", name)
+}
+
+func writeNumericAggregates(b *strings.Builder, args []string) {
+	if len(args) == 0 {
+		return
+	}
+	for _, name := range []string{"_sum", "_avg", "_min", "_max"} {
+		fmt.Fprintf(b, "  %s(%s): Float\n", name, strings.Join(args, ", "))
+	}
+}
+
+// writeInputs writes the filter, order, field and aggregate inputs of a collection.
+func (r *queryRoot) writeInputs(b *strings.Builder, def *ast.Definition) {
+	name := def.Name
+	fmt.Fprintf(b, "\ninput %[1]sFilterArg {\n  _and: [%[1]sFilterArg!]\n  _or: [%[1]sFilterArg!]\n  _not: %[1]sFilterArg\n  _docID: IDOperatorBlock\n", name)
+	for _, f := range def.Fields {
+		named := f.Type.Name()
+		switch {
+		case r.isCollection(named):
+			fmt.Fprintf(b, "  %s: %sFilterArg\n", f.Name, named)
+		case r.isLeaf(named) && f.Type.Elem != nil:
+			r.listOperators[named] = true
+			r.operators[named] = true
+			fmt.Fprintf(b, "  %s: %sListOperatorBlock\n", f.Name, named)
+		case r.isLeaf(named):
+			r.operators[named] = true
+			fmt.Fprintf(b, "  %s: %sOperatorBlock\n", f.Name, named)
+		}
+	}
+	b.WriteString("}\n")
+
+	fmt.Fprintf(b, "\ninput %sOrderArg {\n  _docID: Ordering\n", name)
+	for _, f := range def.Fields {
+		if f.Type.Elem != nil {
+			continue
+		}
+		switch named := f.Type.Name(); {
+		case r.isCollection(named):
+			fmt.Fprintf(b, "  %s: %sOrderArg\n", f.Name, named)
+		case r.isLeaf(named):
+			fmt.Fprintf(b, "  %s: Ordering\n", f.Name)
+		}
+	}
+	b.WriteString("}\n")
+
+	fmt.Fprintf(b, "\nenum %sField {\n  _docID\n", name)
+	for _, f := range def.Fields {
+		if !(f.Type.Elem != nil && r.isCollection(f.Type.Name())) {
+			fmt.Fprintf(b, "  %s\n", f.Name)
+		}
+	}
+	b.WriteString("}\n")
+
+	fmt.Fprintf(b, "\ninput %[1]sAggregateArg {\n  filter: %[1]sFilterArg\n  limit: Int\n  offset: Int\n}\n", name)
+
+	if numeric := r.numericFields(def); len(numeric) > 0 {
+		fmt.Fprintf(b, "\nenum %sNumericField {\n  %s\n}\n", name, strings.Join(numeric, "\n  "))
+		fmt.Fprintf(b, "\ninput %[1]sNumericAggregateArg {\n  field: %[1]sNumericField!\n  filter: %[1]sFilterArg\n  limit: Int\n  offset: Int\n}\n", name)
+	}
+}
+
+func (r *queryRoot) writeOperators(b *strings.Builder) {
+	names := make([]string, 0, len(r.operators))
+	for name := range r.operators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		ops := []string{"_eq", "_ne", "_in", "_nin"}
+		if comparableScalars[name] {
+			ops = append(ops, "_gt", "_ge", "_lt", "_le")
+		}
+		fmt.Fprintf(b, "\ninput %sOperatorBlock {\n", name)
+		for _, op := range ops {
+			if op == "_in" || op == "_nin" {
+				fmt.Fprintf(b, "  %s: [%s]\n", op, name)
+			} else {
+				fmt.Fprintf(b, "  %s: %s\n", op, name)
+			}
+		}
+		if name == "String" {
+			b.WriteString("  _like: String\n  _nlike: String\n  _ilike: String\n  _nilike: String\n")
+		}
+		b.WriteString("}\n")
+
+		if r.listOperators[name] {
+			fmt.Fprintf(b, "\ninput %[1]sListOperatorBlock {\n  _any: %[1]sOperatorBlock\n  _all: %[1]sOperatorBlock\n  _none: %[1]sOperatorBlock\n}\n", name)
+		}
+	}
+}
+
+// addRelationArguments lets relation fields be filtered, and related lists be ordered,
+// limited and grouped.
+func (r *queryRoot) addRelationArguments(def *ast.Definition) error {
+	for _, f := range def.Fields {
+		named := f.Type.Name()
+		if !r.isCollection(named) || len(f.Arguments) > 0 {
+			continue
+		}
+		args := fmt.Sprintf("filter: %sFilterArg", named)
+		if f.Type.Elem != nil {
+			args = listArguments(named)
+		}
+		probe, err := store.ParseDocument("arguments.graphql", fmt.Sprintf("type Probe { f(%s): Int }", args))
+		if err != nil {
+			return err
+		}
+		f.Arguments = probe.Definitions[0].Fields[0].Arguments
+	}
+	return nil
+}
+
+// extension returns the generated fields of a collection that it does not declare itself.
+func (r *queryRoot) extension(def *ast.Definition) string {
+	var fields []string
+	add := func(name string, field string) {
+		if def.Fields.ForName(name) == nil {
+			fields = append(fields, "  "+field+"\n")
+		}
+	}
+
+	add("_docID", "_docID: ID")
+	add("_deleted", "_deleted: Boolean")
+	add("_group", fmt.Sprintf("_group: [%s]", def.Name))
+
+	count := []string{"_group: " + def.Name + "AggregateArg"}
+	var numeric []string
+	if len(r.numericFields(def)) > 0 {
+		numeric = append(numeric, "_group: "+def.Name+"NumericAggregateArg")
+	}
+	for _, f := range def.Fields {
+		named := f.Type.Name()
+		if f.Type.Elem == nil || !r.isCollection(named) {
+			continue
+		}
+		count = append(count, f.Name+": "+named+"AggregateArg")
+		if target := r.doc.Definitions.ForName(named); len(r.numericFields(target)) > 0 {
+			numeric = append(numeric, f.Name+": "+named+"NumericAggregateArg")
+		}
+	}
+	add("_count", fmt.Sprintf("_count(%s): Int", strings.Join(count, ", ")))
+	if len(numeric) > 0 {
+		for _, name := range []string{"_sum", "_avg", "_min", "_max"} {
+			add(name, fmt.Sprintf("%s(%s): Float", name, strings.Join(numeric, ", ")))
+		}
+	}
+
+	return strings.Join(fields, "")
+}
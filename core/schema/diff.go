@@ -0,0 +1,232 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shinzonetwork/view-creator/core/schema/store"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+type ChangeKind string
+
+const (
+	TypeAdded        ChangeKind = "type-added"
+	TypeRemoved      ChangeKind = "type-removed"
+	TypeKindChanged  ChangeKind = "type-kind-changed"
+	FieldAdded       ChangeKind = "field-added"
+	FieldRemoved     ChangeKind = "field-removed"
+	FieldTypeChanged ChangeKind = "field-type-changed"
+	ValueAdded       ChangeKind = "value-added"
+	ValueRemoved     ChangeKind = "value-removed"
+	MemberAdded      ChangeKind = "member-added"
+	MemberRemoved    ChangeKind = "member-removed"
+	DirectiveAdded   ChangeKind = "directive-added"
+	DirectiveRemoved ChangeKind = "directive-removed"
+	DirectiveChanged ChangeKind = "directive-changed"
+)
+
+// Change is one semantic difference between two schemas.
+type Change struct {
+	Kind     ChangeKind
+	Type     string
+	Field    string // field, enum value or union member; empty for type-level changes
+	Old      string // previous type reference, kind or directive
+	New      string
+	Breaking bool
+}
+
+func (c Change) target() string {
+	if c.Field == "" {
+		return c.Type
+	}
+	return c.Type + "." + c.Field
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case TypeAdded:
+		return fmt.Sprintf("%s %s added", c.New, c.Type)
+	case TypeRemoved:
+		return fmt.Sprintf("%s %s removed", c.Old, c.Type)
+	case TypeKindChanged:
+		return fmt.Sprintf("%s changed from %s to %s", c.Type, c.Old, c.New)
+	case FieldAdded:
+		return fmt.Sprintf("field %s: %s added", c.target(), c.New)
+	case FieldRemoved:
+		return fmt.Sprintf("field %s removed", c.target())
+	case FieldTypeChanged:
+		return fmt.Sprintf("field %s changed type from %s to %s", c.target(), c.Old, c.New)
+	case ValueAdded:
+		return fmt.Sprintf("enum value %s added", c.target())
+	case ValueRemoved:
+		return fmt.Sprintf("enum value %s removed", c.target())
+	case MemberAdded:
+		return fmt.Sprintf("union %s gained member %s", c.Type, c.Field)
+	case MemberRemoved:
+		return fmt.Sprintf("union %s lost member %s", c.Type, c.Field)
+	case DirectiveAdded:
+		return fmt.Sprintf("directive %s added on %s", c.New, c.target())
+	case DirectiveRemoved:
+		return fmt.Sprintf("directive %s removed from %s", c.Old, c.target())
+	case DirectiveChanged:
+		return fmt.Sprintf("directive on %s changed from %s to %s", c.target(), c.Old, c.New)
+	}
+	return string(c.Kind) + " " + c.target()
+}
+
+// DiffSchemas compares two schema sources and classifies every change. Removals, type
+// changes and changed or removed directives are breaking; additions are safe, except a
+// required input field without a default.
+func DiffSchemas(oldSource string, newSource string) ([]Change, error) {
+	oldDoc, err := store.ParseDocument("old.graphql", oldSource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse old schema: %w", err)
+	}
+	newDoc, err := store.ParseDocument("new.graphql", newSource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse new schema: %w", err)
+	}
+
+	var changes []Change
+
+	for _, def := range oldDoc.Directives {
+		if newDoc.Directives.ForName(def.Name) == nil {
+			changes = append(changes, Change{Kind: DirectiveRemoved, Type: "schema", Old: "@" + def.Name, Breaking: true})
+		}
+	}
+	for _, def := range newDoc.Directives {
+		if oldDoc.Directives.ForName(def.Name) == nil {
+			changes = append(changes, Change{Kind: DirectiveAdded, Type: "schema", New: "@" + def.Name})
+		}
+	}
+
+	for _, old := range oldDoc.Definitions {
+		def := newDoc.Definitions.ForName(old.Name)
+		switch {
+		case def == nil:
+			changes = append(changes, Change{Kind: TypeRemoved, Type: old.Name, Old: store.KindKeyword(old.Kind), Breaking: true})
+		case def.Kind != old.Kind:
+			changes = append(changes, Change{Kind: TypeKindChanged, Type: old.Name, Old: store.KindKeyword(old.Kind), New: store.KindKeyword(def.Kind), Breaking: true})
+		default:
+			changes = append(changes, diffDefinition(old, def)...)
+		}
+	}
+	for _, def := range newDoc.Definitions {
+		if oldDoc.Definitions.ForName(def.Name) == nil {
+			changes = append(changes, Change{Kind: TypeAdded, Type: def.Name, New: store.KindKeyword(def.Kind)})
+		}
+	}
+
+	return changes, nil
+}
+
+func diffDefinition(old *ast.Definition, def *ast.Definition) []Change {
+	changes := diffDirectives(old.Name, "", old.Directives, def.Directives)
+
+	for _, f := range old.Fields {
+		nf := def.Fields.ForName(f.Name)
+		if nf == nil {
+			changes = append(changes, Change{Kind: FieldRemoved, Type: old.Name, Field: f.Name, Old: f.Type.String(), Breaking: true})
+			continue
+		}
+		if oldType, newType := f.Type.String(), nf.Type.String(); oldType != newType {
+			changes = append(changes, Change{
+				Kind:     FieldTypeChanged,
+				Type:     old.Name,
+				Field:    f.Name,
+				Old:      oldType,
+				New:      newType,
+				Breaking: !safeTypeChange(def.Kind, f.Type, nf.Type),
+			})
+		}
+		changes = append(changes, diffDirectives(old.Name, f.Name, f.Directives, nf.Directives)...)
+	}
+	for _, f := range def.Fields {
+		if old.Fields.ForName(f.Name) == nil {
+			// a required input field breaks every existing caller
+			breaking := def.Kind == ast.InputObject && f.Type.NonNull && f.DefaultValue == nil
+			changes = append(changes, Change{Kind: FieldAdded, Type: def.Name, Field: f.Name, New: f.Type.String(), Breaking: breaking})
+		}
+	}
+
+	for _, v := range old.EnumValues {
+		if def.EnumValues.ForName(v.Name) == nil {
+			changes = append(changes, Change{Kind: ValueRemoved, Type: old.Name, Field: v.Name, Breaking: true})
+		}
+	}
+	for _, v := range def.EnumValues {
+		if old.EnumValues.ForName(v.Name) == nil {
+			changes = append(changes, Change{Kind: ValueAdded, Type: def.Name, Field: v.Name})
+		}
+	}
+
+	for _, member := range old.Types {
+		if !contains(def.Types, member) {
+			changes = append(changes, Change{Kind: MemberRemoved, Type: old.Name, Field: member, Breaking: true})
+		}
+	}
+	for _, member := range def.Types {
+		if !contains(old.Types, member) {
+			changes = append(changes, Change{Kind: MemberAdded, Type: def.Name, Field: member})
+		}
+	}
+
+	return changes
+}
+
+// safeTypeChange reports whether changing a field's type keeps existing clients working:
+// output fields may become non-null, input fields may become nullable.
+func safeTypeChange(kind ast.DefinitionKind, old *ast.Type, new *ast.Type) bool {
+	if kind == ast.InputObject {
+		old, new = new, old
+	}
+	for old != nil && new != nil {
+		if old.NonNull && !new.NonNull {
+			return false
+		}
+		if old.NamedType != new.NamedType || (old.Elem == nil) != (new.Elem == nil) {
+			return false
+		}
+		old, new = old.Elem, new.Elem
+	}
+	return true
+}
+
+func diffDirectives(typeName string, field string, old ast.DirectiveList, new ast.DirectiveList) []Change {
+	var changes []Change
+	for _, d := range old {
+		nd := new.ForName(d.Name)
+		if nd == nil {
+			changes = append(changes, Change{Kind: DirectiveRemoved, Type: typeName, Field: field, Old: formatDirective(d), Breaking: true})
+		} else if formatDirective(d) != formatDirective(nd) {
+			changes = append(changes, Change{Kind: DirectiveChanged, Type: typeName, Field: field, Old: formatDirective(d), New: formatDirective(nd), Breaking: true})
+		}
+	}
+	for _, d := range new {
+		if old.ForName(d.Name) == nil {
+			changes = append(changes, Change{Kind: DirectiveAdded, Type: typeName, Field: field, New: formatDirective(d)})
+		}
+	}
+	return changes
+}
+
+func formatDirective(d *ast.Directive) string {
+	if len(d.Arguments) == 0 {
+		return "@" + d.Name
+	}
+	var args []string
+	for _, a := range d.Arguments {
+		args = append(args, a.Name+": "+a.Value.String())
+	}
+	return "@" + d.Name + "(" + strings.Join(args, ", ") + ")"
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
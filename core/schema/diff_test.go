@@ -0,0 +1,44 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/core/schema"
+)
+
+func TestDiffSchemasClassification(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		change   string
+		breaking bool
+	}{
+		{"output field becomes non-null", "type A { x: String }", "type A { x: String! }", "field A.x changed type from String to String!", false},
+		{"output field becomes nullable", "type A { x: String! }", "type A { x: String }", "field A.x changed type from String! to String", true},
+		{"input field becomes nullable", "input A { x: String! }", "input A { x: String }", "field A.x changed type from String! to String", false},
+		{"list becomes scalar", "type A { x: [String] }", "type A { x: String }", "field A.x changed type from [String] to String", true},
+		{"required input field added", "input A { x: String }", "input A { x: String y: Int! }", "field A.y: Int! added", true},
+		{"optional input field added", "input A { x: String }", "input A { x: String y: Int! = 1 }", "field A.y: Int! added", false},
+		{"enum value removed", "enum E { A B }", "enum E { A }", "enum value E.B removed", true},
+		{"union member added", "union U = A", "union U = A | B", "union U gained member B", false},
+		{"kind changed", "type A { x: String }", "input A { x: String }", "A changed from type to input", true},
+		{"directive argument changed", "type A { x: String @index(unique: true) }", "type A { x: String @index }", "directive on A.x changed from @index(unique: true) to @index", true},
+		{"directive added", "type A { x: String }", "type A { x: String @index }", "directive @index added on A.x", false},
+		{"directive definition removed", "directive @index on FIELD_DEFINITION", "", "directive @index removed from schema", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := schema.DiffSchemas(tt.old, tt.new)
+			if err != nil {
+				t.Fatalf("DiffSchemas failed: %v", err)
+			}
+			if len(changes) != 1 {
+				t.Fatalf("expected one change, got %v", changes)
+			}
+			if changes[0].String() != tt.change || changes[0].Breaking != tt.breaking {
+				t.Errorf("got %q (breaking: %v), want %q (breaking: %v)", changes[0], changes[0].Breaking, tt.change, tt.breaking)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/shinzonetwork/view-creator/core/schema/store"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+type docType struct {
+	Name        string
+	Anchor      string
+	Keyword     string
+	Origin      store.Origin
+	Description string
+	Fields      []docField
+	Values      []docField
+	Members     []string
+	Relations   []docRelation
+}
+
+type docField struct {
+	Name       string
+	Type       string
+	Directives string
+}
+
+type docRelation struct {
+	Field  string
+	Target string
+	Anchor string
+	Name   string
+	List   bool
+}
+
+func newDocTypes(defs []store.Definition) []docType {
+	relations := map[string][]docRelation{}
+	for _, r := range Relations(defs) {
+		relations[r.Definition.Name] = append(relations[r.Definition.Name], docRelation{
+			Field:  r.Field.Name,
+			Target: r.Target,
+			Anchor: strings.ToLower(r.Target),
+			Name:   r.Name,
+			List:   r.List,
+		})
+	}
+
+	types := make([]docType, 0, len(defs))
+	for _, def := range defs {
+		t := docType{
+			Name:        def.Name,
+			Anchor:      strings.ToLower(def.Name),
+			Keyword:     def.Keyword(),
+			Origin:      def.Origin,
+			Description: def.Description,
+			Members:     def.Types,
+			Relations:   relations[def.Name],
+		}
+		for _, f := range def.Fields {
+			field := docField{Name: f.Name, Type: f.Type, Directives: directiveList(f.Directives)}
+			if def.Kind == ast.Enum {
+				t.Values = append(t.Values, field)
+			} else {
+				t.Fields = append(t.Fields, field)
+			}
+		}
+		types = append(types, t)
+	}
+	return types
+}
+
+func directiveList(list []store.Directive) string {
+	var out []string
+	for _, d := range list {
+		if len(d.Arguments) == 0 {
+			out = append(out, "@"+d.Name)
+			continue
+		}
+		var args []string
+		for _, a := range d.Arguments {
+			args = append(args, a.Name+": "+a.Value)
+		}
+		out = append(out, "@"+d.Name+"("+strings.Join(args, ", ")+")")
+	}
+	return strings.Join(out, " ")
+}
+
+// RenderDocs renders every definition with its fields, directives and relations, as
+// "markdown" or "html".
+func RenderDocs(defs []store.Definition, format string) (string, error) {
+	types := newDocTypes(defs)
+	switch format {
+	case "markdown", "md":
+		return renderMarkdown(types), nil
+	case "html":
+		var buf bytes.Buffer
+		if err := docsTemplate.Execute(&buf, types); err != nil {
+			return "", fmt.Errorf("failed to render docs: %w", err)
+		}
+		return buf.String(), nil
+	}
+	return "", fmt.Errorf("unknown docs format %q (expected markdown or html)", format)
+}
+
+func renderMarkdown(types []docType) string {
+	var b strings.Builder
+
+	b.WriteString("# Schema\n\n")
+	for _, t := range types {
+		fmt.Fprintf(&b, "- [%s](#%s) (%s, %s)\n", t.Name, t.Anchor, t.Keyword, t.Origin)
+	}
+
+	for _, t := range types {
+		fmt.Fprintf(&b, "\n## %s\n\n`%s` from the %s schema\n", t.Name, t.Keyword, t.Origin)
+		if t.Description != "" {
+			fmt.Fprintf(&b, "\n%s\n", t.Description)
+		}
+
+		if len(t.Fields) > 0 {
+			b.WriteString("\n| Field | Type | Directives |\n| --- | --- | --- |\n")
+			for _, f := range t.Fields {
+				fmt.Fprintf(&b, "| %s | `%s` | %s |\n", f.Name, f.Type, code(f.Directives))
+			}
+		}
+		if len(t.Values) > 0 {
+			b.WriteString("\n| Value | Directives |\n| --- | --- |\n")
+			for _, v := range t.Values {
+				fmt.Fprintf(&b, "| %s | %s |\n", v.Name, code(v.Directives))
+			}
+		}
+		if len(t.Members) > 0 {
+			fmt.Fprintf(&b, "\nMembers: %s\n", strings.Join(t.Members, ", "))
+		}
+		if len(t.Relations) > 0 {
+			b.WriteString("\nRelations:\n\n")
+			for _, r := range t.Relations {
+				fmt.Fprintf(&b, "- `%s` → %s[%s](#%s)", r.Field, many(r.List), r.Target, r.Anchor)
+				if r.Name != "" {
+					fmt.Fprintf(&b, " via `%s`", r.Name)
+				}
+				b.WriteString("\n")
+			}
+		}
+	}
+
+	return b.String()
+}
+
+func code(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + s + "`"
+}
+
+func many(list bool) string {
+	if list {
+		return "many "
+	}
+	return ""
+}
+
+var docsTemplate = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Schema</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: 2em auto; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.2em 0.6em; text-align: left; }
+</style>
+</head>
+<body>
+<h1>Schema</h1>
+<ul>
+{{- range .}}
+<li><a href="#{{.Anchor}}">{{.Name}}</a> ({{.Keyword}}, {{.Origin}})</li>
+{{- end}}
+</ul>
+{{- range .}}
+<h2 id="{{.Anchor}}">{{.Name}}</h2>
+<p><code>{{.Keyword}}</code> from the {{.Origin}} schema</p>
+{{- if .Description}}
+<p>{{.Description}}</p>
+{{- end}}
+{{- if .Fields}}
+<table>
+<tr><th>Field</th><th>Type</th><th>Directives</th></tr>
+{{- range .Fields}}
+<tr><td>{{.Name}}</td><td><code>{{.Type}}</code></td><td>{{if .Directives}}<code>{{.Directives}}</code>{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Values}}
+<table>
+<tr><th>Value</th><th>Directives</th></tr>
+{{- range .Values}}
+<tr><td>{{.Name}}</td><td>{{if .Directives}}<code>{{.Directives}}</code>{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Members}}
+<p>Members: {{range $i, $m := .Members}}{{if $i}}, {{end}}{{$m}}{{end}}</p>
+{{- end}}
+{{- if .Relations}}
+<p>Relations:</p>
+<ul>
+{{- range .Relations}}
+<li><code>{{.Field}}</code> → {{if .List}}many {{end}}<a href="#{{.Anchor}}">{{.Target}}</a>{{if .Name}} via <code>{{.Name}}</code>{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
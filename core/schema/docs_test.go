@@ -0,0 +1,63 @@
+package schema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/core/schema"
+	"github.com/shinzonetwork/view-creator/core/schema/store"
+)
+
+func TestRenderDocs(t *testing.T) {
+	defs, err := store.ParseDefinitions("schema.graphql", `"A liquidity pool"
+type Pool {
+  id: String @index(unique: true)
+  tokens: [Token] @relation(name: "pool_tokens")
+}
+
+type Token {
+  pools: [Pool] @relation(name: "pool_tokens")
+}
+
+enum Side { BUY SELL }
+
+union Asset = Pool | Token`, store.OriginCustom)
+	if err != nil {
+		t.Fatalf("failed to parse schema: %v", err)
+	}
+
+	markdown, err := schema.RenderDocs(defs, "markdown")
+	if err != nil {
+		t.Fatalf("RenderDocs failed: %v", err)
+	}
+	for _, want := range []string{
+		"- [Pool](#pool) (type, custom)\n",
+		"## Pool\n\n`type` from the custom schema\n\nA liquidity pool\n",
+		"| id | `String` | `@index(unique: true)` |\n",
+		"- `tokens` → many [Token](#token) via `pool_tokens`\n",
+		"| Value | Directives |\n| --- | --- |\n| BUY |  |\n",
+		"Members: Pool, Token\n",
+	} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("expected markdown to contain %q:\n%s", want, markdown)
+		}
+	}
+
+	html, err := schema.RenderDocs(defs, "html")
+	if err != nil {
+		t.Fatalf("RenderDocs failed: %v", err)
+	}
+	for _, want := range []string{
+		`<h2 id="pool">Pool</h2>`,
+		`<tr><td>id</td><td><code>String</code></td><td><code>@index(unique: true)</code></td></tr>`,
+		`<li><code>tokens</code> → many <a href="#token">Token</a> via <code>pool_tokens</code></li>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected html to contain %q:\n%s", want, html)
+		}
+	}
+
+	if _, err := schema.RenderDocs(defs, "pdf"); err == nil {
+		t.Error("expected an unknown format to be rejected")
+	}
+}
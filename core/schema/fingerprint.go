@@ -0,0 +1,115 @@
+package schema
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	"github.com/shinzonetwork/view-creator/core/schema/store"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+)
+
+// Fingerprint hashes the given schema source. Comments, whitespace and the order of
+// definitions do not affect the result.
+func Fingerprint(schemaSource string) (string, error) {
+	doc, err := store.ParseDocument("schema.graphql", schemaSource)
+	if err != nil {
+		return "", err
+	}
+
+	var blocks []string
+	format := func(d *ast.SchemaDocument) {
+		blocks = append(blocks, canonicalBlock(d))
+	}
+	for _, def := range doc.Directives {
+		format(&ast.SchemaDocument{Directives: ast.DirectiveDefinitionList{def}})
+	}
+	for _, def := range doc.Definitions {
+		format(&ast.SchemaDocument{Definitions: ast.DefinitionList{def}})
+	}
+	for _, def := range doc.Extensions {
+		format(&ast.SchemaDocument{Extensions: ast.DefinitionList{def}})
+	}
+	sort.Strings(blocks)
+
+	sum := sha256.Sum256([]byte(strings.Join(blocks, "\n\n")))
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// canonicalBlock formats d without comments, the form Fingerprint hashes.
+func canonicalBlock(d *ast.SchemaDocument) string {
+	var buf bytes.Buffer
+	formatter.NewFormatter(&buf, formatter.WithIndent("  ")).FormatSchemaDocument(d)
+	return strings.TrimSpace(buf.String())
+}
+
+func canonicalDefinition(def *ast.Definition) string {
+	return canonicalBlock(&ast.SchemaDocument{Definitions: ast.DefinitionList{def}})
+}
+
+// Snapshot returns the SDL of the named definitions, in the order given. Names missing
+// from the schema are skipped.
+func Snapshot(schemaSource string, names []string) (string, error) {
+	doc, err := store.ParseDocument("schema.graphql", schemaSource)
+	if err != nil {
+		return "", err
+	}
+
+	var blocks []string
+	for _, name := range names {
+		if def := doc.Definitions.ForName(name); def != nil {
+			blocks = append(blocks, store.FormatDefinition(def))
+		}
+	}
+	return strings.Join(blocks, "\n\n"), nil
+}
+
+// SnapshotDrift compares a snapshot with the current schema source and returns the
+// definitions that changed or were removed since the snapshot was taken. Like Fingerprint,
+// it ignores comments and whitespace.
+func SnapshotDrift(snapshot string, schemaSource string) (changed []string, removed []string, err error) {
+	old, err := store.ParseDocument("snapshot.graphql", snapshot)
+	if err != nil {
+		return nil, nil, err
+	}
+	current, err := store.ParseDocument("schema.graphql", schemaSource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, def := range old.Definitions {
+		now := current.Definitions.ForName(def.Name)
+		switch {
+		case now == nil:
+			removed = append(removed, def.Name)
+		case canonicalDefinition(now) != canonicalDefinition(def):
+			changed = append(changed, def.Name)
+		}
+	}
+	return changed, removed, nil
+}
+
+// UsedTypes returns the types a query selects, in order of first use.
+func UsedTypes(schemaSource string, rawQuery string) ([]string, error) {
+	refs, err := QueryRefs(schemaSource, rawQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var names []string
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, ref := range refs {
+		add(ref.Type)
+		add(strings.Trim(ref.FieldType, "[]!"))
+	}
+	return names, nil
+}
@@ -0,0 +1,65 @@
+package schema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/core/schema"
+)
+
+func TestSnapshotDriftIgnoresComments(t *testing.T) {
+	source := `type Block {
+  hash: String
+  number: Int
+}
+
+type Log {
+  address: String
+}`
+
+	snapshot, err := schema.Snapshot(source, []string{"Block", "Log"})
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	commented := `# blocks as indexed
+type Block {
+  # the block hash
+  hash: String
+  number: Int
+}
+
+type Log {
+  address: String
+}`
+
+	before, err := schema.Fingerprint(source)
+	if err != nil {
+		t.Fatalf("Fingerprint failed: %v", err)
+	}
+	after, err := schema.Fingerprint(commented)
+	if err != nil {
+		t.Fatalf("Fingerprint failed: %v", err)
+	}
+	if before != after {
+		t.Fatalf("expected comments not to change the fingerprint")
+	}
+
+	changed, removed, err := schema.SnapshotDrift(snapshot, commented)
+	if err != nil {
+		t.Fatalf("SnapshotDrift failed: %v", err)
+	}
+	if len(changed) != 0 || len(removed) != 0 {
+		t.Errorf("expected comments not to count as drift, got changed %v, removed %v", changed, removed)
+	}
+
+	edited := strings.Replace(commented, "number: Int", "number: String", 1)
+	edited = strings.Replace(edited, "type Log {\n  address: String\n}", "", 1)
+	changed, removed, err = schema.SnapshotDrift(snapshot, edited)
+	if err != nil {
+		t.Fatalf("SnapshotDrift failed: %v", err)
+	}
+	if strings.Join(changed, ",") != "Block" || strings.Join(removed, ",") != "Log" {
+		t.Errorf("expected Block changed and Log removed, got changed %v, removed %v", changed, removed)
+	}
+}
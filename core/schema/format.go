@@ -0,0 +1,112 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/shinzonetwork/view-creator/core/schema/store"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+	"github.com/vektah/gqlparser/v2/lexer"
+)
+
+// trailingMarker is a placeholder field that holds comments written after the last
+// selection of a set, which the parser would otherwise drop.
+const trailingMarker = "__viewkitTrailingComment"
+
+// FormatQuery pretty-prints a view query, keeping its comments. Bare root selections stay
+// bare; full documents are printed whole.
+func FormatQuery(rawQuery string) (string, error) {
+	if _, err := ParseQuery(rawQuery); err != nil {
+		return "", err
+	}
+	marked, tail := markTrailingComments(rawQuery)
+	doc, err := ParseQuery(marked)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	formatter.NewFormatter(&buf, formatter.WithComments(), formatter.WithIndent("  ")).FormatQueryDocument(doc)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	if !isDocument(rawQuery) {
+		// drop the `query {` wrapper ParseQuery added
+		lines = lines[1 : len(lines)-1]
+		for i, line := range lines {
+			lines[i] = strings.TrimPrefix(line, "  ")
+		}
+	}
+
+	kept := lines[:0]
+	for _, line := range lines {
+		if strings.TrimSpace(line) != trailingMarker {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(append(kept, tail...), "\n"), nil
+}
+
+// markTrailingComments puts trailingMarker before every closing brace of a selection set
+// that follows a comment, so the comment stays in place. Comments at the end of the query
+// have nothing to attach to and are returned separately.
+func markTrailingComments(rawQuery string) (string, []string) {
+	runes := []rune(rawQuery)
+	lex := lexer.New(&ast.Source{Input: rawQuery})
+
+	var marks []int
+	var pending []string
+	parens := 0
+	for {
+		tok, err := lex.ReadToken()
+		if err != nil {
+			return rawQuery, nil
+		}
+		switch tok.Kind {
+		case lexer.Comment:
+			pending = append(pending, tok.Value)
+			continue
+		case lexer.ParenL:
+			parens++
+		case lexer.ParenR:
+			parens--
+		case lexer.BraceR:
+			// braces inside arguments close input objects, not selection sets
+			if len(pending) > 0 && parens == 0 {
+				marks = append(marks, tok.Pos.Start)
+			}
+		case lexer.EOF:
+			for i := len(marks) - 1; i >= 0; i-- {
+				at := marks[i]
+				runes = append(runes[:at], append([]rune(trailingMarker+"\n"), runes[at:]...)...)
+			}
+			return string(runes), pending
+		}
+		pending = nil
+	}
+}
+
+// FormatSDL pretty-prints a view SDL, keeping its comments.
+func FormatSDL(sdl string) (string, error) {
+	doc, err := store.ParseDocument("view.graphql", sdl)
+	if err != nil {
+		return "", fmt.Errorf("SDL parse error: %w", err)
+	}
+	return strings.TrimSpace(store.FormatDocument(doc)), nil
+}
+
+// CanonicalSDL formats a view SDL without its comments, keeping the order of its types, so
+// that only changes DefraDB sees change the result.
+func CanonicalSDL(sdl string) (string, error) {
+	doc, err := store.ParseDocument("view.graphql", sdl)
+	if err != nil {
+		return "", fmt.Errorf("SDL parse error: %w", err)
+	}
+
+	blocks := make([]string, 0, len(doc.Definitions))
+	for _, def := range doc.Definitions {
+		blocks = append(blocks, canonicalDefinition(def))
+	}
+	return strings.Join(blocks, "\n\n"), nil
+}
@@ -0,0 +1,76 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/core/schema"
+)
+
+func TestFormatQuery(t *testing.T) {
+	got, err := schema.FormatQuery(`Log(filter: {address: {_eq: "0x1"}}) {address   # emitter
+  block {number}} Event { eventName }`)
+	if err != nil {
+		t.Fatalf("FormatQuery failed: %v", err)
+	}
+	want := `Log(filter: {address:{_eq:"0x1"}}) {
+  address
+  # emitter
+  block {
+    number
+  }
+}
+Event {
+  eventName
+}`
+	if got != want {
+		t.Errorf("unexpected query:\n%s\nwant:\n%s", got, want)
+	}
+
+	again, err := schema.FormatQuery(got)
+	if err != nil || again != got {
+		t.Errorf("expected formatting to be stable, got:\n%s (err: %v)", again, err)
+	}
+
+	got, err = schema.FormatQuery(`query ($a: Int = 1) { Log(limit: $a) { address } }`)
+	if err != nil {
+		t.Fatalf("FormatQuery failed: %v", err)
+	}
+	if got != "query ($a: Int = 1) {\n  Log(limit: $a) {\n    address\n  }\n}" {
+		t.Errorf("unexpected document:\n%s", got)
+	}
+
+	got, err = schema.FormatQuery("# all logs\n, query { Log { address } }")
+	if err != nil || got != "# all logs\nquery {\n  Log {\n    address\n  }\n}" {
+		t.Errorf("expected a commented document to be printed whole, got:\n%s (err: %v)", got, err)
+	}
+	got, err = schema.FormatQuery("Log { address } # note")
+	if err != nil || got != "Log {\n  address\n}\n# note" {
+		t.Errorf("expected a trailing comment to be kept, got:\n%s (err: %v)", got, err)
+	}
+
+	got, err = schema.FormatQuery("Log(filter: {address: {_eq: \"0x1\" # emitter\n}}) {\n  address\n  # more fields later\n}")
+	want = "Log(filter: {address:{_eq:\"0x1\"}}) {\n  address\n  # more fields later\n}"
+	if err != nil || got != want {
+		t.Errorf("expected the comment after the last selection to be kept, got:\n%s (err: %v)", got, err)
+	}
+	again, err = schema.FormatQuery(got)
+	if err != nil || again != got {
+		t.Errorf("expected formatting a trailing comment to be stable, got:\n%s (err: %v)", again, err)
+	}
+
+	if _, err := schema.FormatQuery("Log {"); err == nil {
+		t.Error("expected an invalid query to fail")
+	}
+}
+
+func TestFormatSDL(t *testing.T) {
+	got, err := schema.FormatSDL(`# decoded
+type FilteredLogs @materialized(if: false) {transactionHash: String   blockNumber: Int}`)
+	if err != nil {
+		t.Fatalf("FormatSDL failed: %v", err)
+	}
+	want := "# decoded\ntype FilteredLogs @materialized(if: false) {\n  transactionHash: String\n  blockNumber: Int\n}"
+	if got != want {
+		t.Errorf("unexpected SDL:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,109 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shinzonetwork/view-creator/core/schema/store"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// RelationEdge joins the two sides of a relation. To is nil when the relation has only
+// one side.
+type RelationEdge struct {
+	From RelationField
+	To   *RelationField
+}
+
+// Label names the edge after its @relation name, or after the field without one.
+func (e RelationEdge) Label() string {
+	if e.From.Name != "" {
+		return e.From.Name
+	}
+	return e.From.Field.Name
+}
+
+// RelationGraph pairs relation fields into edges. A one-to-many edge starts at the type
+// holding the list.
+func RelationGraph(defs []store.Definition) []RelationEdge {
+	relations := Relations(defs)
+	paired := make([]bool, len(relations))
+
+	var edges []RelationEdge
+	for i, r := range relations {
+		if paired[i] {
+			continue
+		}
+		edge := RelationEdge{From: r}
+		for j := i + 1; j < len(relations); j++ {
+			if !paired[j] && r.Pairs(relations[j]) {
+				paired[j] = true
+				other := relations[j]
+				edge.To = &other
+				break
+			}
+		}
+		if edge.To != nil && !edge.From.List && edge.To.List {
+			from := *edge.To
+			to := edge.From
+			edge = RelationEdge{From: from, To: &to}
+		}
+		edges = append(edges, edge)
+	}
+	return edges
+}
+
+// RenderGraph renders the relation graph as "dot" (Graphviz) or "mermaid".
+func RenderGraph(defs []store.Definition, format string) (string, error) {
+	edges := RelationGraph(defs)
+	switch format {
+	case "dot":
+		return renderDot(defs, edges), nil
+	case "mermaid":
+		return renderMermaid(edges), nil
+	}
+	return "", fmt.Errorf("unknown graph format %q (expected dot or mermaid)", format)
+}
+
+func renderDot(defs []store.Definition, edges []RelationEdge) string {
+	var b strings.Builder
+	b.WriteString("digraph schema {\n  rankdir=LR;\n  node [shape=box];\n")
+	for _, def := range defs {
+		if def.Kind == ast.Object || def.Kind == ast.Interface {
+			fmt.Fprintf(&b, "  %q;\n", def.Name)
+		}
+	}
+	for _, e := range edges {
+		head, tail := "normal", "none"
+		if e.From.List {
+			head = "crow"
+		}
+		if e.To != nil && e.To.List {
+			tail = "crow"
+		}
+		fmt.Fprintf(&b, "  %q -> %q [label=%q, dir=both, arrowhead=%s, arrowtail=%s];\n", e.From.Definition.Name, e.From.Target, e.Label(), head, tail)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func renderMermaid(edges []RelationEdge) string {
+	var b strings.Builder
+	b.WriteString("erDiagram\n")
+	for _, e := range edges {
+		// an edge without a counterpart field is read as belonging to a single parent
+		left := "}o"
+		if e.To != nil {
+			left = "||"
+			if e.To.List {
+				left = "}o"
+			}
+		}
+		right := "||"
+		if e.From.List {
+			right = "o{"
+		}
+		fmt.Fprintf(&b, "  %s %s--%s %s : %q\n", e.From.Definition.Name, left, right, e.From.Target, e.Label())
+	}
+	return b.String()
+}
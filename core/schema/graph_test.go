@@ -0,0 +1,63 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/core/schema"
+	"github.com/shinzonetwork/view-creator/core/schema/store"
+)
+
+func TestRenderGraph(t *testing.T) {
+	defs, err := store.ParseDefinitions("schema.graphql", `type Pool {
+  owner: Owner @relation(name: "owner_pools")
+  tokens: [Token] @relation(name: "pool_tokens")
+  creator: Owner
+}
+
+type Owner {
+  pools: [Pool] @relation(name: "owner_pools")
+}
+
+type Token {
+  pools: [Pool] @relation(name: "pool_tokens")
+}`, store.OriginCustom)
+	if err != nil {
+		t.Fatalf("failed to parse schema: %v", err)
+	}
+
+	mermaid, err := schema.RenderGraph(defs, "mermaid")
+	if err != nil {
+		t.Fatalf("RenderGraph failed: %v", err)
+	}
+	want := `erDiagram
+  Owner ||--o{ Pool : "owner_pools"
+  Pool }o--o{ Token : "pool_tokens"
+  Pool }o--|| Owner : "creator"
+`
+	if mermaid != want {
+		t.Errorf("unexpected mermaid graph:\n%s\nwant:\n%s", mermaid, want)
+	}
+
+	dot, err := schema.RenderGraph(defs, "dot")
+	if err != nil {
+		t.Fatalf("RenderGraph failed: %v", err)
+	}
+	want = `digraph schema {
+  rankdir=LR;
+  node [shape=box];
+  "Pool";
+  "Owner";
+  "Token";
+  "Owner" -> "Pool" [label="owner_pools", dir=both, arrowhead=crow, arrowtail=none];
+  "Pool" -> "Token" [label="pool_tokens", dir=both, arrowhead=crow, arrowtail=crow];
+  "Pool" -> "Owner" [label="creator", dir=both, arrowhead=normal, arrowtail=none];
+}
+`
+	if dot != want {
+		t.Errorf("unexpected dot graph:\n%s\nwant:\n%s", dot, want)
+	}
+
+	if _, err := schema.RenderGraph(defs, "svg"); err == nil {
+		t.Error("expected an unknown format to be rejected")
+	}
+}
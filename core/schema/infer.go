@@ -0,0 +1,229 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/shinzonetwork/view-creator/core/schema/store"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// ErrSeveralRoots is returned when fields are derived from a query that selects more than
+// one root collection.
+var ErrSeveralRoots = errors.New("can only derive fields from a query with one root selection")
+
+type InferOptions struct {
+	// TypeName names the inferred type.
+	TypeName string
+	// Materialized sets @materialized(if: true) on the type.
+	Materialized bool
+	// Rename maps a selection path such as "transaction.hash" to a field name. Other
+	// nested fields are flattened into camelCase names such as transactionHash.
+	Rename map[string]string
+}
+
+var builtinScalarNames = map[string]bool{"Int": true, "Float": true, "String": true, "Boolean": true, "ID": true}
+
+var nameWords = regexp.MustCompile(`[A-Za-z0-9]+`)
+
+// TypeNameFor turns a view name such as "decoded-logs" into a type name such as "DecodedLogs".
+func TypeNameFor(viewName string) string {
+	var b strings.Builder
+	for _, word := range nameWords.FindAllString(viewName, -1) {
+		b.WriteString(upperFirst(word))
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "View" + name
+	}
+	return name
+}
+
+// OutputField is one field of the documents a query or lens produces, named as the view's
+// SDL is expected to name it.
+type OutputField struct {
+	Path string // selection path, such as "transaction.hash"
+	Name string
+	Type string // named type, wrapped in brackets for lists
+}
+
+// QueryOutput lists the fields of the documents a query produces, using their types in the
+// schema. Nested relation fields are flattened into camelCase names, and fields under a
+// list become lists.
+func QueryOutput(schemaSource string, rawQuery string) ([]OutputField, error) {
+	normalized, err := NormalizeQuery(rawQuery)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := ParseQuery(normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	fullSchema, err := buildSchemaWithRoot(schemaSource)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	schemaAST, err := gqlparser.LoadSchema(&ast.Source{Name: "combined.graphql", Input: fullSchema})
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	var roots []*ast.Field
+	for _, sel := range doc.Operations[0].SelectionSet {
+		if f, ok := sel.(*ast.Field); ok {
+			roots = append(roots, f)
+		}
+	}
+	if len(roots) != 1 {
+		return nil, fmt.Errorf("%w, found %d", ErrSeveralRoots, len(roots))
+	}
+
+	root := schemaAST.Query.Fields.ForName(roots[0].Name)
+	if root == nil {
+		return nil, fmt.Errorf("unknown root selection %s", roots[0].Name)
+	}
+
+	in := &inference{schema: schemaAST, doc: doc}
+	if err := in.walk(schemaAST.Types[root.Type.Name()], roots[0].SelectionSet, nil, false); err != nil {
+		return nil, err
+	}
+	if len(in.fields) == 0 {
+		return nil, fmt.Errorf("the query selects no fields")
+	}
+	return in.fields, nil
+}
+
+// InferSDL derives an SDL type from the fields a query selects, as listed by QueryOutput.
+func InferSDL(schemaSource string, rawQuery string, opts InferOptions) (string, error) {
+	fields, err := QueryOutput(schemaSource, rawQuery)
+	if err != nil {
+		return "", err
+	}
+
+	used := map[string]bool{}
+	names := map[string]string{} // field name to the path that produced it
+	var extra []string
+	for i, f := range fields {
+		if name, ok := opts.Rename[f.Path]; ok {
+			used[f.Path] = true
+			fields[i].Name = name
+		}
+		if other, taken := names[fields[i].Name]; taken {
+			return "", fmt.Errorf("fields %s and %s both map to %s; rename one of them", other, f.Path, fields[i].Name)
+		}
+		names[fields[i].Name] = f.Path
+
+		named := strings.Trim(f.Type, "[]")
+		if !builtinScalarNames[named] && !contains(extra, named) {
+			extra = append(extra, named)
+		}
+	}
+	for path := range opts.Rename {
+		if !used[path] {
+			return "", fmt.Errorf("cannot rename %s: the query selects no such field", path)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "type %s @materialized(if: %t) {\n", opts.TypeName, opts.Materialized)
+	for _, f := range fields {
+		fmt.Fprintf(&b, "  %s: %s\n", f.Name, f.Type)
+	}
+	b.WriteString("}")
+
+	// enums and custom scalars travel with the type, since the view SDL stands alone
+	if len(extra) > 0 {
+		source, err := store.ParseDocument("schema.graphql", schemaSource)
+		if err != nil {
+			return "", err
+		}
+		for _, name := range extra {
+			if def := source.Definitions.ForName(name); def != nil {
+				b.WriteString("\n\n" + store.FormatDefinition(def))
+			}
+		}
+	}
+
+	return b.String(), nil
+}
+
+type inference struct {
+	schema *ast.Schema
+	doc    *ast.QueryDocument
+	fields []OutputField
+}
+
+func (in *inference) walk(def *ast.Definition, set ast.SelectionSet, path []string, list bool) error {
+	for _, sel := range set {
+		switch s := sel.(type) {
+		case *ast.Field:
+			if err := in.field(def, s, path, list); err != nil {
+				return err
+			}
+		case *ast.InlineFragment:
+			if err := in.walk(def, s.SelectionSet, path, list); err != nil {
+				return err
+			}
+		case *ast.FragmentSpread:
+			if frag := in.doc.Fragments.ForName(s.Name); frag != nil {
+				if err := in.walk(def, frag.SelectionSet, path, list); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (in *inference) field(def *ast.Definition, f *ast.Field, path []string, list bool) error {
+	fd := def.Fields.ForName(f.Name)
+	if fd == nil {
+		return fmt.Errorf("%s has no field %s", def.Name, f.Name)
+	}
+
+	key := f.Alias
+	if key == "" {
+		key = f.Name
+	}
+	fieldPath := append(append([]string{}, path...), key)
+	list = list || fd.Type.Elem != nil
+	named := in.schema.Types[fd.Type.Name()]
+
+	if len(f.SelectionSet) > 0 {
+		return in.walk(named, f.SelectionSet, fieldPath, list)
+	}
+
+	typ := named.Name
+	if list {
+		typ = "[" + typ + "]"
+	}
+	in.fields = append(in.fields, OutputField{Path: strings.Join(fieldPath, "."), Name: camelName(fieldPath), Type: typ})
+	return nil
+}
+
+// camelName joins a selection path into one field name, dropping the leading underscores
+// of generated fields: transaction.block.number becomes transactionBlockNumber.
+func camelName(path []string) string {
+	var b strings.Builder
+	for i, part := range path {
+		part = strings.TrimLeft(part, "_")
+		if i == 0 {
+			b.WriteString(part)
+		} else {
+			b.WriteString(upperFirst(part))
+		}
+	}
+	return b.String()
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
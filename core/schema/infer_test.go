@@ -0,0 +1,64 @@
+package schema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/core/schema"
+	"github.com/shinzonetwork/view-creator/tools"
+)
+
+func TestInferSDL(t *testing.T) {
+	source := tools.DefaultSchema + "\n\nenum Side { BUY SELL }\n\ntype Trade { side: Side! log: Log }"
+
+	got, err := schema.InferSDL(source, `Log {
+  address
+  topics
+  txHash: transactionHash
+  transaction { hash block { number } }
+  events { eventName }
+  _docID
+}`, schema.InferOptions{TypeName: "FilteredLogs", Rename: map[string]string{"transaction.block.number": "blockNo"}})
+	if err != nil {
+		t.Fatalf("InferSDL failed: %v", err)
+	}
+	want := `type FilteredLogs @materialized(if: false) {
+  address: String
+  topics: [String]
+  txHash: String
+  transactionHash: String
+  blockNo: Int
+  eventsEventName: [String]
+  docID: ID
+}`
+	if got != want {
+		t.Errorf("unexpected SDL:\n%s\nwant:\n%s", got, want)
+	}
+
+	got, err = schema.InferSDL(source, `Trade { side log { address } }`, schema.InferOptions{TypeName: "Trades", Materialized: true})
+	if err != nil {
+		t.Fatalf("InferSDL failed: %v", err)
+	}
+	want = "type Trades @materialized(if: true) {\n  side: Side\n  logAddress: String\n}\n\nenum Side {\n  BUY\n  SELL\n}"
+	if got != want {
+		t.Errorf("unexpected SDL:\n%s\nwant:\n%s", got, want)
+	}
+
+	for query, wantErr := range map[string]string{
+		`Log { address } Block { hash }`:            "one root selection",
+		`Log { hash: address transactionHash { } }`: "parse error",
+		`Log { block { hash } blockHash }`:          "both map to blockHash",
+	} {
+		if _, err := schema.InferSDL(source, query, schema.InferOptions{TypeName: "X"}); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("expected %q to fail with %q, got %v", query, wantErr, err)
+		}
+	}
+
+	if _, err := schema.InferSDL(source, `Log { address }`, schema.InferOptions{TypeName: "X", Rename: map[string]string{"nope": "n"}}); err == nil {
+		t.Error("expected an unused rename to be rejected")
+	}
+
+	if name := schema.TypeNameFor("decoded-logs_v2"); name != "DecodedLogsV2" {
+		t.Errorf("unexpected type name %s", name)
+	}
+}
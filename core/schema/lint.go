@@ -0,0 +1,152 @@
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/shinzonetwork/view-creator/core/schema/store"
+)
+
+// Severity decides whether a lint rule fails the run, only warns, or is skipped.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityOff     Severity = "off"
+)
+
+const (
+	RuleRelationName    = "relation-name"
+	RuleRelationPair    = "relation-pair"
+	RuleForeignKeyIndex = "foreign-key-index"
+	RuleTypeNameCase    = "type-name-case"
+	RuleShadowedType    = "shadowed-type"
+)
+
+// DefaultLintRules returns the severity of every lint rule.
+func DefaultLintRules() map[string]Severity {
+	return map[string]Severity{
+		RuleRelationName:    SeverityError,
+		RuleRelationPair:    SeverityError,
+		RuleForeignKeyIndex: SeverityWarning,
+		RuleTypeNameCase:    SeverityWarning,
+		RuleShadowedType:    SeverityError,
+	}
+}
+
+// Finding is one lint problem at a position in a schema file.
+type Finding struct {
+	Rule     string
+	Severity Severity
+	Origin   store.Origin
+	Position store.Position
+	Message  string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s (%s)", f.Position, f.Severity, f.Message, f.Rule)
+}
+
+var pascalCase = regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`)
+
+// Lint checks schema definitions against the given rules. Rules missing from the map use
+// their default severity.
+func Lint(defs []store.Definition, rules map[string]Severity) ([]Finding, error) {
+	severities := DefaultLintRules()
+	for rule, severity := range rules {
+		if _, ok := severities[rule]; !ok {
+			return nil, fmt.Errorf("unknown lint rule %q", rule)
+		}
+		switch severity {
+		case SeverityError, SeverityWarning, SeverityOff:
+		default:
+			return nil, fmt.Errorf("invalid severity %q for rule %s", severity, rule)
+		}
+		severities[rule] = severity
+	}
+
+	var findings []Finding
+	report := func(rule string, def store.Definition, pos store.Position, format string, args ...any) {
+		if severities[rule] == SeverityOff {
+			return
+		}
+		findings = append(findings, Finding{Rule: rule, Severity: severities[rule], Origin: def.Origin, Position: pos, Message: fmt.Sprintf(format, args...)})
+	}
+
+	defaults := map[string]bool{}
+	for _, def := range defs {
+		if def.Origin == store.OriginDefault {
+			defaults[def.Name] = true
+		}
+	}
+
+	for _, def := range defs {
+		if def.Origin == store.OriginCustom && defaults[def.Name] {
+			report(RuleShadowedType, def, def.Position, "%s %s shadows a type of the default schema", def.Keyword(), def.Name)
+		}
+		if !pascalCase.MatchString(def.Name) {
+			report(RuleTypeNameCase, def, def.Position, "%s name %s is not PascalCase", def.Keyword(), def.Name)
+		}
+	}
+
+	named := map[string][]RelationField{}
+	for _, r := range Relations(defs) {
+		if r.Name == "" {
+			report(RuleRelationName, r.Definition, r.Field.Position, "relation field %s.%s has no @relation name", r.Definition.Name, r.Field.Name)
+		} else {
+			named[r.Name] = append(named[r.Name], r)
+		}
+
+		// the single side of a relation holds the foreign key
+		if !r.List && !hasDirective(r.Field, "index") {
+			report(RuleForeignKeyIndex, r.Definition, r.Field.Position, "foreign key %s.%s has no @index", r.Definition.Name, r.Field.Name)
+		}
+	}
+
+	names := make([]string, 0, len(named))
+	for name := range named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fields := named[name]
+		unmatched := 0
+		for _, r := range fields {
+			matched := false
+			for _, other := range fields {
+				if r.Pairs(other) {
+					matched = true
+				}
+			}
+			if !matched {
+				unmatched++
+				report(RuleRelationPair, r.Definition, r.Field.Position, "relation %q on %s.%s has no matching field on %s", name, r.Definition.Name, r.Field.Name, r.Target)
+			}
+		}
+		// an unmatched field already explains a reused name
+		if unmatched == 0 && len(fields) > 2 {
+			var used []string
+			for _, r := range fields {
+				used = append(used, r.Definition.Name+"."+r.Field.Name)
+			}
+			report(RuleRelationPair, fields[2].Definition, fields[2].Field.Position, "relation %q is used by %d fields (%s), expected 2", name, len(fields), strings.Join(used, ", "))
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i].Position, findings[j].Position
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+
+	return findings, nil
+}
@@ -0,0 +1,83 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/core/schema"
+	"github.com/shinzonetwork/view-creator/core/schema/store"
+	"github.com/shinzonetwork/view-creator/tools"
+)
+
+func TestLintRules(t *testing.T) {
+	defaults, err := store.ParseDefinitions("default_schema.graphql", tools.DefaultSchema, store.OriginDefault)
+	if err != nil {
+		t.Fatalf("failed to parse default schema: %v", err)
+	}
+	customs, err := store.ParseDefinitions("custom_schema.graphql", `type Pool {
+  token: Token @relation(name: "pool_token")
+  owner: Owner
+}
+
+type Token {
+  pools: [Pool] @relation(name: "pool_token")
+}
+
+type Owner { id: String }
+
+type bad_name { id: String }
+
+type Block { hash: String }`, store.OriginCustom)
+	if err != nil {
+		t.Fatalf("failed to parse custom schema: %v", err)
+	}
+
+	findings, err := schema.Lint(append(defaults, customs...), nil)
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.String())
+	}
+	want := []string{
+		"custom_schema.graphql:2:3: warning: foreign key Pool.token has no @index (foreign-key-index)",
+		"custom_schema.graphql:3:3: error: relation field Pool.owner has no @relation name (relation-name)",
+		"custom_schema.graphql:3:3: warning: foreign key Pool.owner has no @index (foreign-key-index)",
+		"custom_schema.graphql:12:6: warning: type name bad_name is not PascalCase (type-name-case)",
+		"custom_schema.graphql:14:6: error: type Block shadows a type of the default schema (shadowed-type)",
+		"default_schema.graphql:40:5: warning: foreign key Transaction.block has no @index (foreign-key-index)",
+		"default_schema.graphql:55:5: error: relation \"block_transactions\" on Log.block has no matching field on Block (relation-pair)",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d findings, got %d:\n%v", len(want), len(got), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("finding %d:\n got  %s\n want %s", i, got[i], want[i])
+		}
+	}
+
+	findings, err = schema.Lint(customs, map[string]schema.Severity{
+		schema.RuleForeignKeyIndex: schema.SeverityOff,
+		schema.RuleTypeNameCase:    schema.SeverityError,
+	})
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	for _, f := range findings {
+		if f.Rule == schema.RuleForeignKeyIndex {
+			t.Errorf("expected disabled rule to be skipped, got %s", f)
+		}
+		if f.Rule == schema.RuleTypeNameCase && f.Severity != schema.SeverityError {
+			t.Errorf("expected overridden severity, got %s", f)
+		}
+	}
+
+	if _, err := schema.Lint(customs, map[string]schema.Severity{"no-such-rule": schema.SeverityError}); err == nil {
+		t.Error("expected an unknown rule to be rejected")
+	}
+	if _, err := schema.Lint(customs, map[string]schema.Severity{schema.RuleRelationPair: "fatal"}); err == nil {
+		t.Error("expected an invalid severity to be rejected")
+	}
+}
@@ -0,0 +1,109 @@
+package schema
+
+import (
+	"fmt"
+
+	"github.com/shinzonetwork/view-creator/core/schema/store"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// FieldMapping compares the fields of a view's SDL type with the fields its query or last
+// declaring lens produces.
+type FieldMapping struct {
+	Source     string   // what produces the documents: "query" or "lens <label>"
+	Unmapped   []string // SDL fields nothing produces
+	Mismatched []string // SDL fields produced with another type, as "name (SDL T, source U)"
+	Unused     []string // produced fields the SDL does not declare
+}
+
+// OK reports whether every SDL field is produced with its declared type.
+func (m FieldMapping) OK() bool {
+	return len(m.Unmapped) == 0 && len(m.Mismatched) == 0
+}
+
+// ParseOutput parses the output shape a lens declares, as GraphQL field definitions such
+// as "hash: String, blockNumber: Int".
+func ParseOutput(decl string) ([]OutputField, error) {
+	doc, err := store.ParseDocument("output.graphql", fmt.Sprintf("type Output {\n%s\n}", decl))
+	if err != nil {
+		return nil, fmt.Errorf("invalid output shape: %w", err)
+	}
+	if len(doc.Definitions) != 1 || len(doc.Definitions[0].Fields) == 0 {
+		return nil, fmt.Errorf("invalid output shape: expected field definitions such as \"hash: String\"")
+	}
+
+	var fields []OutputField
+	for _, f := range doc.Definitions[0].Fields {
+		fields = append(fields, OutputField{Path: f.Name, Name: f.Name, Type: outputType(f.Type)})
+	}
+	return fields, nil
+}
+
+// CompareFields matches the fields of the first type in sdl against output by name and
+// type. The first type receives the query documents; further types hold related records.
+// Non-null markers are ignored, since a produced field can always be null.
+func CompareFields(sdl string, output []OutputField, source string) (FieldMapping, error) {
+	doc, err := store.ParseDocument("sdl.graphql", sdl)
+	if err != nil {
+		return FieldMapping{}, fmt.Errorf("invalid SDL: %w", err)
+	}
+
+	var target *ast.Definition
+	for _, def := range doc.Definitions {
+		if def.Kind == ast.Object {
+			target = def
+			break
+		}
+	}
+	if target == nil {
+		return FieldMapping{}, fmt.Errorf("SDL declares no type")
+	}
+
+	produced := map[string]string{}
+	for _, f := range output {
+		produced[f.Name] = f.Type
+	}
+
+	m := FieldMapping{Source: source}
+	declared := map[string]bool{}
+	for _, f := range target.Fields {
+		declared[f.Name] = true
+		typ, ok := produced[f.Name]
+		switch {
+		case !ok:
+			m.Unmapped = append(m.Unmapped, f.Name)
+		case typ != outputType(f.Type):
+			m.Mismatched = append(m.Mismatched, fmt.Sprintf("%s (SDL %s, %s %s)", f.Name, outputType(f.Type), source, typ))
+		}
+	}
+	for _, f := range output {
+		if !declared[f.Name] {
+			m.Unused = append(m.Unused, f.Name)
+		}
+	}
+	return m, nil
+}
+
+// ViewTypes lists the object types a view SDL declares, in order. DefraDB creates one
+// collection for each of them.
+func ViewTypes(sdl string) ([]string, error) {
+	doc, err := store.ParseDocument("sdl.graphql", sdl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SDL: %w", err)
+	}
+
+	var names []string
+	for _, def := range doc.Definitions {
+		if def.Kind == ast.Object {
+			names = append(names, def.Name)
+		}
+	}
+	return names, nil
+}
+
+func outputType(t *ast.Type) string {
+	if t.Elem != nil {
+		return "[" + outputType(t.Elem) + "]"
+	}
+	return t.NamedType
+}
@@ -0,0 +1,75 @@
+package schema_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/core/schema"
+	"github.com/shinzonetwork/view-creator/tools"
+)
+
+func TestCompareFields(t *testing.T) {
+	output, err := schema.QueryOutput(tools.DefaultSchema, `Log { address transactionHash blockNumber transaction { block { number } } }`)
+	if err != nil {
+		t.Fatalf("QueryOutput failed: %v", err)
+	}
+
+	sdl := `type Logs @materialized(if: false) {
+  address: String!
+  hash: String
+  blockNumber: String
+  transactionBlockNumber: Int
+}`
+	m, err := schema.CompareFields(sdl, output, "query")
+	if err != nil {
+		t.Fatalf("CompareFields failed: %v", err)
+	}
+	want := schema.FieldMapping{
+		Source:     "query",
+		Unmapped:   []string{"hash"},
+		Mismatched: []string{"blockNumber (SDL String, query Int)"},
+		Unused:     []string{"transactionHash"},
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("unexpected mapping:\n%+v\nwant:\n%+v", m, want)
+	}
+	if m.OK() {
+		t.Error("expected the mapping to fail")
+	}
+
+	output, err = schema.ParseOutput("hash: String, block: Int, topics: [String!]")
+	if err != nil {
+		t.Fatalf("ParseOutput failed: %v", err)
+	}
+	m, err = schema.CompareFields("type Decoded { hash: String topics: [String] }", output, "lens decode")
+	if err != nil {
+		t.Fatalf("CompareFields failed: %v", err)
+	}
+	if !m.OK() || !reflect.DeepEqual(m.Unused, []string{"block"}) {
+		t.Errorf("unexpected mapping %+v", m)
+	}
+
+	if _, err := schema.ParseOutput("hash String"); err == nil {
+		t.Error("expected an invalid output shape to be rejected")
+	}
+}
+
+func TestViewTypes(t *testing.T) {
+	types, err := schema.ViewTypes(`type Transfer @materialized(if: true) {
+  amount: Int
+  from: Account @relation(name: "account_transfers")
+}
+
+enum Kind { IN OUT }
+
+type Account @materialized(if: true) {
+  address: String
+  transfers: [Transfer] @relation(name: "account_transfers")
+}`)
+	if err != nil {
+		t.Fatalf("ViewTypes failed: %v", err)
+	}
+	if !reflect.DeepEqual(types, []string{"Transfer", "Account"}) {
+		t.Errorf("unexpected types %v", types)
+	}
+}
@@ -0,0 +1,214 @@
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+var documentStart = regexp.MustCompile(`^(\{|(query|mutation|subscription|fragment)\b)`)
+
+// ParseQuery parses a view query. It is either a full GraphQL document holding one query
+// operation, with optional variables and named fragments, or only the root selections,
+// such as `Log { address }`.
+func ParseQuery(rawQuery string) (*ast.QueryDocument, error) {
+	input := strings.TrimSpace(rawQuery)
+	if !isDocument(input) {
+		// on lines of their own, so a trailing comment cannot swallow the closing brace
+		input = fmt.Sprintf("query {\n%s\n}", input)
+	}
+
+	doc, err := parser.ParseQuery(&ast.Source{Name: "query.graphql", Input: input})
+	if err != nil {
+		return nil, fmt.Errorf("query parse error: %w", err)
+	}
+
+	if len(doc.Operations) != 1 {
+		return nil, fmt.Errorf("query must hold exactly one operation, found %d", len(doc.Operations))
+	}
+	if op := doc.Operations[0]; op.Operation != ast.Query {
+		return nil, fmt.Errorf("view queries cannot be a %s", op.Operation)
+	}
+	return doc, nil
+}
+
+// isDocument reports whether a view query is a full GraphQL document rather than bare root
+// selections. Leading whitespace, commas and comments are skipped.
+func isDocument(rawQuery string) bool {
+	rest := rawQuery
+	for {
+		rest = strings.TrimLeft(rest, " \t\r\n,\ufeff")
+		if !strings.HasPrefix(rest, "#") {
+			break
+		}
+		end := strings.IndexAny(rest, "\r\n")
+		if end < 0 {
+			return false
+		}
+		rest = rest[end:]
+	}
+	return documentStart.MatchString(rest)
+}
+
+// NormalizeQuery rewrites a view query into the root selections DefraDB expects: named
+// fragments are expanded in place and variables are replaced by their default values.
+func NormalizeQuery(rawQuery string) (string, error) {
+	doc, err := ParseQuery(rawQuery)
+	if err != nil {
+		return "", err
+	}
+
+	op := doc.Operations[0]
+	vars := map[string]*ast.Value{}
+	for _, v := range op.VariableDefinitions {
+		if v.DefaultValue == nil {
+			return "", fmt.Errorf("variable $%s has no default value; views run without variables", v.Variable)
+		}
+		vars[v.Variable] = v.DefaultValue
+	}
+
+	p := &queryPrinter{doc: doc, vars: vars}
+	lines := p.selections(op.SelectionSet, "", map[string]bool{})
+	if p.err != nil {
+		return "", p.err
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+type queryPrinter struct {
+	doc  *ast.QueryDocument
+	vars map[string]*ast.Value
+	err  error
+}
+
+// selections prints a selection set, one entry per selection. Fragments without directives
+// are flattened into their parent, and selections that print identically are kept once.
+func (p *queryPrinter) selections(set ast.SelectionSet, indent string, fragments map[string]bool) []string {
+	var out []string
+	seen := map[string]bool{}
+	add := func(s string) {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+
+	for _, sel := range set {
+		switch s := sel.(type) {
+		case *ast.Field:
+			add(p.field(s, indent, fragments))
+		case *ast.InlineFragment:
+			if len(s.Directives) == 0 {
+				for _, line := range p.selections(s.SelectionSet, indent, fragments) {
+					add(line)
+				}
+				continue
+			}
+			add(p.fragment(s.TypeCondition, s.Directives, s.SelectionSet, indent, fragments))
+		case *ast.FragmentSpread:
+			frag := p.doc.Fragments.ForName(s.Name)
+			if frag == nil {
+				p.err = fmt.Errorf("unknown fragment %s", s.Name)
+				continue
+			}
+			if fragments[s.Name] {
+				p.err = fmt.Errorf("fragment %s spreads itself", s.Name)
+				continue
+			}
+			fragments[s.Name] = true
+			if len(s.Directives) == 0 {
+				for _, line := range p.selections(frag.SelectionSet, indent, fragments) {
+					add(line)
+				}
+			} else {
+				add(p.fragment(frag.TypeCondition, s.Directives, frag.SelectionSet, indent, fragments))
+			}
+			delete(fragments, s.Name)
+		}
+	}
+	return out
+}
+
+func (p *queryPrinter) field(f *ast.Field, indent string, fragments map[string]bool) string {
+	var b strings.Builder
+	b.WriteString(indent)
+	if f.Alias != "" && f.Alias != f.Name {
+		b.WriteString(f.Alias + ": ")
+	}
+	b.WriteString(f.Name)
+	if len(f.Arguments) > 0 {
+		var args []string
+		for _, a := range f.Arguments {
+			args = append(args, a.Name+": "+p.value(a.Value))
+		}
+		b.WriteString("(" + strings.Join(args, ", ") + ")")
+	}
+	b.WriteString(p.directives(f.Directives))
+	p.block(&b, f.SelectionSet, indent, fragments)
+	return b.String()
+}
+
+func (p *queryPrinter) fragment(typeCondition string, directives ast.DirectiveList, set ast.SelectionSet, indent string, fragments map[string]bool) string {
+	var b strings.Builder
+	b.WriteString(indent + "...")
+	if typeCondition != "" {
+		b.WriteString(" on " + typeCondition)
+	}
+	b.WriteString(p.directives(directives))
+	p.block(&b, set, indent, fragments)
+	return b.String()
+}
+
+func (p *queryPrinter) block(b *strings.Builder, set ast.SelectionSet, indent string, fragments map[string]bool) {
+	if len(set) == 0 {
+		return
+	}
+	b.WriteString(" {\n")
+	for _, line := range p.selections(set, indent+"  ", fragments) {
+		b.WriteString(line + "\n")
+	}
+	b.WriteString(indent + "}")
+}
+
+func (p *queryPrinter) directives(list ast.DirectiveList) string {
+	var b strings.Builder
+	for _, d := range list {
+		b.WriteString(" @" + d.Name)
+		if len(d.Arguments) > 0 {
+			var args []string
+			for _, a := range d.Arguments {
+				args = append(args, a.Name+": "+p.value(a.Value))
+			}
+			b.WriteString("(" + strings.Join(args, ", ") + ")")
+		}
+	}
+	return b.String()
+}
+
+func (p *queryPrinter) value(v *ast.Value) string {
+	switch v.Kind {
+	case ast.Variable:
+		def, ok := p.vars[v.Raw]
+		if !ok {
+			p.err = fmt.Errorf("variable $%s is not defined", v.Raw)
+			return "null"
+		}
+		return p.value(def)
+	case ast.ListValue:
+		var items []string
+		for _, child := range v.Children {
+			items = append(items, p.value(child.Value))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case ast.ObjectValue:
+		var fields []string
+		for _, child := range v.Children {
+			fields = append(fields, child.Name+": "+p.value(child.Value))
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	}
+	return v.String()
+}
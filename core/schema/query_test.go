@@ -0,0 +1,97 @@
+package schema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/core/schema"
+	"github.com/shinzonetwork/view-creator/core/schema/store/fileschema"
+)
+
+const decodedLogsQuery = `query DecodedLogs($address: String = "0x1e3a", $limit: Int = 10) {
+  Log(filter: {address: {_eq: $address}}, limit: $limit) {
+    ...LogFields
+    events { eventName }
+  }
+  Event(order: {blockNumber: DESC}) {
+    log { ...LogFields }
+  }
+}
+
+fragment LogFields on Log {
+  address
+  transactionHash
+  ... on Log { address }
+}`
+
+func TestNormalizeQuery(t *testing.T) {
+	got, err := schema.NormalizeQuery(decodedLogsQuery)
+	if err != nil {
+		t.Fatalf("NormalizeQuery failed: %v", err)
+	}
+	want := `Log(filter: {address: {_eq: "0x1e3a"}}, limit: 10) {
+  address
+  transactionHash
+  events {
+    eventName
+  }
+}
+Event(order: {blockNumber: DESC}) {
+  log {
+    address
+    transactionHash
+  }
+}`
+	if got != want {
+		t.Errorf("unexpected query:\n%s\nwant:\n%s", got, want)
+	}
+
+	// bare root selections are kept as they are
+	got, err = schema.NormalizeQuery("Log { address @include(if: true) }")
+	if err != nil || got != "Log {\n  address @include(if: true)\n}" {
+		t.Errorf("unexpected query %q (err: %v)", got, err)
+	}
+
+	// comments around the query do not change how it is read
+	got, err = schema.NormalizeQuery("# decoded logs\nquery Logs { Log { address } }")
+	if err != nil || got != "Log {\n  address\n}" {
+		t.Errorf("expected a commented document not to be wrapped again, got %q (err: %v)", got, err)
+	}
+	got, err = schema.NormalizeQuery("Log { address } # note")
+	if err != nil || got != "Log {\n  address\n}" {
+		t.Errorf("expected a trailing comment to be ignored, got %q (err: %v)", got, err)
+	}
+
+	for query, want := range map[string]string{
+		`query ($a: String) { Log(filter: {address: {_eq: $a}}) { address } }`: "has no default value",
+		`query { Log { ...Missing } }`:                                         "unknown fragment",
+		`mutation { Log { address } }`:                                         "cannot be a mutation",
+		`query A { Log { address } } query B { Block { hash } }`:               "exactly one operation",
+	} {
+		if _, err := schema.NormalizeQuery(query); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q to fail with %q, got %v", query, want, err)
+		}
+	}
+}
+
+func TestValidateQueryDocument(t *testing.T) {
+	s, err := fileschema.NewFileSchemaStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+
+	if err := schema.ValidateQuery(s, decodedLogsQuery); err != nil {
+		t.Errorf("expected the document to validate: %v", err)
+	}
+
+	// variables must match the argument types
+	if err := schema.ValidateQuery(s, `query ($limit: String = "x") { Log(limit: $limit) { address } }`); err == nil {
+		t.Error("expected a mistyped variable to be rejected")
+	}
+	if err := schema.ValidateQuery(s, `query ($a: String) { Log(filter: {address: {_eq: $a}}) { address } }`); err == nil {
+		t.Error("expected a variable without default to be rejected")
+	}
+	if err := schema.ValidateQuery(s, `query { Log { ...F } } fragment F on Block { hash }`); err == nil {
+		t.Error("expected a fragment on the wrong type to be rejected")
+	}
+}
@@ -0,0 +1,80 @@
+package schema
+
+import (
+	"strings"
+
+	"github.com/shinzonetwork/view-creator/core/schema/store"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// RelationField is a field whose type is another object type.
+type RelationField struct {
+	Definition store.Definition
+	Field      store.Field
+	Target     string
+	Name       string // @relation name; empty when the directive is missing
+	List       bool
+}
+
+// Relations returns every relation field of the object and interface definitions, in
+// definition order.
+func Relations(defs []store.Definition) []RelationField {
+	objects := map[string]bool{}
+	for _, def := range defs {
+		if def.Kind == ast.Object || def.Kind == ast.Interface {
+			objects[def.Name] = true
+		}
+	}
+
+	var relations []RelationField
+	for _, def := range defs {
+		if def.Kind != ast.Object && def.Kind != ast.Interface {
+			continue
+		}
+		for _, f := range def.Fields {
+			target := strings.Trim(f.Type, "[]!")
+			if !objects[target] {
+				continue
+			}
+			name, _ := relationName(f)
+			relations = append(relations, RelationField{
+				Definition: def,
+				Field:      f,
+				Target:     target,
+				Name:       name,
+				List:       strings.HasPrefix(f.Type, "["),
+			})
+		}
+	}
+	return relations
+}
+
+// Pairs reports whether r and other are the two sides of the same relation.
+func (r RelationField) Pairs(other RelationField) bool {
+	return r.Name != "" && r.Name == other.Name &&
+		other.Definition.Name == r.Target && other.Target == r.Definition.Name &&
+		other.Field.Name != r.Field.Name
+}
+
+func relationName(f store.Field) (string, bool) {
+	for _, d := range f.Directives {
+		if d.Name != "relation" {
+			continue
+		}
+		for _, a := range d.Arguments {
+			if a.Name == "name" {
+				return strings.Trim(a.Value, `"`), true
+			}
+		}
+	}
+	return "", false
+}
+
+func hasDirective(f store.Field, name string) bool {
+	for _, d := range f.Directives {
+		if d.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+// Package source fetches the default schema from a URL, a git repository or a local file,
+// optionally verifying it against a sha256 digest or an ed25519 signature.
+package source
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// DefaultURL is the schema shipped in this repository; {ref} is the branch, tag or commit.
+const DefaultURL = "https://raw.githubusercontent.com/shinzonetwork/view-creator/{ref}/tools/schema.graphql"
+
+// DefaultPath is the schema file inside a git repository.
+const DefaultPath = "tools/schema.graphql"
+
+var (
+	ErrChecksumMismatch = errors.New("schema checksum mismatch")
+	ErrBadSignature     = errors.New("schema signature verification failed")
+)
+
+// Source says where the default schema comes from.
+type Source struct {
+	// URL is a template for the schema location; {ref} is replaced by the requested ref.
+	URL string `json:"url"`
+	// Git is a repository URL or local path. When set, the schema is read from Path at the
+	// requested ref instead of from URL.
+	Git  string `json:"git"`
+	Path string `json:"path"`
+	// PublicKey is an ed25519 key, hex or base64. When set, every fetched schema must come
+	// with a detached signature in a ".sig" file next to it.
+	PublicKey string `json:"publicKey"`
+}
+
+func Default() Source {
+	return Source{URL: DefaultURL, Path: DefaultPath}
+}
+
+// FetchOptions selects what to fetch and how to check it.
+type FetchOptions struct {
+	Ref    string // branch, tag or commit; "main" when empty
+	File   string // read this local file instead of the configured source
+	SHA256 string // expected hex digest of the schema
+}
+
+// Fetch returns the schema and the location it was read from.
+func (s Source) Fetch(opts FetchOptions) (string, string, error) {
+	ref := opts.Ref
+	if ref == "" {
+		ref = "main"
+	}
+
+	var read func(suffix string) ([]byte, error)
+	var location string
+	switch {
+	case opts.File != "":
+		location = opts.File
+		read = func(suffix string) ([]byte, error) { return os.ReadFile(opts.File + suffix) }
+	case s.Git != "":
+		path := s.Path
+		if path == "" {
+			path = DefaultPath
+		}
+		location = fmt.Sprintf("%s@%s:%s", s.Git, ref, path)
+		read = func(suffix string) ([]byte, error) { return gitShow(s.Git, ref, path+suffix) }
+	default:
+		template := s.URL
+		if template == "" {
+			template = DefaultURL
+		}
+		location = strings.ReplaceAll(template, "{ref}", ref)
+		read = func(suffix string) ([]byte, error) { return httpGet(location + suffix) }
+	}
+
+	data, err := read("")
+	if err != nil {
+		return "", location, fmt.Errorf("failed to fetch schema from %s: %w", location, err)
+	}
+
+	if opts.SHA256 != "" {
+		sum := sha256.Sum256(data)
+		if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, opts.SHA256) {
+			return "", location, fmt.Errorf("%w: got %s, expected %s", ErrChecksumMismatch, got, opts.SHA256)
+		}
+	}
+
+	if s.PublicKey != "" {
+		sig, err := read(".sig")
+		if err != nil {
+			return "", location, fmt.Errorf("%w: failed to fetch signature %s.sig: %v", ErrBadSignature, location, err)
+		}
+		if err := verify(s.PublicKey, data, sig); err != nil {
+			return "", location, err
+		}
+	}
+
+	return strings.TrimSpace(string(data)) + "\n", location, nil
+}
+
+// httpClient bounds a fetch, including reading the body, so an unresponsive host fails
+// the update instead of hanging it.
+var httpClient = &http.Client{Timeout: 60 * time.Second}
+
+func httpGet(url string) ([]byte, error) {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// gitShow reads a file at a ref of a repository without a full clone.
+func gitShow(repo string, ref string, path string) ([]byte, error) {
+	// neither may be read as an option, such as --upload-pack=<command>
+	if strings.HasPrefix(repo, "-") {
+		return nil, fmt.Errorf("invalid git repository %q", repo)
+	}
+	if strings.HasPrefix(ref, "-") {
+		return nil, fmt.Errorf("invalid git ref %q", ref)
+	}
+
+	dir, err := os.MkdirTemp("", "viewkit-schema-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	run := func(args ...string) ([]byte, error) {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		var stderr strings.Builder
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+		}
+		return out, nil
+	}
+
+	if _, err := run("init", "-q"); err != nil {
+		return nil, err
+	}
+	if _, err := run("fetch", "-q", "--depth", "1", "--end-of-options", repo, ref); err != nil {
+		return nil, err
+	}
+	return run("show", "FETCH_HEAD:"+path)
+}
+
+func decodeKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if b, err := hex.DecodeString(s); err == nil {
+		return b, nil
+	}
+	return base64.StdEncoding.DecodeString(s)
+}
+
+func verify(publicKey string, data []byte, sig []byte) error {
+	key, err := decodeKey(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: invalid public key", ErrBadSignature)
+	}
+
+	// signatures may be raw bytes or base64 text
+	if len(sig) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+		if err != nil {
+			return fmt.Errorf("%w: unreadable signature", ErrBadSignature)
+		}
+		sig = decoded
+	}
+
+	if !ed25519.Verify(key, data, sig) {
+		return ErrBadSignature
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package source_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/core/schema/source"
+)
+
+const schema = "type Block { hash: String }\n"
+
+func TestFetchFromURLTemplate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1.2/schema.graphql" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(schema))
+	}))
+	defer server.Close()
+
+	src := source.Source{URL: server.URL + "/{ref}/schema.graphql"}
+
+	got, location, err := src.Fetch(source.FetchOptions{Ref: "v1.2"})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if got != schema || location != server.URL+"/v1.2/schema.graphql" {
+		t.Errorf("unexpected result %q from %s", got, location)
+	}
+
+	if _, _, err := src.Fetch(source.FetchOptions{}); err == nil {
+		t.Error("expected the main ref to 404")
+	}
+
+	sum := sha256.Sum256([]byte(schema))
+	if _, _, err := src.Fetch(source.FetchOptions{Ref: "v1.2", SHA256: hex.EncodeToString(sum[:])}); err != nil {
+		t.Errorf("expected matching checksum to pass: %v", err)
+	}
+	if _, _, err := src.Fetch(source.FetchOptions{Ref: "v1.2", SHA256: "00"}); !errors.Is(err, source.ErrChecksumMismatch) {
+		t.Errorf("expected ErrChecksumMismatch, got %v", err)
+	}
+}
+
+func TestFetchFromFileWithSignature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "schema.graphql")
+	if err := os.WriteFile(path, []byte(schema), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src := source.Source{PublicKey: hex.EncodeToString(pub)}
+	opts := source.FetchOptions{File: path}
+
+	if _, _, err := src.Fetch(opts); !errors.Is(err, source.ErrBadSignature) {
+		t.Errorf("expected a missing signature to fail, got %v", err)
+	}
+
+	sig := ed25519.Sign(priv, []byte(schema))
+	if err := os.WriteFile(path+".sig", []byte(base64.StdEncoding.EncodeToString(sig)+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, _, err := src.Fetch(opts); err != nil || got != schema {
+		t.Fatalf("expected a valid base64 signature to pass, got %q (err: %v)", got, err)
+	}
+
+	// raw signature bytes and a base64 key work too
+	src.PublicKey = base64.StdEncoding.EncodeToString(pub)
+	if err := os.WriteFile(path+".sig", sig, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := src.Fetch(opts); err != nil {
+		t.Errorf("expected a valid raw signature to pass: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(schema+"type Extra { x: String }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := src.Fetch(opts); !errors.Is(err, source.ErrBadSignature) {
+		t.Errorf("expected a tampered schema to fail, got %v", err)
+	}
+}
+
+func TestFetchFromGitRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	git("init", "-q", "-b", "main")
+	if err := os.MkdirAll(filepath.Join(repo, "tools"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "tools", "schema.graphql"), []byte(schema), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "schema")
+	git("tag", "v1")
+
+	src := source.Source{Git: repo}
+	got, location, err := src.Fetch(source.FetchOptions{Ref: "v1"})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if got != schema || location != repo+"@v1:tools/schema.graphql" {
+		t.Errorf("unexpected result %q from %s", got, location)
+	}
+
+	if _, _, err := src.Fetch(source.FetchOptions{Ref: "missing"}); err == nil {
+		t.Error("expected an unknown ref to fail")
+	}
+}
+
+func TestFetchFromGitRejectsOptions(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	marker := filepath.Join(t.TempDir(), "ran")
+	injected := "--upload-pack=touch " + marker
+
+	for _, tt := range []struct {
+		source source.Source
+		ref    string
+		want   string
+	}{
+		{source.Source{Git: t.TempDir()}, injected, "invalid git ref"},
+		{source.Source{Git: injected}, "v1", "invalid git repository"},
+	} {
+		_, _, err := tt.source.Fetch(source.FetchOptions{Ref: tt.ref})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("expected %q, got %v", tt.want, err)
+		}
+	}
+
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Errorf("expected the injected command not to run, got %v", err)
+	}
+}
@@ -0,0 +1,243 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// Origin tells which schema file a definition comes from.
+type Origin string
+
+const (
+	OriginDefault Origin = "default"
+	OriginCustom  Origin = "custom"
+)
+
+// Position locates a definition or field in its schema file.
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+type Argument struct {
+	Name  string
+	Value string // in GraphQL syntax
+}
+
+type Directive struct {
+	Name      string
+	Arguments []Argument
+}
+
+// Field is a field of an object, interface or input type, or a value of an enum.
+type Field struct {
+	Name         string
+	Type         string // GraphQL type reference such as "[Log!]!"; empty for enum values
+	Description  string
+	Arguments    []Field
+	DefaultValue string
+	Directives   []Directive
+	Position     Position
+}
+
+// Definition is a named type from the default or custom schema.
+type Definition struct {
+	Name        string
+	Kind        ast.DefinitionKind
+	Description string
+	Interfaces  []string // implemented interfaces
+	Types       []string // union members
+	Fields      []Field  // fields, input fields or enum values
+	Directives  []Directive
+	Origin      Origin
+	Position    Position
+
+	// Source is the definition printed back as SDL, including its comments.
+	Source string
+
+	// AST is the parsed definition for callers that need more than the fields above.
+	AST *ast.Definition
+}
+
+var kindKeywords = map[ast.DefinitionKind]string{
+	ast.Object:      "type",
+	ast.Interface:   "interface",
+	ast.Union:       "union",
+	ast.Enum:        "enum",
+	ast.Scalar:      "scalar",
+	ast.InputObject: "input",
+}
+
+// KindKeyword returns the SDL keyword that introduces a definition of the given kind, such as "enum".
+func KindKeyword(kind ast.DefinitionKind) string {
+	return kindKeywords[kind]
+}
+
+// Keyword returns the SDL keyword that introduces the definition.
+func (d Definition) Keyword() string {
+	return KindKeyword(d.Kind)
+}
+
+// Field returns the field with the given name, or nil.
+func (d Definition) Field(name string) *Field {
+	for i := range d.Fields {
+		if d.Fields[i].Name == name {
+			return &d.Fields[i]
+		}
+	}
+	return nil
+}
+
+// ParseDocument parses schema source without validating references, so that a file may
+// use types declared in another.
+func ParseDocument(file string, input string) (*ast.SchemaDocument, error) {
+	doc, err := parser.ParseSchema(&ast.Source{Name: file, Input: input})
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// ParseDefinitions parses schema source into typed definitions.
+func ParseDefinitions(file string, input string, origin Origin) ([]Definition, error) {
+	doc, err := ParseDocument(file, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+
+	defs := make([]Definition, 0, len(doc.Definitions))
+	for _, def := range doc.Definitions {
+		defs = append(defs, NewDefinition(def, origin))
+	}
+	return defs, nil
+}
+
+// NewDefinition converts a parsed definition into its typed form.
+func NewDefinition(def *ast.Definition, origin Origin) Definition {
+	d := Definition{
+		Name:        def.Name,
+		Kind:        def.Kind,
+		Description: def.Description,
+		Interfaces:  def.Interfaces,
+		Types:       def.Types,
+		Directives:  directives(def.Directives),
+		Origin:      origin,
+		Position:    position(def.Position),
+		Source:      FormatDefinition(def),
+		AST:         def,
+	}
+
+	for _, f := range def.Fields {
+		field := Field{
+			Name:        f.Name,
+			Type:        f.Type.String(),
+			Description: f.Description,
+			Directives:  directives(f.Directives),
+			Position:    position(f.Position),
+		}
+		if f.DefaultValue != nil {
+			field.DefaultValue = f.DefaultValue.String()
+		}
+		for _, a := range f.Arguments {
+			arg := Field{Name: a.Name, Type: a.Type.String(), Description: a.Description, Directives: directives(a.Directives), Position: position(a.Position)}
+			if a.DefaultValue != nil {
+				arg.DefaultValue = a.DefaultValue.String()
+			}
+			field.Arguments = append(field.Arguments, arg)
+		}
+		d.Fields = append(d.Fields, field)
+	}
+
+	for _, v := range def.EnumValues {
+		d.Fields = append(d.Fields, Field{
+			Name:        v.Name,
+			Description: v.Description,
+			Directives:  directives(v.Directives),
+			Position:    position(v.Position),
+		})
+	}
+
+	return d
+}
+
+func directives(list ast.DirectiveList) []Directive {
+	var out []Directive
+	for _, d := range list {
+		directive := Directive{Name: d.Name}
+		for _, a := range d.Arguments {
+			directive.Arguments = append(directive.Arguments, Argument{Name: a.Name, Value: a.Value.String()})
+		}
+		out = append(out, directive)
+	}
+	return out
+}
+
+func position(p *ast.Position) Position {
+	if p == nil {
+		return Position{}
+	}
+	pos := Position{Line: p.Line, Column: p.Column}
+	if p.Src != nil {
+		pos.File = p.Src.Name
+	}
+	return pos
+}
+
+func newFormatter(buf *bytes.Buffer) formatter.Formatter {
+	return formatter.NewFormatter(buf, formatter.WithComments(), formatter.WithIndent("  "))
+}
+
+// FormatDefinition prints a single definition as SDL, keeping its comments.
+func FormatDefinition(def *ast.Definition) string {
+	var buf bytes.Buffer
+	newFormatter(&buf).FormatSchemaDocument(&ast.SchemaDocument{Definitions: ast.DefinitionList{def}})
+	return strings.TrimSpace(buf.String())
+}
+
+// FormatDocument prints a schema document as SDL, keeping comments and separating
+// top-level definitions with a blank line.
+func FormatDocument(doc *ast.SchemaDocument) string {
+	var blocks []string
+
+	add := func(d *ast.SchemaDocument) {
+		var buf bytes.Buffer
+		newFormatter(&buf).FormatSchemaDocument(d)
+		if block := strings.TrimSpace(buf.String()); block != "" {
+			blocks = append(blocks, block)
+		}
+	}
+
+	for _, def := range doc.Directives {
+		add(&ast.SchemaDocument{Directives: ast.DirectiveDefinitionList{def}})
+	}
+	for _, def := range doc.Schema {
+		add(&ast.SchemaDocument{Schema: ast.SchemaDefinitionList{def}})
+	}
+	for _, def := range doc.Definitions {
+		add(&ast.SchemaDocument{Definitions: ast.DefinitionList{def}})
+	}
+	for _, def := range doc.SchemaExtension {
+		add(&ast.SchemaDocument{SchemaExtension: ast.SchemaDefinitionList{def}})
+	}
+	for _, def := range doc.Extensions {
+		add(&ast.SchemaDocument{Extensions: ast.DefinitionList{def}})
+	}
+
+	// comments after the last definition
+	add(&ast.SchemaDocument{Comment: doc.Comment})
+
+	if len(blocks) == 0 {
+		return ""
+	}
+	return strings.Join(blocks, "\n\n") + "\n"
+}
@@ -1 +1,9 @@
 package store
+
+import "errors"
+
+var (
+	ErrTypeNotFound     = errors.New("type not found in schema")
+	ErrNoPreviousSchema = errors.New("no previous default schema to revert to")
+	ErrProfileNotFound  = errors.New("schema profile not found")
+)
@@ -2,18 +2,20 @@ package fileschema
 
 import (
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 
+	"github.com/shinzonetwork/view-creator/core/schema/store"
 	"github.com/shinzonetwork/view-creator/tools"
 )
 
 type FileSchemaStore struct {
 	BasePath string
+
+	// root is the schema directory of the default profile; other profiles live under root/profiles.
+	root    string
+	profile string
 }
 
 func NewFileSchemaStore(dir ...string) (*FileSchemaStore, error) {
@@ -48,7 +50,7 @@ func NewFileSchemaStore(dir ...string) (*FileSchemaStore, error) {
 		}
 	}
 
-	return &FileSchemaStore{BasePath: base}, nil
+	return &FileSchemaStore{BasePath: base, root: base, profile: store.DefaultProfile}, nil
 }
 
 func (s *FileSchemaStore) Load() (string, error) {
@@ -106,85 +108,85 @@ func (s *FileSchemaStore) ResetCustom() error {
 }
 
 func (s *FileSchemaStore) ListTypes() ([]string, []string, error) {
-	defaultSchema, err := s.LoadDefault()
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to load default schema: %w", err)
-	}
-
-	customSchema, err := s.LoadCustom()
+	defs, err := s.Definitions()
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to load custom schema: %w", err)
+		return nil, nil, err
 	}
 
-	typeRegex := regexp.MustCompile(`(?m)^type\s+(\w+)`)
-
 	var defaultTypes, customTypes []string
-
-	for _, match := range typeRegex.FindAllStringSubmatch(defaultSchema, -1) {
-		defaultTypes = append(defaultTypes, match[1])
-	}
-	for _, match := range typeRegex.FindAllStringSubmatch(customSchema, -1) {
-		customTypes = append(customTypes, match[1])
+	for _, def := range defs {
+		if def.Origin == store.OriginDefault {
+			defaultTypes = append(defaultTypes, def.Name)
+		} else {
+			customTypes = append(customTypes, def.Name)
+		}
 	}
 
 	return defaultTypes, customTypes, nil
 }
 
 func (s *FileSchemaStore) GetTypeDefinition(typeName string) (string, error) {
-	paths := []string{
-		filepath.Join(s.BasePath, "default_schema.graphql"),
-		filepath.Join(s.BasePath, "custom_schema.graphql"),
+	def, err := s.GetDefinition(typeName)
+	if err != nil {
+		return "", err
 	}
+	return def.Source, nil
+}
 
-	re := regexp.MustCompile(`(?ms)^type\s+` + regexp.QuoteMeta(typeName) + `\s*\{([^}]*)\}`)
-
-	for _, path := range paths {
-		b, err := os.ReadFile(path)
-		if err != nil {
-			continue
-		}
-
-		content := string(b)
-		matches := re.FindStringSubmatch(content)
+func (s *FileSchemaStore) Definitions() ([]store.Definition, error) {
+	defaultSchema, err := s.LoadDefault()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load default schema: %w", err)
+	}
 
-		if len(matches) >= 2 {
-			return formatTypeBlock(typeName, matches[1]), nil
-		}
+	customSchema, err := s.LoadCustom()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load custom schema: %w", err)
 	}
 
-	return "", fmt.Errorf("type '%s' not found in schema", typeName)
-}
+	defaults, err := store.ParseDefinitions("default_schema.graphql", defaultSchema, store.OriginDefault)
+	if err != nil {
+		return nil, err
+	}
 
-func (s *FileSchemaStore) UpdateDefaultFromRemote(version string) error {
-	if version == "" {
-		version = "main"
+	customs, err := store.ParseDefinitions("custom_schema.graphql", customSchema, store.OriginCustom)
+	if err != nil {
+		return nil, err
 	}
 
-	url := fmt.Sprintf(
-		"https://raw.githubusercontent.com/shinzonetwork/viewkit/%s/tools/default_schema.graphql",
-		version,
-	)
+	return append(defaults, customs...), nil
+}
 
-	resp, err := http.Get(url)
+func (s *FileSchemaStore) GetDefinition(typeName string) (store.Definition, error) {
+	defs, err := s.Definitions()
 	if err != nil {
-		return fmt.Errorf("failed to fetch schema from %s: %w", url, err)
+		return store.Definition{}, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("unexpected response from %s: %s", url, resp.Status)
+	for _, def := range defs {
+		if def.Name == typeName {
+			return def, nil
+		}
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read schema response: %w", err)
-	}
+	return store.Definition{}, fmt.Errorf("%w: %s", store.ErrTypeNotFound, typeName)
+}
 
+func (s *FileSchemaStore) SaveDefault(schema string) error {
 	path := filepath.Join(s.BasePath, "default_schema.graphql")
 	temp := path + ".tmp"
 
+	// Keep the current schema so the update can be reverted
+	current, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read default schema: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.BasePath, "default_schema.prev.graphql"), current, 0644); err != nil {
+		return fmt.Errorf("failed to keep previous schema: %w", err)
+	}
+
 	// Safe write using temp file
-	if err := os.WriteFile(temp, []byte(strings.TrimSpace(string(body))+"\n"), 0644); err != nil {
+	if err := os.WriteFile(temp, []byte(strings.TrimSpace(schema)+"\n"), 0644); err != nil {
 		return fmt.Errorf("failed to write temp file: %w", err)
 	}
 	if err := os.Rename(temp, path); err != nil {
@@ -194,6 +196,23 @@ func (s *FileSchemaStore) UpdateDefaultFromRemote(version string) error {
 	return nil
 }
 
+func (s *FileSchemaStore) LoadPreviousDefault() (string, error) {
+	content, err := read(filepath.Join(s.BasePath, "default_schema.prev.graphql"))
+	if os.IsNotExist(err) {
+		return "", store.ErrNoPreviousSchema
+	}
+	return content, err
+}
+
+func (s *FileSchemaStore) RevertDefault() error {
+	previous, err := s.LoadPreviousDefault()
+	if err != nil {
+		return err
+	}
+	// SaveDefault keeps the schema being replaced, so a revert can itself be reverted
+	return s.SaveDefault(previous)
+}
+
 func isFileEmpty(path string) bool {
 	info, err := os.Stat(path)
 	return err == nil && info.Size() == 0
@@ -206,14 +225,3 @@ func read(path string) (string, error) {
 	}
 	return string(b), nil
 }
-
-func formatTypeBlock(typeName, rawBody string) string {
-	rawBody = strings.TrimSpace(rawBody)
-	lines := strings.Split(rawBody, "\n")
-
-	for i, line := range lines {
-		lines[i] = "  " + strings.TrimSpace(line)
-	}
-
-	return fmt.Sprintf("type %s {\n%s\n}", typeName, strings.Join(lines, "\n"))
-}
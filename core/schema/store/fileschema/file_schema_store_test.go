@@ -1,10 +1,13 @@
 package fileschema_test
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
+	"github.com/shinzonetwork/view-creator/core/schema/store"
 	"github.com/shinzonetwork/view-creator/core/schema/store/fileschema"
+	"github.com/vektah/gqlparser/v2/ast"
 )
 
 func TestFileSchemaStore_Lifecycle(t *testing.T) {
@@ -73,3 +76,190 @@ func TestFileSchemaStore_Lifecycle(t *testing.T) {
 		t.Error("custom schema should be empty after reset")
 	}
 }
+
+func TestFileSchemaStore_DefinitionsOfEveryKind(t *testing.T) {
+	s, err := fileschema.NewFileSchemaStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to initialize store: %v", err)
+	}
+
+	custom := `# tokens we track
+type Token @index(includes: [{field: "address"}, {field: "symbol"}]) {
+  # checksummed
+  address: String @index(unique: true)
+  symbol: String
+  side: Side
+}
+
+enum Side {
+  BUY
+  SELL
+}
+
+interface Node {
+  id: ID!
+}
+
+union Asset = Token
+
+scalar Hex
+
+input TokenFilter {
+  symbol: String = "USDT"
+}
+`
+	if err := s.SaveCustom(custom); err != nil {
+		t.Fatalf("failed to save custom schema: %v", err)
+	}
+
+	_, customTypes, err := s.ListTypes()
+	if err != nil {
+		t.Fatalf("failed to list types: %v", err)
+	}
+	if strings.Join(customTypes, ",") != "Token,Side,Node,Asset,Hex,TokenFilter" {
+		t.Errorf("unexpected custom types: %v", customTypes)
+	}
+
+	token, err := s.GetDefinition("Token")
+	if err != nil {
+		t.Fatalf("failed to get definition: %v", err)
+	}
+	if token.Kind != ast.Object || token.Origin != store.OriginCustom {
+		t.Errorf("unexpected kind or origin: %s %s", token.Kind, token.Origin)
+	}
+	if token.Position.String() != "custom_schema.graphql:2:6" {
+		t.Errorf("unexpected position: %s", token.Position)
+	}
+	if len(token.Directives) != 1 || token.Directives[0].Arguments[0].Value != `[{field:"address"},{field:"symbol"}]` {
+		t.Errorf("unexpected directives: %+v", token.Directives)
+	}
+	if f := token.Field("address"); f == nil || f.Type != "String" || f.Directives[0].Name != "index" {
+		t.Errorf("unexpected address field: %+v", f)
+	}
+
+	def, err := s.GetTypeDefinition("Token")
+	if err != nil {
+		t.Fatalf("failed to get type definition: %v", err)
+	}
+	if !strings.HasPrefix(def, "# tokens we track\ntype Token") || !strings.Contains(def, "# checksummed") {
+		t.Errorf("expected comments to be kept, got:\n%s", def)
+	}
+
+	side, err := s.GetDefinition("Side")
+	if err != nil {
+		t.Fatalf("failed to get enum: %v", err)
+	}
+	if side.Kind != ast.Enum || len(side.Fields) != 2 || side.Fields[1].Name != "SELL" {
+		t.Errorf("unexpected enum definition: %+v", side)
+	}
+
+	filter, err := s.GetDefinition("TokenFilter")
+	if err != nil {
+		t.Fatalf("failed to get input: %v", err)
+	}
+	if filter.Kind != ast.InputObject || filter.Fields[0].DefaultValue != `"USDT"` {
+		t.Errorf("unexpected input definition: %+v", filter)
+	}
+
+	if _, err := s.GetDefinition("Missing"); !errors.Is(err, store.ErrTypeNotFound) {
+		t.Errorf("expected ErrTypeNotFound, got: %v", err)
+	}
+}
+
+func TestFileSchemaStore_SaveRevertDefault(t *testing.T) {
+	s, err := fileschema.NewFileSchemaStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	original, _ := s.LoadDefault()
+	updated := "type Block { hash: String }\n"
+
+	if _, err := s.LoadPreviousDefault(); !errors.Is(err, store.ErrNoPreviousSchema) {
+		t.Errorf("expected ErrNoPreviousSchema before any update, got %v", err)
+	}
+	if err := s.RevertDefault(); !errors.Is(err, store.ErrNoPreviousSchema) {
+		t.Errorf("expected revert without a previous schema to fail, got %v", err)
+	}
+
+	if err := s.SaveDefault(updated); err != nil {
+		t.Fatalf("SaveDefault failed: %v", err)
+	}
+	if current, _ := s.LoadDefault(); current != updated {
+		t.Errorf("unexpected default schema %q", current)
+	}
+	if previous, err := s.LoadPreviousDefault(); err != nil || previous != original {
+		t.Errorf("expected the original schema to be kept, got err %v", err)
+	}
+
+	if err := s.RevertDefault(); err != nil {
+		t.Fatalf("RevertDefault failed: %v", err)
+	}
+	if current, _ := s.LoadDefault(); strings.TrimSpace(current) != strings.TrimSpace(original) {
+		t.Error("expected the original schema after revert")
+	}
+	if previous, _ := s.LoadPreviousDefault(); previous != updated {
+		t.Error("expected the reverted schema to become the previous one")
+	}
+}
+
+func TestFileSchemaStore_Profiles(t *testing.T) {
+	s, err := fileschema.NewFileSchemaStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	if s.Profile() != store.DefaultProfile {
+		t.Errorf("expected the default profile, got %s", s.Profile())
+	}
+
+	if err := s.AddProfile("solana", "type Slot { number: Int }"); err != nil {
+		t.Fatalf("AddProfile failed: %v", err)
+	}
+	for _, bad := range []string{"solana", "default", "Bad Name", ""} {
+		if err := s.AddProfile(bad, "type A { x: String }"); err == nil {
+			t.Errorf("expected AddProfile(%q) to fail", bad)
+		}
+	}
+	if err := s.AddProfile("broken", "type {"); err == nil {
+		t.Error("expected an invalid schema to be rejected")
+	}
+
+	profiles, err := s.Profiles()
+	if err != nil || strings.Join(profiles, ",") != "default,solana" {
+		t.Errorf("unexpected profiles %v (err: %v)", profiles, err)
+	}
+
+	solana, err := s.WithProfile("solana")
+	if err != nil {
+		t.Fatalf("WithProfile failed: %v", err)
+	}
+	if solana.Profile() != "solana" {
+		t.Errorf("unexpected profile %s", solana.Profile())
+	}
+
+	defaults, customs, err := solana.ListTypes()
+	if err != nil || strings.Join(defaults, ",") != "Slot" || len(customs) != 0 {
+		t.Errorf("unexpected solana types %v %v (err: %v)", defaults, customs, err)
+	}
+
+	// custom schemas are kept per profile
+	if err := solana.SaveCustom("type Vote { slot: Slot }"); err != nil {
+		t.Fatalf("SaveCustom failed: %v", err)
+	}
+	if custom, _ := s.LoadCustom(); strings.Contains(custom, "Vote") {
+		t.Error("custom types of one profile leaked into the default profile")
+	}
+
+	back, err := solana.WithProfile(store.DefaultProfile)
+	if err != nil {
+		t.Fatalf("WithProfile(default) failed: %v", err)
+	}
+	if _, err := back.GetDefinition("Block"); err != nil {
+		t.Errorf("expected the default profile to have Block: %v", err)
+	}
+
+	if _, err := s.WithProfile("missing"); !errors.Is(err, store.ErrProfileNotFound) {
+		t.Errorf("expected ErrProfileNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package fileschema
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/shinzonetwork/view-creator/core/schema/store"
+)
+
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+func (s *FileSchemaStore) Profile() string {
+	return s.profile
+}
+
+func (s *FileSchemaStore) profileDir(name string) string {
+	if name == store.DefaultProfile {
+		return s.root
+	}
+	return filepath.Join(s.root, "profiles", name)
+}
+
+func (s *FileSchemaStore) Profiles() ([]string, error) {
+	profiles := []string{store.DefaultProfile}
+
+	entries, err := os.ReadDir(filepath.Join(s.root, "profiles"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+
+	var others []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			others = append(others, entry.Name())
+		}
+	}
+	sort.Strings(others)
+
+	return append(profiles, others...), nil
+}
+
+func (s *FileSchemaStore) WithProfile(name string) (store.SchemaStore, error) {
+	if name == "" {
+		name = store.DefaultProfile
+	}
+
+	dir := s.profileDir(name)
+	if _, err := os.Stat(filepath.Join(dir, "default_schema.graphql")); err != nil {
+		return nil, fmt.Errorf("%w: %s", store.ErrProfileNotFound, name)
+	}
+
+	return &FileSchemaStore{BasePath: dir, root: s.root, profile: name}, nil
+}
+
+func (s *FileSchemaStore) AddProfile(name string, defaultSchema string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use lowercase letters, digits, '-' and '_'", name)
+	}
+	if name == store.DefaultProfile {
+		return fmt.Errorf("profile %s already exists", name)
+	}
+
+	dir := s.profileDir(name)
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("profile %s already exists", name)
+	}
+
+	if _, err := store.ParseDocument(name+".graphql", defaultSchema); err != nil {
+		return fmt.Errorf("invalid schema for profile %s: %w", name, err)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("unable to create profile directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "default_schema.graphql"), []byte(strings.TrimSpace(defaultSchema)+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write default schema: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "custom_schema.graphql"), []byte(""), 0644); err != nil {
+		return fmt.Errorf("failed to write custom schema: %w", err)
+	}
+	return nil
+}
@@ -1,5 +1,8 @@
 package store
 
+// DefaultProfile is the profile holding the embedded EVM schema.
+const DefaultProfile = "default"
+
 // SchemaStore defines a contract for managing GraphQL schemas used in the developer tool.
 //
 // This interface abstracts the storage and retrieval of schema files (default and custom),
@@ -8,8 +11,11 @@ package store
 // A schema is composed of two distinct parts:
 // - Default schema: typically maintained by the CLI and updated from a remote source
 // - Custom schema: user-defined types added and managed locally
+//
+// A store reads one schema profile. Each profile, such as one per chain, has its own
+// default and custom schema.
 type SchemaStore interface {
-	// LoadD returns the full contents of the default schema and custom schema combined.
+	// Load returns the full contents of the default schema and custom schema combined.
 	Load() (string, error)
 
 	// LoadDefault returns the full contents of the default schema.
@@ -24,22 +30,48 @@ type SchemaStore interface {
 	// Useful for batch edits or updates from an external source.
 	SaveCustom(schema string) error
 
-	// UpdateDefaultFromRemote fetches and replaces the default schema
-	// from a remote location based on the provided version (e.g., branch or tag).
-	// If version is empty, it defaults to "main".
-	UpdateDefaultFromRemote(version string) error
+	// SaveDefault replaces the default schema, keeping the current one as the previous version.
+	SaveDefault(schema string) error
+
+	// LoadPreviousDefault returns the default schema that was replaced last.
+	// Returns ErrNoPreviousSchema if the default schema was never replaced.
+	LoadPreviousDefault() (string, error)
+
+	// RevertDefault restores the previous default schema.
+	RevertDefault() error
 
 	// ResetCustom clears the custom schema, removing all user-defined types.
 	// Default schema remains untouched.
 	ResetCustom() error
 
-	// ListTypes returns all types found in the default and custom schemas.
+	// ListTypes returns the names of all types found in the default and custom schemas,
+	// of every kind (objects, interfaces, unions, enums, scalars and inputs).
 	// Results are returned as two separate slices:
 	//   - defaultTypes: from the default schema
 	//   - customTypes: from the custom schema
 	ListTypes() (defaultTypes []string, customTypes []string, err error)
 
-	// GetTypeDefinition returns the full definition block of a type by name.
+	// GetTypeDefinition returns the full definition block of a type by name, including comments.
 	// Searches both default and custom schemas.
 	GetTypeDefinition(typeName string) (string, error)
+
+	// Definitions returns every type definition, default schema first, parsed into typed form.
+	Definitions() ([]Definition, error)
+
+	// GetDefinition returns the parsed definition of a type by name.
+	// Searches both default and custom schemas.
+	GetDefinition(typeName string) (Definition, error)
+
+	// Profile returns the name of the profile this store reads.
+	Profile() string
+
+	// Profiles lists every profile, DefaultProfile first.
+	Profiles() ([]string, error)
+
+	// WithProfile returns a store reading the named profile.
+	// Returns ErrProfileNotFound if the profile does not exist.
+	WithProfile(name string) (SchemaStore, error)
+
+	// AddProfile creates a profile with the given default schema and an empty custom schema.
+	AddProfile(name string, defaultSchema string) error
 }
@@ -0,0 +1,109 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// FieldRef is a schema element a query depends on. Field is empty for a root selection,
+// which depends only on the type.
+type FieldRef struct {
+	Path      string // selection path in the query, such as "Log.block.number"
+	Type      string // type the field is selected on
+	Field     string
+	FieldType string // declared type of the field, such as "[Log]"
+}
+
+// QueryRefs lists every type and field the query selects, resolved against the given
+// schema source. Selections the schema cannot resolve are skipped.
+func QueryRefs(schemaSource string, rawQuery string) ([]FieldRef, error) {
+	fullSchema, err := buildSchemaWithRoot(schemaSource)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	schemaAST, err := gqlparser.LoadSchema(&ast.Source{Name: "combined.graphql", Input: fullSchema})
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	queryDoc, err := ParseQuery(rawQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &refWalker{schema: schemaAST, doc: queryDoc, seen: map[string]bool{}}
+	for _, op := range queryDoc.Operations {
+		w.walk(schemaAST.Query, op.SelectionSet, "", map[string]bool{})
+	}
+	return w.refs, nil
+}
+
+type refWalker struct {
+	schema *ast.Schema
+	doc    *ast.QueryDocument
+	refs   []FieldRef
+	seen   map[string]bool
+}
+
+func (w *refWalker) add(ref FieldRef) {
+	key := ref.Path + "|" + ref.Type + "|" + ref.Field
+	if !w.seen[key] {
+		w.seen[key] = true
+		w.refs = append(w.refs, ref)
+	}
+}
+
+func (w *refWalker) walk(parent *ast.Definition, set ast.SelectionSet, path string, fragments map[string]bool) {
+	if parent == nil {
+		return
+	}
+	for _, sel := range set {
+		switch s := sel.(type) {
+		case *ast.Field:
+			def := parent.Fields.ForName(s.Name)
+			if def == nil {
+				continue
+			}
+			named := def.Type.Name()
+			if strings.HasPrefix(s.Name, "_") {
+				// generated by the node: _docID, _group and the aggregates, whose
+				// arguments name the collections or relations they aggregate
+				for _, arg := range s.Arguments {
+					if parent == w.schema.Query {
+						w.add(FieldRef{Path: s.Name + "." + arg.Name, Type: arg.Name})
+					} else if f := parent.Fields.ForName(arg.Name); f != nil && !strings.HasPrefix(arg.Name, "_") {
+						w.add(FieldRef{Path: path + "." + s.Name + "." + arg.Name, Type: parent.Name, Field: arg.Name, FieldType: f.Type.String()})
+					}
+				}
+				w.walk(w.schema.Types[named], s.SelectionSet, path+"."+s.Name, fragments)
+				continue
+			}
+			if parent == w.schema.Query {
+				w.add(FieldRef{Path: s.Name, Type: named})
+				w.walk(w.schema.Types[named], s.SelectionSet, s.Name, fragments)
+				continue
+			}
+			fieldPath := path + "." + s.Name
+			w.add(FieldRef{Path: fieldPath, Type: parent.Name, Field: s.Name, FieldType: def.Type.String()})
+			w.walk(w.schema.Types[named], s.SelectionSet, fieldPath, fragments)
+		case *ast.InlineFragment:
+			target := parent
+			if s.TypeCondition != "" {
+				target = w.schema.Types[s.TypeCondition]
+			}
+			w.walk(target, s.SelectionSet, path, fragments)
+		case *ast.FragmentSpread:
+			frag := w.doc.Fragments.ForName(s.Name)
+			if frag == nil || fragments[s.Name] {
+				continue
+			}
+			fragments[s.Name] = true
+			w.walk(w.schema.Types[frag.TypeCondition], frag.SelectionSet, path, fragments)
+			delete(fragments, s.Name)
+		}
+	}
+}
@@ -2,13 +2,11 @@ package schema
 
 import (
 	"fmt"
-	"regexp"
 	"strings"
 
 	"github.com/shinzonetwork/view-creator/core/schema/store"
 	"github.com/vektah/gqlparser/v2"
 	"github.com/vektah/gqlparser/v2/ast"
-	"github.com/vektah/gqlparser/v2/parser"
 	"github.com/vektah/gqlparser/v2/validator"
 	"github.com/vektah/gqlparser/v2/validator/rules"
 )
@@ -24,7 +22,10 @@ func ValidateQuery(schemaStore store.SchemaStore, rawQuery string) error {
 		return fmt.Errorf("failed to load custom schema: %w", err)
 	}
 
-	fullSchema := buildSchemaWithRoot(defaultSchema + "\n\n" + customSchema)
+	fullSchema, err := buildSchemaWithRoot(defaultSchema + "\n\n" + customSchema)
+	if err != nil {
+		return fmt.Errorf("invalid schema: %w", err)
+	}
 
 	schemaAST, err := gqlparser.LoadSchema(&ast.Source{
 		Name:  "combined.graphql",
@@ -34,14 +35,9 @@ func ValidateQuery(schemaStore store.SchemaStore, rawQuery string) error {
 		return fmt.Errorf("invalid schema: %w", err)
 	}
 
-	wrappedQuery := fmt.Sprintf("query { %s }", strings.TrimSpace(rawQuery))
-
-	queryDoc, err := parser.ParseQuery(&ast.Source{
-		Name:  "query.graphql",
-		Input: wrappedQuery,
-	})
+	queryDoc, err := ParseQuery(rawQuery)
 	if err != nil {
-		return fmt.Errorf("query parse error: %w", err)
+		return err
 	}
 
 	errs := validator.ValidateWithRules(schemaAST, queryDoc, rules.NewDefaultRules())
@@ -53,24 +49,26 @@ func ValidateQuery(schemaStore store.SchemaStore, rawQuery string) error {
 		return fmt.Errorf("GraphQL validation failed:\n%s", strings.Join(lines, "\n"))
 	}
 
+	// the node runs the view without variables
+	if _, err := NormalizeQuery(rawQuery); err != nil {
+		return err
+	}
+
 	return nil
 }
 
-func buildSchemaWithRoot(original string) string {
-	re := regexp.MustCompile(`(?m)^type\s+(\w+)\s*\{`)
-	matches := re.FindAllStringSubmatch(original, -1)
-
-	var rootFields []string
-	for _, match := range matches {
-		typeName := match[1]
-		if typeName == "Query" {
-			continue
-		}
-		rootFields = append(rootFields, fmt.Sprintf("  %s: [%s]", typeName, typeName))
+// buildSchemaWithRoot adds the query surface of a DefraDB node: a Query root with a
+// collection field for every object type, and the filter, order and aggregate inputs.
+func buildSchemaWithRoot(original string) (string, error) {
+	doc, err := store.ParseDocument("combined.graphql", original)
+	if err != nil {
+		return "", err
 	}
 
-	rootType := "type Query {\n" + strings.Join(rootFields, "\n") + "\n}"
-	schemaBlock := "schema {\n  query: Query\n}"
+	generated, err := newQueryRoot(doc).build()
+	if err != nil {
+		return "", err
+	}
 
-	return strings.TrimSpace(original) + "\n\n" + schemaBlock + "\n\n" + rootType
+	return strings.TrimSpace(store.FormatDocument(doc)) + "\n\n" + generated, nil
 }
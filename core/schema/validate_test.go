@@ -0,0 +1,76 @@
+package schema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/core/schema"
+	"github.com/shinzonetwork/view-creator/core/schema/store/fileschema"
+)
+
+func TestValidateQueryDefraSurface(t *testing.T) {
+	s, err := fileschema.NewFileSchemaStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+	if err := s.SaveCustom("enum Side { BUY SELL }\n\ntype Trade { side: Side price: Float tags: [String] }"); err != nil {
+		t.Fatalf("failed to save custom schema: %v", err)
+	}
+
+	valid := []string{
+		`Log(filter: {address: {_eq: "0x1e3a"}}, order: {blockNumber: DESC}, limit: 10) { address topics }`,
+		`Log(filter: {_or: [{blockNumber: {_gt: 100}}, {removed: {_ne: "true"}}], topics: {_any: {_eq: "0xddf2"}}}) { _docID }`,
+		`Log(filter: {block: {number: {_ge: 1}}}, offset: 5) { block { hash } }`,
+		`Block { transactions(filter: {from: {_like: "0x%"}}, order: {gasUsed: ASC}, limit: 1) { hash } _count(transactions: {}) }`,
+		`Transaction(groupBy: [from]) { from _group { hash } _count(_group: {}) }`,
+		`Event(docID: ["bae-123"], showDeleted: true) { _docID _deleted eventName }`,
+		`_count(Log: {filter: {address: {_eq: "0x1"}}})`,
+		`Block { _sum(transactions: {field: blockNumber}) _max(_group: {field: number}) }`,
+		`Trade(filter: {side: {_in: [BUY]}, price: {_lt: 2.5}}, order: {side: ASC}) { side }`,
+	}
+	for _, q := range valid {
+		if err := schema.ValidateQuery(s, q); err != nil {
+			t.Errorf("expected %q to validate: %v", q, err)
+		}
+	}
+
+	invalid := map[string]string{
+		`Log(filter: {address: {_gt: 1}}) { address }`:        "_gt",
+		`Log(order: {address: UP}) { address }`:               "UP",
+		`Log(groupBy: [nope]) { address }`:                    "nope",
+		`Block { transactions(order: {logs: ASC}) { hash } }`: "logs",
+		`Block { _sum(transactions: {field: hash}) }`:         "hash",
+		`Trade(filter: {tags: {_like: "x"}}) { side }`:        "_like",
+	}
+	for q, want := range invalid {
+		err := schema.ValidateQuery(s, q)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q to fail mentioning %s, got %v", q, want, err)
+		}
+	}
+}
+
+func TestQueryRefsGeneratedFields(t *testing.T) {
+	s, err := fileschema.NewFileSchemaStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+	source, err := s.Load()
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	refs, err := schema.QueryRefs(source, `Block { _docID _count(transactions: {}) _group { number } } _count(Log: {})`)
+	if err != nil {
+		t.Fatalf("QueryRefs failed: %v", err)
+	}
+
+	var got []string
+	for _, r := range refs {
+		got = append(got, r.Path+"="+r.Type+"."+r.Field)
+	}
+	want := "Block=Block.,Block._count.transactions=Block.transactions,Block._group.number=Block.number,_count.Log=Log."
+	if strings.Join(got, ",") != want {
+		t.Errorf("unexpected refs:\n got  %s\n want %s", strings.Join(got, ","), want)
+	}
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shinzonetwork/view-creator/core/schema"
+	schemastore "github.com/shinzonetwork/view-creator/core/schema/store"
+	viewstore "github.com/shinzonetwork/view-creator/core/view/store"
+)
+
+var (
+	ErrQueryTooExpensive = errors.New("view query exceeds the maximum deploy cost")
+	ErrNoQuery           = errors.New("no query to analyze")
+)
+
+// AnalyzeView analyzes the query of a view against the schema of its profile.
+func AnalyzeView(name string, vs viewstore.ViewStore, ss schemastore.SchemaStore) (schema.QueryAnalysis, error) {
+	view, err := vs.Load(name)
+	if err != nil {
+		return schema.QueryAnalysis{}, err
+	}
+	if view.Query == nil || *view.Query == "" {
+		return schema.QueryAnalysis{}, fmt.Errorf("view %s has %w", name, ErrNoQuery)
+	}
+
+	ss, err = ViewSchemaStore(view, ss)
+	if err != nil {
+		return schema.QueryAnalysis{}, err
+	}
+	source, err := ss.Load()
+	if err != nil {
+		return schema.QueryAnalysis{}, err
+	}
+	return schema.AnalyzeQuery(source, *view.Query)
+}
+
+// CheckQueryCost fails when the analyzed cost class is above max. An empty max disables the
+// check.
+func CheckQueryCost(analysis schema.QueryAnalysis, max schema.CostClass) error {
+	if max != "" && analysis.Cost.Exceeds(max) {
+		return fmt.Errorf("%w: cost is %s, limit is %s", ErrQueryTooExpensive, analysis.Cost, max)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/core/schema"
+	"github.com/shinzonetwork/view-creator/core/schema/store/fileschema"
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/shinzonetwork/view-creator/core/view/store/local"
+)
+
+func TestAnalyzeViewAndCheckCost(t *testing.T) {
+	tempDir := t.TempDir()
+
+	viewStore, err := local.NewLocalStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create view store: %v", err)
+	}
+	schemaStore, err := fileschema.NewFileSchemaStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+
+	if _, err := service.InitView("logs", viewStore); err != nil {
+		t.Fatalf("InitView failed: %v", err)
+	}
+	if _, err := service.AnalyzeView("logs", viewStore, schemaStore); !errors.Is(err, service.ErrNoQuery) {
+		t.Errorf("expected a view without a query to be rejected, got %v", err)
+	}
+
+	if _, err := service.UpdateQuery("logs", "Log { address events { eventName } }", viewStore, schemaStore, service.QueryOptions{}); err != nil {
+		t.Fatalf("UpdateQuery failed: %v", err)
+	}
+	analysis, err := service.AnalyzeView("logs", viewStore, schemaStore)
+	if err != nil {
+		t.Fatalf("AnalyzeView failed: %v", err)
+	}
+	if analysis.Cost != schema.CostMedium {
+		t.Fatalf("expected a medium cost, got %+v", analysis)
+	}
+
+	if err := service.CheckQueryCost(analysis, schema.CostLow); !errors.Is(err, service.ErrQueryTooExpensive) {
+		t.Errorf("expected ErrQueryTooExpensive, got %v", err)
+	}
+	if err := service.CheckQueryCost(analysis, schema.CostMedium); err != nil {
+		t.Errorf("expected the cost to be within the limit, got %v", err)
+	}
+	if err := service.CheckQueryCost(analysis, ""); err != nil {
+		t.Errorf("expected an empty limit to disable the check, got %v", err)
+	}
+}
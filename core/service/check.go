@@ -0,0 +1,190 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shinzonetwork/view-creator/core/models"
+	"github.com/shinzonetwork/view-creator/core/schema"
+	schemastore "github.com/shinzonetwork/view-creator/core/schema/store"
+	viewstore "github.com/shinzonetwork/view-creator/core/view/store"
+)
+
+type ViewCheckStatus string
+
+const (
+	CheckValid    ViewCheckStatus = "valid"    // valid against an unchanged schema
+	CheckDrifted  ViewCheckStatus = "drifted"  // still valid, but the schema changed since it was pinned
+	CheckUnpinned ViewCheckStatus = "unpinned" // valid, with no recorded schema to compare with
+	CheckInvalid  ViewCheckStatus = "invalid"
+	CheckNoQuery  ViewCheckStatus = "no-query"
+)
+
+// ViewCheck is the result of revalidating one view against the current schema.
+type ViewCheck struct {
+	View   string
+	Status ViewCheckStatus
+	Err    error // validation error for invalid views
+
+	// Changed and Removed list the snapshotted types that differ from the current schema.
+	Changed []string
+	Removed []string
+
+	// Fields compares the SDL with what the query or lenses produce. It is nil for views
+	// without an SDL, and when the fields cannot be compared; FieldsNote then says why.
+	Fields     *schema.FieldMapping
+	FieldsNote string
+}
+
+// SchemaFingerprint hashes the current default and custom schema.
+func SchemaFingerprint(schemaStore schemastore.SchemaStore) (string, error) {
+	defaultSchema, customSchema, err := loadSchemaSource(schemaStore)
+	if err != nil {
+		return "", err
+	}
+	return schema.Fingerprint(defaultSchema + "\n\n" + customSchema)
+}
+
+func pinSchema(schemaStore schemastore.SchemaStore, query string, snapshot bool) (models.SchemaPin, error) {
+	defaultSchema, customSchema, err := loadSchemaSource(schemaStore)
+	if err != nil {
+		return models.SchemaPin{}, err
+	}
+	source := defaultSchema + "\n\n" + customSchema
+
+	fingerprint, err := schema.Fingerprint(source)
+	if err != nil {
+		return models.SchemaPin{}, fmt.Errorf("failed to fingerprint schema: %w", err)
+	}
+	pin := models.SchemaPin{
+		Fingerprint: fingerprint,
+		ValidatedAt: strconv.FormatInt(time.Now().Unix(), 10),
+	}
+
+	if snapshot {
+		types, err := schema.UsedTypes(source, query)
+		if err != nil {
+			return models.SchemaPin{}, err
+		}
+		pin.Snapshot, err = schema.Snapshot(source, types)
+		if err != nil {
+			return models.SchemaPin{}, err
+		}
+	}
+	return pin, nil
+}
+
+// CheckView revalidates the query of a view against the current schema and compares the
+// schema with the one the view was pinned to.
+func CheckView(name string, vs viewstore.ViewStore, ss schemastore.SchemaStore) (ViewCheck, error) {
+	view, err := vs.Load(name)
+	if err != nil {
+		return ViewCheck{}, err
+	}
+	return checkView(view, ss)
+}
+
+// CheckAllViews runs CheckView on every stored view.
+func CheckAllViews(vs viewstore.ViewStore, ss schemastore.SchemaStore) ([]ViewCheck, error) {
+	views, err := vs.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list views: %w", err)
+	}
+
+	var checks []ViewCheck
+	for _, view := range views {
+		check, err := checkView(view, ss)
+		if err != nil {
+			return nil, fmt.Errorf("view %s: %w", view.Name, err)
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+func checkView(view models.View, ss schemastore.SchemaStore) (ViewCheck, error) {
+	check := ViewCheck{View: view.Name}
+	if view.Query == nil || strings.TrimSpace(*view.Query) == "" {
+		check.Status = CheckNoQuery
+		return check, nil
+	}
+
+	ss, err := ViewSchemaStore(view, ss)
+	if err != nil {
+		return check, err
+	}
+
+	defaultSchema, customSchema, err := loadSchemaSource(ss)
+	if err != nil {
+		return check, err
+	}
+	source := defaultSchema + "\n\n" + customSchema
+
+	if view.Schema != nil && view.Schema.Snapshot != "" {
+		check.Changed, check.Removed, err = schema.SnapshotDrift(view.Schema.Snapshot, source)
+		if err != nil {
+			return check, fmt.Errorf("invalid schema snapshot: %w", err)
+		}
+	}
+
+	if err := schema.ValidateQuery(ss, *view.Query); err != nil {
+		check.Status = CheckInvalid
+		check.Err = err
+		return check, nil
+	}
+
+	if view.Sdl != nil && strings.TrimSpace(*view.Sdl) != "" {
+		fields, err := compareViewFields(view, source)
+		switch {
+		case errors.Is(err, schema.ErrSeveralRoots):
+			check.FieldsNote = "SDL fields not compared: " + err.Error()
+		case err != nil:
+			return check, err
+		default:
+			check.Fields = &fields
+		}
+	}
+
+	if view.Schema == nil {
+		check.Status = CheckUnpinned
+		return check, nil
+	}
+
+	fingerprint, err := schema.Fingerprint(source)
+	if err != nil {
+		return check, err
+	}
+	if fingerprint == view.Schema.Fingerprint {
+		check.Status = CheckValid
+	} else {
+		check.Status = CheckDrifted
+	}
+	return check, nil
+}
+
+// compareViewFields compares the SDL of a view with the documents that reach it: the query
+// output, replaced by the declared output of each lens that has one. Lenses without a
+// declared output are taken to pass their input through, as filters do.
+func compareViewFields(view models.View, source string) (schema.FieldMapping, error) {
+	// the last lens that declares an output shape produces the documents
+	for i := len(view.Transform.Lenses) - 1; i >= 0; i-- {
+		lens := view.Transform.Lenses[i]
+		if lens.Output == "" {
+			continue
+		}
+		output, err := schema.ParseOutput(lens.Output)
+		if err != nil {
+			return schema.FieldMapping{}, fmt.Errorf("lens %q: %w", lens.Label, err)
+		}
+		return schema.CompareFields(*view.Sdl, output, "lens "+lens.Label)
+	}
+
+	output, err := schema.QueryOutput(source, *view.Query)
+	if err != nil {
+		return schema.FieldMapping{}, err
+	}
+	return schema.CompareFields(*view.Sdl, output, "query")
+}
@@ -0,0 +1,210 @@
+package service_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/core/schema/store/fileschema"
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/shinzonetwork/view-creator/core/view/store/local"
+)
+
+func TestCheckViewReportsDrift(t *testing.T) {
+	tempDir := t.TempDir()
+
+	viewStore, err := local.NewLocalStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create view store: %v", err)
+	}
+	schemaStore, err := fileschema.NewFileSchemaStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+
+	if err := service.AddCustomSchema(schemaStore, "type Token { address: String }\n\ntype Pool { id: String }"); err != nil {
+		t.Fatalf("AddCustomSchema failed: %v", err)
+	}
+	if _, err := service.InitView("tokens", viewStore); err != nil {
+		t.Fatalf("InitView failed: %v", err)
+	}
+	if _, err := service.InitView("empty", viewStore); err != nil {
+		t.Fatalf("InitView failed: %v", err)
+	}
+
+	view, err := service.UpdateQuery("tokens", "Token { address }", viewStore, schemaStore, service.QueryOptions{Snapshot: true})
+	if err != nil {
+		t.Fatalf("UpdateQuery failed: %v", err)
+	}
+	if view.Schema == nil || !strings.HasPrefix(view.Schema.Fingerprint, "sha256:") {
+		t.Fatalf("expected the view to be pinned, got %+v", view.Schema)
+	}
+	if view.Schema.Snapshot != "type Token {\n  address: String\n}" {
+		t.Errorf("unexpected snapshot:\n%s", view.Schema.Snapshot)
+	}
+
+	check, err := service.CheckView("tokens", viewStore, schemaStore)
+	if err != nil || check.Status != service.CheckValid {
+		t.Fatalf("expected a valid view, got %+v (err: %v)", check, err)
+	}
+
+	// comments and definition order do not count as drift
+	if err := schemaStore.SaveCustom("# pools\ntype Pool { id: String }\n\ntype Token {\n  address: String\n}\n"); err != nil {
+		t.Fatalf("SaveCustom failed: %v", err)
+	}
+	if check, _ := service.CheckView("tokens", viewStore, schemaStore); check.Status != service.CheckValid {
+		t.Errorf("expected reformatting to keep the view valid, got %s", check.Status)
+	}
+
+	if err := service.AddSchemaField(schemaStore, "Token", "symbol: String"); err != nil {
+		t.Fatalf("AddSchemaField failed: %v", err)
+	}
+	check, err = service.CheckView("tokens", viewStore, schemaStore)
+	if err != nil || check.Status != service.CheckDrifted || strings.Join(check.Changed, ",") != "Token" {
+		t.Errorf("expected drift on Token, got %+v (err: %v)", check, err)
+	}
+
+	if err := service.RemoveCustomSchema(schemaStore, "Token", service.SchemaChangeOptions{}); err != nil {
+		t.Fatalf("RemoveCustomSchema failed: %v", err)
+	}
+	checks, err := service.CheckAllViews(viewStore, schemaStore)
+	if err != nil {
+		t.Fatalf("CheckAllViews failed: %v", err)
+	}
+	statuses := map[string]service.ViewCheckStatus{}
+	for _, c := range checks {
+		statuses[c.View] = c.Status
+		if c.View == "tokens" && (c.Err == nil || strings.Join(c.Removed, ",") != "Token") {
+			t.Errorf("expected tokens to report Token as removed, got %+v", c)
+		}
+	}
+	if statuses["tokens"] != service.CheckInvalid || statuses["empty"] != service.CheckNoQuery {
+		t.Errorf("unexpected statuses: %v", statuses)
+	}
+
+	view, err = service.ClearQuery("tokens", viewStore)
+	if err != nil || view.Schema != nil {
+		t.Errorf("expected clearing the query to drop the schema pin, got %+v (err: %v)", view.Schema, err)
+	}
+}
+
+func TestCheckViewComparesFields(t *testing.T) {
+	tempDir := t.TempDir()
+
+	viewStore, err := local.NewLocalStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create view store: %v", err)
+	}
+	schemaStore, err := fileschema.NewFileSchemaStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+
+	if _, err := service.InitView("logs", viewStore); err != nil {
+		t.Fatalf("InitView failed: %v", err)
+	}
+	if _, err := service.UpdateQuery("logs", "Log { address transactionHash }", viewStore, schemaStore, service.QueryOptions{}); err != nil {
+		t.Fatalf("UpdateQuery failed: %v", err)
+	}
+
+	check, err := service.CheckView("logs", viewStore, schemaStore)
+	if err != nil {
+		t.Fatalf("CheckView failed: %v", err)
+	}
+	if check.Fields != nil {
+		t.Errorf("expected no field comparison without an SDL, got %+v", check.Fields)
+	}
+
+	if _, err := service.UpdateSDL("logs", "type Logs @materialized(if: false) { address: String hash: String }", viewStore); err != nil {
+		t.Fatalf("UpdateSDL failed: %v", err)
+	}
+	check, err = service.CheckView("logs", viewStore, schemaStore)
+	if err != nil {
+		t.Fatalf("CheckView failed: %v", err)
+	}
+	if check.Fields == nil || check.Fields.Source != "query" || !reflect.DeepEqual(check.Fields.Unmapped, []string{"hash"}) || !reflect.DeepEqual(check.Fields.Unused, []string{"transactionHash"}) {
+		t.Errorf("unexpected field comparison %+v", check.Fields)
+	}
+
+	watPath := filepath.Join(tempDir, "noop.wat")
+	if err := os.WriteFile(watPath, []byte(`(module $noop (func $transform (export "transform")))`), 0644); err != nil {
+		t.Fatalf("failed to write wat: %v", err)
+	}
+	if _, err := service.InitLens("logs", "filter", watPath, nil, viewStore, service.LensOptions{}); err != nil {
+		t.Fatalf("InitLens failed: %v", err)
+	}
+	if _, err := service.InitLens("logs", "decode", watPath, nil, viewStore, service.LensOptions{Output: "address: String, hash: String"}); err != nil {
+		t.Fatalf("InitLens failed: %v", err)
+	}
+	if _, err := service.InitLens("logs", "broken", watPath, nil, viewStore, service.LensOptions{Output: "hash"}); err == nil {
+		t.Error("expected an invalid output shape to be rejected")
+	}
+
+	check, err = service.CheckView("logs", viewStore, schemaStore)
+	if err != nil {
+		t.Fatalf("CheckView failed: %v", err)
+	}
+	if check.Fields == nil || check.Fields.Source != "lens decode" || !check.Fields.OK() || len(check.Fields.Unused) != 0 {
+		t.Errorf("expected the declared lens output to match the SDL, got %+v", check.Fields)
+	}
+
+	payload, err := service.BuildDeployPayload("logs", viewStore)
+	if err != nil {
+		t.Fatalf("BuildDeployPayload failed: %v", err)
+	}
+	if strings.Contains(string(payload), "output") {
+		t.Errorf("expected lens output shapes to stay out of the deploy payload")
+	}
+}
+
+func TestCheckViewWithSeveralRoots(t *testing.T) {
+	tempDir := t.TempDir()
+
+	viewStore, err := local.NewLocalStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create view store: %v", err)
+	}
+	schemaStore, err := fileschema.NewFileSchemaStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+
+	if _, err := service.InitView("chain", viewStore); err != nil {
+		t.Fatalf("InitView failed: %v", err)
+	}
+	if _, err := service.UpdateQuery("chain", "Log { address } Block { hash }", viewStore, schemaStore, service.QueryOptions{}); err != nil {
+		t.Fatalf("UpdateQuery failed: %v", err)
+	}
+	if _, err := service.UpdateSDL("chain", "type Chain @materialized(if: false) { address: String }", viewStore); err != nil {
+		t.Fatalf("UpdateSDL failed: %v", err)
+	}
+
+	checks, err := service.CheckAllViews(viewStore, schemaStore)
+	if err != nil {
+		t.Fatalf("CheckAllViews failed: %v", err)
+	}
+	if len(checks) != 1 || checks[0].Status != service.CheckValid {
+		t.Fatalf("expected one valid view, got %+v", checks)
+	}
+	if checks[0].Fields != nil || !strings.Contains(checks[0].FieldsNote, "one root selection, found 2") {
+		t.Errorf("expected the field comparison to be skipped with a note, got %+v (note %q)", checks[0].Fields, checks[0].FieldsNote)
+	}
+
+	// a lens that declares its output can still be compared
+	watPath := filepath.Join(tempDir, "noop.wat")
+	if err := os.WriteFile(watPath, []byte(`(module (func (export "transform")))`), 0644); err != nil {
+		t.Fatalf("failed to write wat: %v", err)
+	}
+	if _, err := service.InitLens("chain", "merge", watPath, nil, viewStore, service.LensOptions{Output: "address: String"}); err != nil {
+		t.Fatalf("InitLens failed: %v", err)
+	}
+	check, err := service.CheckView("chain", viewStore, schemaStore)
+	if err != nil {
+		t.Fatalf("CheckView failed: %v", err)
+	}
+	if check.Fields == nil || !check.Fields.OK() || check.FieldsNote != "" {
+		t.Errorf("expected the lens output to be compared, got %+v (note %q)", check.Fields, check.FieldsNote)
+	}
+}
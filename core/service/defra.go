@@ -12,11 +12,16 @@ import (
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/shinzonetwork/view-creator/core/cache"
 	"github.com/shinzonetwork/view-creator/core/models"
+	"github.com/shinzonetwork/view-creator/core/schema"
 	schemastore "github.com/shinzonetwork/view-creator/core/schema/store"
+	"github.com/shinzonetwork/view-creator/core/util"
 	viewstore "github.com/shinzonetwork/view-creator/core/view/store"
 )
 
@@ -28,7 +33,7 @@ type DefraViewPayload struct {
 	Transform map[string]any `json:"Transform"`
 }
 
-func StartLocalNodeAndDeployView(name string, viewstore viewstore.ViewStore, schemastore schemastore.SchemaStore) error {
+func StartLocalNodeAndDeployView(name string, viewstore viewstore.ViewStore, schemastore schemastore.SchemaStore, downloads *cache.DownloadCache) error {
 	ctx := context.Background()
 
 	view, err := viewstore.Load(name)
@@ -36,6 +41,11 @@ func StartLocalNodeAndDeployView(name string, viewstore viewstore.ViewStore, sch
 		return err
 	}
 
+	schemastore, err = ViewSchemaStore(view, schemastore)
+	if err != nil {
+		return err
+	}
+
 	viewJson, err := ConvertViewToDefraJson(view)
 	if err != nil {
 		return err
@@ -46,7 +56,7 @@ func StartLocalNodeAndDeployView(name string, viewstore viewstore.ViewStore, sch
 
 	port := "9181"
 
-	bin, err := EnsureDefraBinary("0.18.0")
+	bin, err := EnsureDefraBinary(util.DefraVersion, downloads)
 	if err != nil {
 		return fmt.Errorf("failed to ensure defradb binary: %w", err)
 	}
@@ -99,17 +109,13 @@ func StartLocalNodeAndDeployView(name string, viewstore viewstore.ViewStore, sch
 		return cleanupDefra("failed to send view", err)
 	}
 
-	collection, err := extractCollectionName(result)
-	if err != nil {
-		return cleanupDefra("failed to send view", err)
-	}
-
-	err = RefreshView(ctx, "http://127.0.0.1:9181", collection)
+	collections, err := RefreshViewCollections(ctx, "http://127.0.0.1:9181", deref(view.Sdl), result)
 	if err != nil {
-		return cleanupDefra("failed to send view", err)
+		return cleanupDefra("failed to refresh view", err)
 	}
 
 	fmt.Println("✅ View Successfully Applied")
+	fmt.Println("📦 Collections:", strings.Join(collections, ", "))
 
 	fmt.Println("🧪 Visit the DefraDB GraphQL Playground at http://127.0.0.1:9181/")
 	fmt.Println("📦 Press Ctrl+C to stop...")
@@ -118,7 +124,7 @@ func StartLocalNodeAndDeployView(name string, viewstore viewstore.ViewStore, sch
 	return shutdownDefra()
 }
 
-func StartLocalNodeAndTestView(name string, viewstore viewstore.ViewStore, schemastore schemastore.SchemaStore) error {
+func StartLocalNodeAndTestView(name string, viewstore viewstore.ViewStore, schemastore schemastore.SchemaStore, downloads *cache.DownloadCache) error {
 	ctx := context.Background()
 
 	fmt.Println("🔍 Loading view...")
@@ -127,13 +133,18 @@ func StartLocalNodeAndTestView(name string, viewstore viewstore.ViewStore, schem
 		return fmt.Errorf("❌ Failed to load view: %w", err)
 	}
 
+	schemastore, err = ViewSchemaStore(view, schemastore)
+	if err != nil {
+		return fmt.Errorf("❌ Failed to open schema profile: %w", err)
+	}
+
 	viewJson, err := ConvertViewToDefraJson(view)
 	if err != nil {
 		return fmt.Errorf("❌ Failed to convert view to JSON: %w", err)
 	}
 
 	fmt.Println("⚙️  Ensuring DefraDB binary...")
-	bin, err := EnsureDefraBinary("0.18.0")
+	bin, err := EnsureDefraBinary(util.DefraVersion, downloads)
 	if err != nil {
 		return fmt.Errorf("❌ Failed to ensure DefraDB binary: %w", err)
 	}
@@ -196,23 +207,19 @@ func StartLocalNodeAndTestView(name string, viewstore viewstore.ViewStore, schem
 	}
 	fmt.Println("✅ View applied")
 
-	fmt.Println("🔎 Extracting collection name...")
-	collection, err := extractCollectionName(result)
-	if err != nil {
-		return cleanupDefra("❌ Failed to extract collection name", err)
-	}
-
-	fmt.Println("♻️  Refreshing view...")
-	err = RefreshView(ctx, "http://127.0.0.1:9181", collection)
+	fmt.Println("♻️  Refreshing and verifying collections...")
+	collections, err := RefreshViewCollections(ctx, "http://127.0.0.1:9181", deref(view.Sdl), result)
 	if err != nil {
 		return cleanupDefra("❌ Failed to refresh view", err)
 	}
-	fmt.Println("✅ View refreshed")
+	fmt.Println("✅ Collections refreshed:", strings.Join(collections, ", "))
 
 	fmt.Println("✅ Test flow completed successfully. Shutting down...")
 	return shutdownDefra()
 }
 
+// ConvertViewToDefraJson returns the view as a local node receives it. The query and SDL
+// are the canonical ones deploy signs; lenses point at their files instead of being inlined.
 func ConvertViewToDefraJson(view models.View) (string, error) {
 	transform := map[string]any{
 		"lenses": []map[string]any{},
@@ -226,9 +233,18 @@ func ConvertViewToDefraJson(view models.View) (string, error) {
 		transform["lenses"] = append(transform["lenses"].([]map[string]any), lensMap)
 	}
 
+	query, err := normalizedQuery(view)
+	if err != nil {
+		return "", err
+	}
+	sdl, err := canonicalSDL(view)
+	if err != nil {
+		return "", err
+	}
+
 	payload := DefraViewPayload{
-		Query:     deref(view.Query),
-		SDL:       deref(view.Sdl),
+		Query:     query,
+		SDL:       sdl,
 		Transform: transform,
 	}
 
@@ -240,6 +256,18 @@ func ConvertViewToDefraJson(view models.View) (string, error) {
 	return buf.String(), nil
 }
 
+// normalizedQuery returns the view query in the form DefraDB accepts, or "" without one.
+func normalizedQuery(view models.View) (string, error) {
+	if view.Query == nil || *view.Query == "" {
+		return "", nil
+	}
+	query, err := schema.NormalizeQuery(*view.Query)
+	if err != nil {
+		return "", fmt.Errorf("failed to normalize query: %w", err)
+	}
+	return query, nil
+}
+
 func SendViewToDefra(ctx context.Context, defraURL string, jsonPayload string) (string, error) {
 	url := defraURL + "/api/v0/view"
 
@@ -289,27 +317,103 @@ func RefreshView(ctx context.Context, defraURL string, collection string) error
 	return nil
 }
 
-func extractCollectionName(result string) (string, error) {
+// RefreshViewCollections refreshes every collection DefraDB created for a view, given the
+// result of SendViewToDefra, and verifies that each type of the SDL got a collection that
+// can be queried. It returns the collection names.
+func RefreshViewCollections(ctx context.Context, defraURL string, sdl string, result string) ([]string, error) {
+	collections, err := extractCollectionNames(result)
+	if err != nil {
+		return nil, err
+	}
+
+	types, err := schema.ViewTypes(sdl)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range types {
+		if !slices.Contains(collections, name) {
+			return nil, fmt.Errorf("DefraDB created no collection for type %s (created: %s)", name, strings.Join(collections, ", "))
+		}
+	}
+
+	for _, name := range collections {
+		if err := RefreshView(ctx, defraURL, name); err != nil {
+			return nil, fmt.Errorf("collection %s: %w", name, err)
+		}
+		if err := verifyCollection(ctx, defraURL, name); err != nil {
+			return nil, fmt.Errorf("collection %s: %w", name, err)
+		}
+	}
+	return collections, nil
+}
+
+func extractCollectionNames(result string) ([]string, error) {
 	var parsed []map[string]interface{}
 	if err := json.Unmarshal([]byte(result), &parsed); err != nil {
-		return "", fmt.Errorf("failed to parse result: %w", err)
+		return nil, fmt.Errorf("failed to parse result: %w", err)
 	}
 
 	if len(parsed) == 0 {
-		return "", fmt.Errorf("empty result")
+		return nil, fmt.Errorf("empty result")
+	}
+
+	var names []string
+	for _, collection := range parsed {
+		version, ok := collection["version"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("missing or invalid 'version' field")
+		}
+
+		name, ok := version["Name"].(string)
+		if !ok {
+			return nil, fmt.Errorf("missing or invalid 'Name' field")
+		}
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// verifyCollection queries a refreshed collection to check that it is served.
+func verifyCollection(ctx context.Context, defraURL string, collection string) error {
+	reqBody := map[string]string{
+		"query": fmt.Sprintf("query { %s { _docID } }", collection),
 	}
 
-	version, ok := parsed[0]["version"].(map[string]interface{})
-	if !ok {
-		return "", fmt.Errorf("missing or invalid 'version' field")
+	buf := new(bytes.Buffer)
+	if err := json.NewEncoder(buf).Encode(reqBody); err != nil {
+		return fmt.Errorf("failed to encode request body: %w", err)
 	}
 
-	name, ok := version["Name"].(string)
-	if !ok {
-		return "", fmt.Errorf("missing or invalid 'Name' field")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, defraURL+"/api/v0/graphql", buf)
+	if err != nil {
+		return fmt.Errorf("failed to create query request: %w", err)
 	}
+	req.Header.Set("Content-Type", "application/json")
 
-	return name, nil
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send query request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected query status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var parsed struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return fmt.Errorf("failed to parse query result: %w", err)
+	}
+	if len(parsed.Errors) > 0 {
+		return fmt.Errorf("query failed: %s", parsed.Errors[0].Message)
+	}
+	return nil
 }
 
 func InsertDataToDefra(ctx context.Context, data string) error {
@@ -345,7 +449,7 @@ func InsertDataToDefra(ctx context.Context, data string) error {
 	return nil
 }
 
-func EnsureDefraBinary(version string, dir ...string) (string, error) {
+func EnsureDefraBinary(version string, downloads *cache.DownloadCache, dir ...string) (string, error) {
 	var base string
 	if len(dir) == 0 || dir[0] == "" {
 		home, err := os.UserHomeDir()
@@ -360,7 +464,7 @@ func EnsureDefraBinary(version string, dir ...string) (string, error) {
 	binaryPath := filepath.Join(base, "defradb")
 
 	if _, err := os.Stat(binaryPath); os.IsNotExist(err) {
-		if err := DownloadDefraDB(version, dir...); err != nil {
+		if err := DownloadDefraDB(version, downloads, dir...); err != nil {
 			return "", fmt.Errorf("failed to download defradb: %w", err)
 		}
 	} else if err != nil {
@@ -370,7 +474,15 @@ func EnsureDefraBinary(version string, dir ...string) (string, error) {
 	return binaryPath, nil
 }
 
-func DownloadDefraDB(version string, dir ...string) error {
+// DefraDownloadOptions returns cache options suited to fetching the DefraDB release binary.
+func DefraDownloadOptions() cache.Options {
+	opts := cache.DefaultOptions()
+	opts.Timeout = 10 * time.Minute
+	opts.MaxSize = 512 << 20
+	return opts
+}
+
+func DownloadDefraDB(version string, downloads *cache.DownloadCache, dir ...string) error {
 	var base string
 
 	if len(dir) == 0 || dir[0] == "" {
@@ -387,14 +499,27 @@ func DownloadDefraDB(version string, dir ...string) error {
 		return fmt.Errorf("failed to create dir: %w", err)
 	}
 
+	if downloads == nil {
+		var err error
+		downloads, err = cache.NewDownloadCache(DefraDownloadOptions(), dir...)
+		if err != nil {
+			return err
+		}
+	}
+
 	url := defraDownloadURL(version)
 	fmt.Println("Downloading DefraDB from:", url)
 
-	resp, err := http.Get(url)
+	cached, err := downloads.Fetch(url, "")
 	if err != nil {
 		return fmt.Errorf("failed to download: %w", err)
 	}
-	defer resp.Body.Close()
+
+	in, err := os.Open(cached)
+	if err != nil {
+		return fmt.Errorf("failed to open cached binary: %w", err)
+	}
+	defer in.Close()
 
 	binary := filepath.Join(base, "defradb")
 	out, err := os.Create(binary)
@@ -403,7 +528,7 @@ func DownloadDefraDB(version string, dir ...string) error {
 	}
 	defer out.Close()
 
-	if _, err := io.Copy(out, resp.Body); err != nil {
+	if _, err := io.Copy(out, in); err != nil {
 		return fmt.Errorf("failed to write binary: %w", err)
 	}
 
@@ -0,0 +1,60 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/core/service"
+)
+
+func TestRefreshViewCollections(t *testing.T) {
+	var refreshed, queried []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/view/refresh":
+			refreshed = append(refreshed, r.URL.Query().Get("name"))
+		case "/api/v0/graphql":
+			var body struct{ Query string }
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			queried = append(queried, body.Query)
+			if strings.Contains(body.Query, "Broken") {
+				_, _ = w.Write([]byte(`{"errors":[{"message":"collection not found"}]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":{}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	sdl := `type Transfer { amount: Int from: Account @relation(name: "account_transfers") }
+type Account { address: String transfers: [Transfer] @relation(name: "account_transfers") }`
+	result := `[{"version":{"Name":"Transfer"}},{"version":{"Name":"Account"}}]`
+
+	collections, err := service.RefreshViewCollections(context.Background(), server.URL, sdl, result)
+	if err != nil {
+		t.Fatalf("RefreshViewCollections failed: %v", err)
+	}
+	if want := []string{"Transfer", "Account"}; !reflect.DeepEqual(collections, want) || !reflect.DeepEqual(refreshed, want) {
+		t.Errorf("expected both collections to be refreshed, got %v and %v", collections, refreshed)
+	}
+	if len(queried) != 2 || !strings.Contains(queried[1], "Account { _docID }") {
+		t.Errorf("expected both collections to be queried, got %v", queried)
+	}
+
+	_, err = service.RefreshViewCollections(context.Background(), server.URL, sdl, `[{"version":{"Name":"Transfer"}}]`)
+	if err == nil || !strings.Contains(err.Error(), "no collection for type Account") {
+		t.Errorf("expected a missing collection to be reported, got %v", err)
+	}
+
+	_, err = service.RefreshViewCollections(context.Background(), server.URL, "type Broken { a: String }", `[{"version":{"Name":"Broken"}}]`)
+	if err == nil || !strings.Contains(err.Error(), "collection Broken: query failed: collection not found") {
+		t.Errorf("expected a failing collection query to be reported, got %v", err)
+	}
+}
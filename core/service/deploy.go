@@ -4,12 +4,15 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"os"
-	"regexp"
+	"strings"
 
+	"github.com/shinzonetwork/view-creator/core/cache"
 	"github.com/shinzonetwork/view-creator/core/models"
+	"github.com/shinzonetwork/view-creator/core/schema"
 	schemastore "github.com/shinzonetwork/view-creator/core/schema/store"
 	viewstore "github.com/shinzonetwork/view-creator/core/view/store"
 
@@ -28,7 +31,34 @@ type ViewLite struct {
 	Transform models.Transform `json:"transform"`
 }
 
-func StartLocalNodeTestAndDeploy(name string, viewstore viewstore.ViewStore, schemastore schemastore.SchemaStore, wallet Wallet) error {
+type DeployOptions struct {
+	// MaxPayload caps the size of the view blob in bytes; zero disables the check.
+	MaxPayload int
+	// MaxCost caps the cost class of the view query; empty disables the check.
+	MaxCost schema.CostClass
+}
+
+func StartLocalNodeTestAndDeploy(name string, viewstore viewstore.ViewStore, schemastore schemastore.SchemaStore, downloads *cache.DownloadCache, wallet Wallet, opts DeployOptions) error {
+	// build the blob up front so an oversized view fails before the node is started
+	data, err := BuildDeployPayload(name, viewstore)
+	if err != nil {
+		return err
+	}
+	if err := CheckPayloadSize(data, opts.MaxPayload); err != nil {
+		return fmt.Errorf("❌ %w (run `viewkit view size %s` for a breakdown)", err, name)
+	}
+
+	// a view without a query has no cost to check
+	analysis, err := AnalyzeView(name, viewstore, schemastore)
+	if err != nil && !errors.Is(err, ErrNoQuery) {
+		return err
+	}
+	if err == nil {
+		if err := CheckQueryCost(analysis, opts.MaxCost); err != nil {
+			return fmt.Errorf("❌ %w (run `viewkit view analyze %s` for details)", err, name)
+		}
+	}
+
 	fmt.Println("🔧 Building and testing view before deployment...")
 
 	// Suppress stdout and stderr
@@ -38,7 +68,7 @@ func StartLocalNodeTestAndDeploy(name string, viewstore viewstore.ViewStore, sch
 	os.Stdout = null
 	os.Stderr = null
 
-	err := StartLocalNodeAndTestView(name, viewstore, schemastore)
+	err = StartLocalNodeAndTestView(name, viewstore, schemastore, downloads)
 
 	// Restore original stdout and stderr
 	os.Stdout = stdout
@@ -56,29 +86,16 @@ func StartLocalNodeTestAndDeploy(name string, viewstore viewstore.ViewStore, sch
 		return err
 	}
 
-	view, err := viewstore.Load(name)
-
-	for i := range view.Transform.Lenses {
-		lens := &view.Transform.Lenses[i]
-		blob, err := viewstore.GetAssetBlob(name, lens.Label)
-		if err != nil {
-			return fmt.Errorf("failed to get blob for lens %q: %w", lens.Label, err)
-		}
-		lens.Path = blob
-	}
-
-	viewLite := ViewLite{
-		Query:     view.Query,
-		Sdl:       view.Sdl,
-		Transform: view.Transform,
+	viewHash, viewid, err := ComputeViewID(crypto.PubkeyToAddress(privateKey.PublicKey), data)
+	if err != nil {
+		return err
 	}
 
-	data, err := json.Marshal(viewLite)
+	view, err := viewstore.Load(name)
 	if err != nil {
-		return fmt.Errorf("failed to marshal view: %w", err)
+		return err
 	}
-
-	viewHash, viewid, err := ComputeViewID(privateKey, data)
+	collections, err := schema.ViewTypes(deref(view.Sdl))
 	if err != nil {
 		return err
 	}
@@ -92,6 +109,7 @@ func StartLocalNodeTestAndDeploy(name string, viewstore viewstore.ViewStore, sch
 	fmt.Println("----------------------------------------")
 	fmt.Printf("🔑 View ID:           %s\n", viewid)
 	fmt.Printf("🔑 View Key:          %s\n", viewHash)
+	fmt.Printf("📦 Collections:       %s\n", strings.Join(collections, ", "))
 	fmt.Printf("📦 Transaction Hash:  %s\n", hash)
 	fmt.Println("Blob size (bytes):", len(data))
 	fmt.Println("----------------------------------------")
@@ -100,16 +118,23 @@ func StartLocalNodeTestAndDeploy(name string, viewstore viewstore.ViewStore, sch
 	return nil
 }
 
-func ComputeViewID(privateKey *ecdsa.PrivateKey, blob []byte) (common.Hash, string, error) {
-	sender := crypto.PubkeyToAddress(privateKey.PublicKey)
+// ComputeViewID derives the ID a view blob is registered under: the name of the first SDL
+// type, read from the parsed SDL, and the keccak256 hash of the sender address followed
+// by the blob.
+func ComputeViewID(sender common.Address, blob []byte) (common.Hash, string, error) {
+	var view ViewLite
+	if err := json.Unmarshal(blob, &view); err != nil {
+		return common.Hash{}, "", fmt.Errorf("invalid view blob: %w", err)
+	}
 
-	re := regexp.MustCompile(`type\s+([A-Za-z0-9_]+)`) // TODO: more robost regex to get sdl type
-	matches := re.FindStringSubmatch(string(blob))
-	if len(matches) < 1 {
+	types, err := schema.ViewTypes(deref(view.Sdl))
+	if err != nil {
+		return common.Hash{}, "", err
+	}
+	if len(types) == 0 {
 		return common.Hash{}, "", fmt.Errorf("invalid SDL, could not get resource name")
 	}
-
-	ResourceName := matches[1]
+	resourceName := types[0]
 
 	// Concatenate sender address and blob (same as abi.encodePacked)
 	combined := append(sender.Bytes(), blob...)
@@ -117,7 +142,16 @@ func ComputeViewID(privateKey *ecdsa.PrivateKey, blob []byte) (common.Hash, stri
 	// keccak256 hash
 	viewHash := crypto.Keccak256Hash(combined)
 
-	return viewHash, fmt.Sprintf("%s_%s", ResourceName, viewHash.Hex()), nil
+	return viewHash, fmt.Sprintf("%s_%s", resourceName, viewHash.Hex()), nil
+}
+
+// ViewID computes the ID a view would be registered under by sender, without deploying it.
+func ViewID(name string, s viewstore.ViewStore, sender common.Address) (common.Hash, string, error) {
+	data, err := BuildDeployPayload(name, s)
+	if err != nil {
+		return common.Hash{}, "", err
+	}
+	return ComputeViewID(sender, data)
 }
 
 func sendRegisterTx(
@@ -0,0 +1,124 @@
+package service_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/shinzonetwork/view-creator/core/view/store/local"
+)
+
+func TestComputeViewIDReadsTheSDL(t *testing.T) {
+	sender := common.HexToAddress("0x1e3aA9fE4Ef01D3cB3189c129a49E3C03126C636")
+
+	// "type Decoy" in the query and lens arguments must not be taken for the resource name
+	blob := []byte(`{"query":"Log { type Decoy }","sdl":"type Logs { address: String }","transform":{"lenses":[{"arguments":{"abi":"type Other"},"label":"l","path":"dHlwZSBYeXo="}]}}`)
+	viewHash, viewid, err := service.ComputeViewID(sender, blob)
+	if err != nil {
+		t.Fatalf("ComputeViewID failed: %v", err)
+	}
+	if viewid != "Logs_"+viewHash.Hex() {
+		t.Errorf("unexpected view ID %s", viewid)
+	}
+
+	if _, _, err := service.ComputeViewID(sender, []byte(`{"query":"type Logs","sdl":null}`)); err == nil {
+		t.Error("expected a blob without an SDL type to be rejected")
+	}
+}
+
+func TestViewIDIsDeterministic(t *testing.T) {
+	tempDir := t.TempDir()
+
+	viewStore, err := local.NewLocalStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create view store: %v", err)
+	}
+
+	watPath := filepath.Join(tempDir, "noop.wat")
+	if err := os.WriteFile(watPath, []byte(`(module $noop (func $transform (export "transform")))`), 0644); err != nil {
+		t.Fatalf("failed to write wat: %v", err)
+	}
+
+	sdls := map[string]string{
+		"compact": "type Logs @materialized(if: false) {address: String hash: String}",
+		"spaced":  "type Logs @materialized(if: false) {\n    address: String\n\n    hash: String\n}\n",
+		"noted":   "# decoded logs\ntype Logs @materialized(if: false) {address: String # emitter\nhash: String}",
+	}
+	for name, sdl := range sdls {
+		if _, err := service.InitView(name, viewStore); err != nil {
+			t.Fatalf("InitView failed: %v", err)
+		}
+		if _, err := service.UpdateSDL(name, sdl, viewStore); err != nil {
+			t.Fatalf("UpdateSDL failed: %v", err)
+		}
+		args := map[string]any{"value": "0x1e3a", "src": "address", "limit": 10}
+		if _, err := service.InitLens(name, "filter", watPath, args, viewStore, service.LensOptions{}); err != nil {
+			t.Fatalf("InitLens failed: %v", err)
+		}
+	}
+
+	sender := common.HexToAddress("0x1e3aA9fE4Ef01D3cB3189c129a49E3C03126C636")
+	_, compact, err := service.ViewID("compact", viewStore, sender)
+	if err != nil {
+		t.Fatalf("ViewID failed: %v", err)
+	}
+	_, spaced, err := service.ViewID("spaced", viewStore, sender)
+	if err != nil {
+		t.Fatalf("ViewID failed: %v", err)
+	}
+	if compact != spaced || !strings.HasPrefix(compact, "Logs_0x") {
+		t.Errorf("expected equal IDs for views differing only in whitespace, got %s and %s", compact, spaced)
+	}
+	_, noted, err := service.ViewID("noted", viewStore, sender)
+	if err != nil {
+		t.Fatalf("ViewID failed: %v", err)
+	}
+	if noted != compact {
+		t.Errorf("expected comments not to change the ID, got %s and %s", noted, compact)
+	}
+
+	payload, err := service.BuildDeployPayload("compact", viewStore)
+	if err != nil {
+		t.Fatalf("BuildDeployPayload failed: %v", err)
+	}
+	if !strings.Contains(string(payload), `{"arguments":{"limit":10,"src":"address","value":"0x1e3a"},"label":"filter","path":`) {
+		t.Errorf("expected sorted keys in the payload, got %s", payload)
+	}
+
+	// the local test flow applies the SDL deploy signs
+	signedPayload, err := service.BuildDeployPayload("noted", viewStore)
+	if err != nil {
+		t.Fatalf("BuildDeployPayload failed: %v", err)
+	}
+	var signed service.ViewLite
+	if err := json.Unmarshal(signedPayload, &signed); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	view, err := viewStore.Load("noted")
+	if err != nil {
+		t.Fatalf("failed to load view: %v", err)
+	}
+	local, err := service.ConvertViewToDefraJson(view)
+	if err != nil {
+		t.Fatalf("ConvertViewToDefraJson failed: %v", err)
+	}
+	var applied service.DefraViewPayload
+	if err := json.Unmarshal([]byte(local), &applied); err != nil {
+		t.Fatalf("invalid local payload: %v", err)
+	}
+	if applied.SDL != *signed.Sdl {
+		t.Errorf("expected the local SDL to match the signed one, got:\n%s\nwant:\n%s", applied.SDL, *signed.Sdl)
+	}
+
+	_, other, err := service.ViewID("compact", viewStore, common.HexToAddress("0x0000000000000000000000000000000000000001"))
+	if err != nil {
+		t.Fatalf("ViewID failed: %v", err)
+	}
+	if other == compact {
+		t.Error("expected the ID to depend on the deployer address")
+	}
+}
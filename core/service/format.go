@@ -0,0 +1,87 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/shinzonetwork/view-creator/core/models"
+	"github.com/shinzonetwork/view-creator/core/schema"
+	viewstore "github.com/shinzonetwork/view-creator/core/view/store"
+)
+
+type FormatOptions struct {
+	// Check reports unformatted views without saving them.
+	Check bool
+}
+
+// ViewFormat tells which parts of a view were, or with Check would be, reformatted.
+type ViewFormat struct {
+	View  string
+	Query bool
+	SDL   bool
+}
+
+func (f ViewFormat) Changed() bool {
+	return f.Query || f.SDL
+}
+
+// FormatView pretty-prints the query and SDL of a view and saves the result as a revision.
+func FormatView(name string, vs viewstore.ViewStore, opts FormatOptions) (ViewFormat, error) {
+	view, err := vs.Load(name)
+	if err != nil {
+		return ViewFormat{}, err
+	}
+	return formatView(view, vs, opts)
+}
+
+// FormatAllViews formats every stored view.
+func FormatAllViews(vs viewstore.ViewStore, opts FormatOptions) ([]ViewFormat, error) {
+	views, err := vs.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list views: %w", err)
+	}
+
+	var results []ViewFormat
+	for _, view := range views {
+		result, err := formatView(view, vs, opts)
+		if err != nil {
+			return nil, fmt.Errorf("view %s: %w", view.Name, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func formatView(view models.View, vs viewstore.ViewStore, opts FormatOptions) (ViewFormat, error) {
+	result := ViewFormat{View: view.Name}
+
+	if view.Query != nil && *view.Query != "" {
+		query, err := schema.FormatQuery(*view.Query)
+		if err != nil {
+			return result, err
+		}
+		if query != *view.Query {
+			result.Query = true
+			view.Query = &query
+		}
+	}
+
+	if view.Sdl != nil && *view.Sdl != "" {
+		sdl, err := schema.FormatSDL(*view.Sdl)
+		if err != nil {
+			return result, err
+		}
+		if sdl != *view.Sdl {
+			result.SDL = true
+			view.Sdl = &sdl
+		}
+	}
+
+	if opts.Check || !result.Changed() {
+		return result, nil
+	}
+
+	if _, err := vs.Save(view.Name, view); err != nil {
+		return result, err
+	}
+	return result, nil
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/shinzonetwork/view-creator/tools"
+)
+
+type lensLanguage struct {
+	Name      string
+	Toolchain string
+}
+
+var lensLanguages = map[string]lensLanguage{
+	"rust":           {Name: "Rust", Toolchain: "cargo with the wasm32-unknown-unknown target (`rustup target add wasm32-unknown-unknown`)"},
+	"assemblyscript": {Name: "AssemblyScript", Toolchain: "Node.js and npm"},
+	"tinygo":         {Name: "TinyGo", Toolchain: "TinyGo 0.30 or newer"},
+}
+
+var lensNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// LensLanguages returns the languages ScaffoldLens can generate.
+func LensLanguages() []string {
+	return []string{"rust", "assemblyscript", "tinygo"}
+}
+
+// ScaffoldLens writes a ready-to-build lens project named name into dir and returns its path.
+func ScaffoldLens(name string, lang string, dir string) (string, error) {
+	language, ok := lensLanguages[lang]
+	if !ok {
+		return "", fmt.Errorf("unsupported lens language %q. Must be one of: %s", lang, strings.Join(LensLanguages(), ", "))
+	}
+
+	if !lensNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid lens name %q: use letters, digits, '-' or '_' and start with a letter", name)
+	}
+
+	target := filepath.Join(dir, name)
+	if _, err := os.Stat(target); err == nil {
+		return "", fmt.Errorf("%s already exists", target)
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to check target directory: %w", err)
+	}
+
+	data := map[string]string{
+		"Name":      name,
+		"Crate":     strings.ToLower(strings.ReplaceAll(name, "-", "_")),
+		"Language":  language.Name,
+		"Toolchain": language.Toolchain,
+	}
+
+	for _, root := range []string{path.Join("lens", "common"), path.Join("lens", lang)} {
+		if err := renderLensTemplates(root, target, data); err != nil {
+			_ = os.RemoveAll(target)
+			return "", err
+		}
+	}
+
+	return target, nil
+}
+
+func renderLensTemplates(root string, target string, data map[string]string) error {
+	return fs.WalkDir(tools.LensTemplates, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		raw, err := tools.LensTemplates.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("failed to read template %s: %w", p, err)
+		}
+
+		tmpl, err := template.New(p).Option("missingkey=error").Parse(string(raw))
+		if err != nil {
+			return fmt.Errorf("failed to parse template %s: %w", p, err)
+		}
+
+		var out bytes.Buffer
+		if err := tmpl.Execute(&out, data); err != nil {
+			return fmt.Errorf("failed to render template %s: %w", p, err)
+		}
+
+		rel := strings.TrimSuffix(strings.TrimPrefix(p, root+"/"), ".tmpl")
+		dest := filepath.Join(target, filepath.FromSlash(rel))
+
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(dest), err)
+		}
+
+		return os.WriteFile(dest, out.Bytes(), 0644)
+	})
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/shinzonetwork/view-creator/core/models"
+	"github.com/shinzonetwork/view-creator/core/schema"
+	schemastore "github.com/shinzonetwork/view-creator/core/schema/store"
+	viewstore "github.com/shinzonetwork/view-creator/core/view/store"
+)
+
+func viewProfile(view models.View) string {
+	if view.Profile == "" {
+		return schemastore.DefaultProfile
+	}
+	return view.Profile
+}
+
+// ViewSchemaStore returns the schema store for the profile a view targets.
+func ViewSchemaStore(view models.View, ss schemastore.SchemaStore) (schemastore.SchemaStore, error) {
+	profile := viewProfile(view)
+	if profile == ss.Profile() {
+		return ss, nil
+	}
+	return ss.WithProfile(profile)
+}
+
+// SetViewProfile points a view at another schema profile. A view with a query must still
+// validate against the new profile, and is pinned to it.
+func SetViewProfile(name string, profile string, vs viewstore.ViewStore, ss schemastore.SchemaStore) (models.View, error) {
+	view, err := vs.Load(name)
+	if err != nil {
+		return models.View{}, err
+	}
+
+	target, err := ss.WithProfile(profile)
+	if err != nil {
+		return models.View{}, err
+	}
+
+	if view.Query != nil && *view.Query != "" {
+		if err := schema.ValidateQuery(target, *view.Query); err != nil {
+			return models.View{}, fmt.Errorf("query does not validate against profile %s: %w", profile, err)
+		}
+		snapshot := view.Schema != nil && view.Schema.Snapshot != ""
+		pin, err := pinSchema(target, *view.Query, snapshot)
+		if err != nil {
+			return models.View{}, err
+		}
+		view.Schema = &pin
+	}
+
+	view.Profile = profile
+	if profile == schemastore.DefaultProfile {
+		view.Profile = ""
+	}
+
+	return vs.Save(name, view)
+}
+
+// AddSchemaProfile creates a profile whose default schema is read from path.
+func AddSchemaProfile(ss schemastore.SchemaStore, name string, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read schema file: %w", err)
+	}
+	return ss.AddProfile(name, string(content))
+}
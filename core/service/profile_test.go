@@ -0,0 +1,76 @@
+package service_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/core/schema/store/fileschema"
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/shinzonetwork/view-creator/core/view/store/local"
+)
+
+func TestViewSchemaProfiles(t *testing.T) {
+	tempDir := t.TempDir()
+
+	viewStore, err := local.NewLocalStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create view store: %v", err)
+	}
+	schemaStore, err := fileschema.NewFileSchemaStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+
+	path := filepath.Join(tempDir, "solana.graphql")
+	if err := os.WriteFile(path, []byte("type Account { id: String }"), 0644); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+	if err := service.AddSchemaProfile(schemaStore, "solana", path); err != nil {
+		t.Fatalf("AddSchemaProfile failed: %v", err)
+	}
+
+	if _, err := service.InitView("accounts", viewStore); err != nil {
+		t.Fatalf("InitView failed: %v", err)
+	}
+	if _, err := service.UpdateQuery("accounts", "Account { id }", viewStore, schemaStore, service.QueryOptions{}); err == nil {
+		t.Fatal("expected the query to fail against the default profile")
+	}
+
+	view, err := service.SetViewProfile("accounts", "solana", viewStore, schemaStore)
+	if err != nil {
+		t.Fatalf("SetViewProfile failed: %v", err)
+	}
+	if view.Profile != "solana" {
+		t.Errorf("unexpected profile %q", view.Profile)
+	}
+
+	// the query is validated against the view's profile, not the active one
+	view, err = service.UpdateQuery("accounts", "Account { id }", viewStore, schemaStore, service.QueryOptions{})
+	if err != nil {
+		t.Fatalf("UpdateQuery failed: %v", err)
+	}
+	if check, err := service.CheckView("accounts", viewStore, schemaStore); err != nil || check.Status != service.CheckValid {
+		t.Errorf("expected a valid view, got %+v (err: %v)", check, err)
+	}
+
+	// a view with a query cannot move to a profile the query does not validate against
+	if _, err := service.SetViewProfile("accounts", "default", viewStore, schemaStore); err == nil {
+		t.Error("expected moving the view back to the default profile to fail")
+	}
+	if _, err := service.SetViewProfile("accounts", "missing", viewStore, schemaStore); err == nil {
+		t.Error("expected an unknown profile to fail")
+	}
+
+	// usages only cover views of the store's profile
+	if _, _, err := service.FindSchemaUsages(schemaStore, viewStore, "Account"); err == nil {
+		t.Error("expected Account to be unknown to the default profile")
+	}
+	solana, err := schemaStore.WithProfile("solana")
+	if err != nil {
+		t.Fatalf("WithProfile failed: %v", err)
+	}
+	if usages, _, err := service.FindSchemaUsages(solana, viewStore, "Account"); err != nil || len(usages) == 0 {
+		t.Errorf("expected usages on the solana profile, got %v (err: %v)", usages, err)
+	}
+}
@@ -2,30 +2,54 @@ package service
 
 import (
 	"fmt"
-	"regexp"
 	"strings"
 
+	"github.com/shinzonetwork/view-creator/core/schema"
+	"github.com/shinzonetwork/view-creator/core/schema/source"
 	"github.com/shinzonetwork/view-creator/core/schema/store"
 	"github.com/shinzonetwork/view-creator/core/util"
+	viewstore "github.com/shinzonetwork/view-creator/core/view/store"
+	"github.com/vektah/gqlparser/v2/ast"
 )
 
+// AddCustomSchema appends one or more type definitions of any kind (type, interface, union,
+// enum, scalar or input) to the custom schema.
 func AddCustomSchema(schemaStore store.SchemaStore, newSchema string) error {
 	customContent, err := schemaStore.LoadCustom()
 	if err != nil {
 		return fmt.Errorf("failed to load custom schema: %w", err)
 	}
 
-	typeName, err := extractStrictTypeName(newSchema)
+	if err := util.ValidateSchemaBlock(newSchema); err != nil {
+		return fmt.Errorf("invalid schema: %w", err)
+	}
+
+	doc, err := store.ParseDocument("new-type.graphql", newSchema)
 	if err != nil {
 		return err
 	}
+	if len(doc.Directives) > 0 || len(doc.Schema) > 0 || len(doc.SchemaExtension) > 0 || len(doc.Extensions) > 0 {
+		return fmt.Errorf("only type definitions (type, interface, union, enum, scalar, input) can be added")
+	}
+	if len(doc.Definitions) == 0 {
+		return fmt.Errorf("no type definition found")
+	}
 
-	if strings.Contains(customContent, fmt.Sprintf("type %s", typeName)) {
-		return fmt.Errorf("type %s already exists", typeName)
+	existing, err := schemaStore.Definitions()
+	if err != nil {
+		return err
 	}
 
-	if err := util.ValidateSchemaBlock(newSchema); err != nil {
-		return fmt.Errorf("invalid schema: %w", err)
+	origins := map[string]store.Origin{}
+	for _, def := range existing {
+		origins[def.Name] = def.Origin
+	}
+
+	for _, def := range doc.Definitions {
+		if origin, ok := origins[def.Name]; ok {
+			return fmt.Errorf("%s %s already exists in the %s schema", store.KindKeyword(def.Kind), def.Name, origin)
+		}
+		origins[def.Name] = store.OriginCustom
 	}
 
 	customContent = strings.TrimSpace(customContent) + "\n\n" + strings.TrimSpace(newSchema) + "\n"
@@ -45,37 +69,223 @@ func ListSchemas(schemaStore store.SchemaStore) (defaultTypes []string, customTy
 	return schemaStore.ListTypes()
 }
 
-func RemoveCustomSchema(schemaStore store.SchemaStore, name string) error {
+func ListSchemaDefinitions(schemaStore store.SchemaStore) ([]store.Definition, error) {
+	return schemaStore.Definitions()
+}
+
+// RemoveCustomSchema removes a custom definition, refusing when another definition still
+// references it, or when a stored view still uses it unless opts.Force is set.
+func RemoveCustomSchema(schemaStore store.SchemaStore, name string, opts SchemaChangeOptions) error {
 	custom, err := schemaStore.LoadCustom()
 	if err != nil {
 		return err
 	}
 
-	re := regexp.MustCompile(`(?ms)^type\s+` + regexp.QuoteMeta(name) + `\s*\{[^}]*\}\n*`)
-	modified := re.ReplaceAllString(custom, "")
-	if modified == custom {
+	doc, err := store.ParseDocument("custom_schema.graphql", custom)
+	if err != nil {
+		return fmt.Errorf("failed to parse custom schema: %w", err)
+	}
+
+	var kept ast.DefinitionList
+	for _, def := range doc.Definitions {
+		if def.Name != name {
+			kept = append(kept, def)
+		}
+	}
+	if len(kept) == len(doc.Definitions) {
+		if def, err := schemaStore.GetDefinition(name); err == nil && def.Origin == store.OriginDefault {
+			return fmt.Errorf("%s %s belongs to the default schema and cannot be removed", def.Keyword(), name)
+		}
 		return fmt.Errorf("type %s not found in custom schema", name)
 	}
+	doc.Definitions = kept
+
+	defaultSchema, err := schemaStore.LoadDefault()
+	if err != nil {
+		return err
+	}
+	defaults, err := store.ParseDocument("default_schema.graphql", defaultSchema)
+	if err != nil {
+		return err
+	}
+	if users := referencingDefinitions(name, append(defaults.Definitions, kept...)); len(users) > 0 {
+		return fmt.Errorf("cannot remove %s: still referenced by %s", name, strings.Join(users, ", "))
+	}
+
+	return saveCustomChecked(schemaStore, store.FormatDocument(doc), opts)
+}
+
+// referencingDefinitions returns the names of the definitions in all whose fields, arguments,
+// union members or interfaces name the type name.
+func referencingDefinitions(name string, all ast.DefinitionList) []string {
+	var users []string
+	for _, def := range all {
+		if def.Name == name {
+			continue
+		}
+		if referencesType(def, name) {
+			users = append(users, def.Name)
+		}
+	}
+	return users
+}
 
-	return schemaStore.SaveCustom(modified)
+func referencesType(def *ast.Definition, name string) bool {
+	for _, f := range def.Fields {
+		if util.UnwrapType(f.Type) == name {
+			return true
+		}
+		for _, a := range f.Arguments {
+			if util.UnwrapType(a.Type) == name {
+				return true
+			}
+		}
+	}
+	for _, member := range append(def.Types, def.Interfaces...) {
+		if member == name {
+			return true
+		}
+	}
+	return false
 }
 
-func ResetCustomSchemas(schemaStore store.SchemaStore) error {
+// ResetCustomSchemas clears the custom schema, refusing when a stored view uses a custom
+// type unless opts.Force is set.
+func ResetCustomSchemas(schemaStore store.SchemaStore, opts SchemaChangeOptions) error {
+	if err := checkSchemaChange(schemaStore, "", opts); err != nil {
+		return err
+	}
 	return schemaStore.ResetCustom()
 }
 
-func UpdateDefaultSchemas(schemaStore store.SchemaStore, version string) error {
-	if version == "" {
-		version = "main"
+// SchemaUpdatePlan describes what replacing the default schema would change.
+type SchemaUpdatePlan struct {
+	Location string // where the new schema was read from
+	Schema   string // the new default schema
+	Changes  []schema.Change
+	Affected []SchemaUsage // stored view selections that would no longer resolve
+	Skipped  []SkippedView // stored views the new schema could not be checked against
+}
+
+// Breaking reports whether any change is classified as breaking.
+func (p SchemaUpdatePlan) Breaking() bool {
+	for _, c := range p.Changes {
+		if c.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+func planDefaultSchema(schemaStore store.SchemaStore, newDefault string, views viewstore.ViewStore) (SchemaUpdatePlan, error) {
+	current, err := schemaStore.LoadDefault()
+	if err != nil {
+		return SchemaUpdatePlan{}, fmt.Errorf("failed to load default schema: %w", err)
+	}
+
+	changes, err := schema.DiffSchemas(current, newDefault)
+	if err != nil {
+		return SchemaUpdatePlan{}, err
+	}
+	plan := SchemaUpdatePlan{Schema: newDefault, Changes: changes}
+
+	if views != nil {
+		custom, err := schemaStore.LoadCustom()
+		if err != nil {
+			return SchemaUpdatePlan{}, fmt.Errorf("failed to load custom schema: %w", err)
+		}
+		plan.Affected, plan.Skipped, err = BreakingUsages(schemaStore, views, newDefault, custom)
+		if err != nil {
+			return SchemaUpdatePlan{}, err
+		}
+	}
+	return plan, nil
+}
+
+func applyDefaultSchema(schemaStore store.SchemaStore, plan SchemaUpdatePlan, opts SchemaChangeOptions) error {
+	if len(plan.Affected) > 0 && !opts.Force {
+		return &BreakingChangeError{Usages: plan.Affected}
+	}
+	return schemaStore.SaveDefault(plan.Schema)
+}
+
+// PlanDefaultSchemaUpdate fetches the default schema from src and compares it with the
+// current one, without saving anything.
+func PlanDefaultSchemaUpdate(schemaStore store.SchemaStore, src source.Source, fetch source.FetchOptions, views viewstore.ViewStore) (SchemaUpdatePlan, error) {
+	fetched, location, err := src.Fetch(fetch)
+	if err != nil {
+		return SchemaUpdatePlan{}, err
+	}
+
+	plan, err := planDefaultSchema(schemaStore, fetched, views)
+	plan.Location = location
+	return plan, err
+}
+
+// UpdateDefaultSchemas replaces the default schema with the one fetched from src. The
+// schema it replaces is kept for RevertDefaultSchema. The update is refused when it breaks
+// a stored view, unless opts.Force is set.
+func UpdateDefaultSchemas(schemaStore store.SchemaStore, src source.Source, fetch source.FetchOptions, opts SchemaChangeOptions) (SchemaUpdatePlan, error) {
+	plan, err := PlanDefaultSchemaUpdate(schemaStore, src, fetch, opts.Views)
+	if err != nil {
+		return SchemaUpdatePlan{}, err
+	}
+	return plan, applyDefaultSchema(schemaStore, plan, opts)
+}
+
+// RevertDefaultSchema restores the default schema that the last update replaced.
+func RevertDefaultSchema(schemaStore store.SchemaStore, opts SchemaChangeOptions) (SchemaUpdatePlan, error) {
+	previous, err := schemaStore.LoadPreviousDefault()
+	if err != nil {
+		return SchemaUpdatePlan{}, err
+	}
+
+	plan, err := planDefaultSchema(schemaStore, previous, opts.Views)
+	if err != nil {
+		return SchemaUpdatePlan{}, err
+	}
+	return plan, applyDefaultSchema(schemaStore, plan, opts)
+}
+
+// LintSchema runs the lint rules over the default and custom schema. With customOnly set,
+// findings in the default schema are dropped.
+func LintSchema(schemaStore store.SchemaStore, rules map[string]schema.Severity, customOnly bool) ([]schema.Finding, error) {
+	defs, err := schemaStore.Definitions()
+	if err != nil {
+		return nil, err
+	}
+
+	findings, err := schema.Lint(defs, rules)
+	if err != nil {
+		return nil, err
+	}
+	if !customOnly {
+		return findings, nil
+	}
+
+	var custom []schema.Finding
+	for _, f := range findings {
+		if f.Origin == store.OriginCustom {
+			custom = append(custom, f)
+		}
 	}
-	return schemaStore.UpdateDefaultFromRemote(version)
+	return custom, nil
 }
 
-func extractStrictTypeName(schema string) (string, error) {
-	re := regexp.MustCompile(`(?m)^\s*type\s+(\w+)`)
-	match := re.FindStringSubmatch(schema)
-	if len(match) < 2 {
-		return "", fmt.Errorf("only 'type <Name> { ... }' is supported")
+// SchemaDocs renders documentation for every default and custom type.
+func SchemaDocs(schemaStore store.SchemaStore, format string) (string, error) {
+	defs, err := schemaStore.Definitions()
+	if err != nil {
+		return "", err
+	}
+	return schema.RenderDocs(defs, format)
+}
+
+// SchemaGraph renders the relation graph of the default and custom schema.
+func SchemaGraph(schemaStore store.SchemaStore, format string) (string, error) {
+	defs, err := schemaStore.Definitions()
+	if err != nil {
+		return "", err
 	}
-	return match[1], nil
+	return schema.RenderGraph(defs, format)
 }
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shinzonetwork/view-creator/core/schema/store"
+	"github.com/shinzonetwork/view-creator/core/util"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// editCustomDefinition applies fn to the named custom definition, checks that every type
+// the custom definitions reference exists, and saves the custom schema. A definition that
+// fn renames must no longer be referenced under its old name.
+func editCustomDefinition(schemaStore store.SchemaStore, name string, opts SchemaChangeOptions, fn func(doc *ast.SchemaDocument, index int) error) error {
+	custom, err := schemaStore.LoadCustom()
+	if err != nil {
+		return err
+	}
+
+	doc, err := store.ParseDocument("custom_schema.graphql", custom)
+	if err != nil {
+		return fmt.Errorf("failed to parse custom schema: %w", err)
+	}
+
+	index := -1
+	for i, def := range doc.Definitions {
+		if def.Name == name {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		if def, err := schemaStore.GetDefinition(name); err == nil && def.Origin == store.OriginDefault {
+			return fmt.Errorf("%s %s belongs to the default schema and cannot be edited", def.Keyword(), name)
+		}
+		return fmt.Errorf("type %s not found in custom schema", name)
+	}
+
+	if err := fn(doc, index); err != nil {
+		return err
+	}
+
+	defaultSchema, err := schemaStore.LoadDefault()
+	if err != nil {
+		return err
+	}
+	defaults, err := store.ParseDocument("default_schema.graphql", defaultSchema)
+	if err != nil {
+		return err
+	}
+	all := append(defaults.Definitions, doc.Definitions...)
+	if renamed := doc.Definitions[index].Name; renamed != name {
+		if users := referencingDefinitions(name, all); len(users) > 0 {
+			return fmt.Errorf("cannot rename %s to %s: still referenced by %s", name, renamed, strings.Join(users, ", "))
+		}
+	}
+	for _, def := range doc.Definitions {
+		if err := checkTypeReferences(def, all); err != nil {
+			return err
+		}
+	}
+
+	return saveCustomChecked(schemaStore, store.FormatDocument(doc), opts)
+}
+
+// checkTypeReferences fails when def names a type that is neither built in, provided by
+// DefraDB nor defined.
+func checkTypeReferences(def *ast.Definition, all ast.DefinitionList) error {
+	known := map[string]bool{}
+	for _, d := range all {
+		known[d.Name] = true
+	}
+
+	check := func(t *ast.Type, where string) error {
+		name := util.UnwrapType(t)
+		if !util.IsBuiltinScalar(name) && !util.IsDefraType(name) && !known[name] {
+			return fmt.Errorf("undefined type %s (in %s)", name, where)
+		}
+		return nil
+	}
+
+	for _, f := range def.Fields {
+		if err := check(f.Type, def.Name+"."+f.Name); err != nil {
+			return err
+		}
+		for _, a := range f.Arguments {
+			if err := check(a.Type, def.Name+"."+f.Name+"("+a.Name+")"); err != nil {
+				return err
+			}
+		}
+	}
+	for _, member := range append(def.Types, def.Interfaces...) {
+		if !known[member] {
+			return fmt.Errorf("undefined type %s (in %s)", member, def.Name)
+		}
+	}
+	return nil
+}
+
+// parseFieldFor parses a single field (or enum value) in the form it takes inside def.
+func parseFieldFor(def *ast.Definition, fieldSDL string) (*ast.Definition, error) {
+	keyword := store.KindKeyword(def.Kind)
+	switch def.Kind {
+	case ast.Object, ast.Interface, ast.InputObject, ast.Enum:
+	default:
+		return nil, fmt.Errorf("%s %s has no fields", keyword, def.Name)
+	}
+
+	doc, err := store.ParseDocument("field.graphql", fmt.Sprintf("%s %s {\n%s\n}", keyword, def.Name, fieldSDL))
+	if err != nil {
+		return nil, fmt.Errorf("invalid field %q: %w", fieldSDL, err)
+	}
+
+	parsed := doc.Definitions[0]
+	if len(parsed.Fields)+len(parsed.EnumValues) != 1 {
+		return nil, fmt.Errorf("expected exactly one field, got %q", fieldSDL)
+	}
+	return parsed, nil
+}
+
+func hasMember(def *ast.Definition, name string) bool {
+	return def.Fields.ForName(name) != nil || def.EnumValues.ForName(name) != nil
+}
+
+// AddSchemaField adds a field such as `symbol: String @index` to a custom type, or a value
+// to a custom enum.
+func AddSchemaField(schemaStore store.SchemaStore, typeName string, fieldSDL string) error {
+	// adding a field cannot break an existing query
+	return editCustomDefinition(schemaStore, typeName, SchemaChangeOptions{}, func(doc *ast.SchemaDocument, index int) error {
+		def := doc.Definitions[index]
+
+		parsed, err := parseFieldFor(def, fieldSDL)
+		if err != nil {
+			return err
+		}
+
+		if len(parsed.Fields) == 1 {
+			if hasMember(def, parsed.Fields[0].Name) {
+				return fmt.Errorf("field %s.%s already exists", typeName, parsed.Fields[0].Name)
+			}
+			def.Fields = append(def.Fields, parsed.Fields[0])
+		} else {
+			if hasMember(def, parsed.EnumValues[0].Name) {
+				return fmt.Errorf("value %s.%s already exists", typeName, parsed.EnumValues[0].Name)
+			}
+			def.EnumValues = append(def.EnumValues, parsed.EnumValues[0])
+		}
+		return nil
+	})
+}
+
+// RemoveSchemaField removes a field from a custom type, or a value from a custom enum.
+func RemoveSchemaField(schemaStore store.SchemaStore, typeName string, fieldName string, opts SchemaChangeOptions) error {
+	return editCustomDefinition(schemaStore, typeName, opts, func(doc *ast.SchemaDocument, index int) error {
+		def := doc.Definitions[index]
+
+		var fields ast.FieldList
+		for _, f := range def.Fields {
+			if f.Name != fieldName {
+				fields = append(fields, f)
+			}
+		}
+		var values ast.EnumValueList
+		for _, v := range def.EnumValues {
+			if v.Name != fieldName {
+				values = append(values, v)
+			}
+		}
+
+		if len(fields) == len(def.Fields) && len(values) == len(def.EnumValues) {
+			return fmt.Errorf("field %s.%s not found", typeName, fieldName)
+		}
+		def.Fields = fields
+		def.EnumValues = values
+		return nil
+	})
+}
+
+// RenameSchemaField renames a field of a custom type, or a value of a custom enum.
+func RenameSchemaField(schemaStore store.SchemaStore, typeName string, oldName string, newName string, opts SchemaChangeOptions) error {
+	return editCustomDefinition(schemaStore, typeName, opts, func(doc *ast.SchemaDocument, index int) error {
+		def := doc.Definitions[index]
+
+		if hasMember(def, newName) {
+			return fmt.Errorf("field %s.%s already exists", typeName, newName)
+		}
+		// reuse the field parser to validate the new name
+		probe := newName + ": String"
+		if def.Kind == ast.Enum {
+			probe = newName
+		}
+		if _, err := parseFieldFor(def, probe); err != nil {
+			return fmt.Errorf("invalid field name %q", newName)
+		}
+
+		if f := def.Fields.ForName(oldName); f != nil {
+			f.Name = newName
+			return nil
+		}
+		if v := def.EnumValues.ForName(oldName); v != nil {
+			v.Name = newName
+			return nil
+		}
+		return fmt.Errorf("field %s.%s not found", typeName, oldName)
+	})
+}
+
+// ReplaceCustomDefinition swaps a custom definition for newSDL, which must hold exactly one
+// definition. The definition may be renamed as long as the new name is free.
+func ReplaceCustomDefinition(schemaStore store.SchemaStore, typeName string, newSDL string, opts SchemaChangeOptions) error {
+	parsed, err := store.ParseDocument("edit.graphql", newSDL)
+	if err != nil {
+		return fmt.Errorf("invalid schema: %w", err)
+	}
+	if len(parsed.Definitions) != 1 || len(parsed.Directives)+len(parsed.Extensions)+len(parsed.Schema)+len(parsed.SchemaExtension) > 0 {
+		return fmt.Errorf("expected exactly one type definition")
+	}
+	replacement := parsed.Definitions[0]
+
+	if replacement.Name != typeName {
+		if _, err := schemaStore.GetDefinition(replacement.Name); err == nil {
+			return fmt.Errorf("cannot rename %s to %s: %s already exists", typeName, replacement.Name, replacement.Name)
+		}
+	}
+
+	return editCustomDefinition(schemaStore, typeName, opts, func(doc *ast.SchemaDocument, index int) error {
+		doc.Definitions[index] = replacement
+		return nil
+	})
+}
@@ -46,7 +46,7 @@ func TestSchemaService_AddGetListRemoveReset(t *testing.T) {
 		t.Errorf("custom type 'TestServiceType' not found in list")
 	}
 
-	err = service.RemoveCustomSchema(schemaStore, "TestServiceType")
+	err = service.RemoveCustomSchema(schemaStore, "TestServiceType", service.SchemaChangeOptions{})
 	if err != nil {
 		t.Fatalf("RemoveCustomSchema failed: %v", err)
 	}
@@ -64,7 +64,7 @@ func TestSchemaService_AddGetListRemoveReset(t *testing.T) {
 		t.Fatalf("re-adding schema before reset failed: %v", err)
 	}
 
-	err = service.ResetCustomSchemas(schemaStore)
+	err = service.ResetCustomSchemas(schemaStore, service.SchemaChangeOptions{})
 	if err != nil {
 		t.Fatalf("ResetCustomSchemas failed: %v", err)
 	}
@@ -77,3 +77,287 @@ func TestSchemaService_AddGetListRemoveReset(t *testing.T) {
 		t.Error("expected custom schema to be empty after reset")
 	}
 }
+
+func TestRemoveCustomSchemaKeepsOtherDefinitions(t *testing.T) {
+	schemaStore, err := fileschema.NewFileSchemaStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+
+	custom := `# kept
+type Keep @index(includes: [{field: "a"}]) {
+  a: String
+}
+
+type Drop {
+  b: String
+}
+
+enum Side { BUY SELL }
+`
+	if err := schemaStore.SaveCustom(custom); err != nil {
+		t.Fatalf("failed to save custom schema: %v", err)
+	}
+
+	if err := service.RemoveCustomSchema(schemaStore, "Drop", service.SchemaChangeOptions{}); err != nil {
+		t.Fatalf("RemoveCustomSchema failed: %v", err)
+	}
+
+	after, err := schemaStore.LoadCustom()
+	if err != nil {
+		t.Fatalf("LoadCustom failed: %v", err)
+	}
+	if strings.Contains(after, "Drop") {
+		t.Errorf("expected Drop to be removed, got:\n%s", after)
+	}
+	for _, want := range []string{"# kept", `@index(includes: [{field:"a"}])`, "enum Side"} {
+		if !strings.Contains(after, want) {
+			t.Errorf("expected %q to survive removal, got:\n%s", want, after)
+		}
+	}
+
+	if err := service.RemoveCustomSchema(schemaStore, "Drop", service.SchemaChangeOptions{}); err == nil {
+		t.Error("expected removing a missing type to fail")
+	}
+}
+
+func TestRemoveCustomSchemaRefusesReferencedType(t *testing.T) {
+	schemaStore, err := fileschema.NewFileSchemaStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+
+	custom := `type A {
+  a: String
+}
+
+type B {
+  items: [A!]
+}
+
+union C = A | B
+`
+	if err := schemaStore.SaveCustom(custom); err != nil {
+		t.Fatalf("failed to save custom schema: %v", err)
+	}
+
+	err = service.RemoveCustomSchema(schemaStore, "A", service.SchemaChangeOptions{Force: true})
+	if err == nil || !strings.Contains(err.Error(), "still referenced by B, C") {
+		t.Fatalf("expected removal to be refused naming B and C, got %v", err)
+	}
+
+	after, err := schemaStore.LoadCustom()
+	if err != nil {
+		t.Fatalf("LoadCustom failed: %v", err)
+	}
+	if after != custom {
+		t.Errorf("expected the custom schema to be left unchanged, got:\n%s", after)
+	}
+
+	if err := service.RemoveCustomSchema(schemaStore, "C", service.SchemaChangeOptions{}); err != nil {
+		t.Fatalf("expected removing an unreferenced type to succeed: %v", err)
+	}
+}
+
+func TestAddCustomSchemaEveryKind(t *testing.T) {
+	schemaStore, err := fileschema.NewFileSchemaStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+
+	for _, sdl := range []string{
+		"enum TokenStandard { ERC20 ERC721 }",
+		"scalar Hex",
+		"interface Node { id: ID! }",
+		"input TokenFilter { standard: TokenStandard }",
+		"type Token implements Node { id: ID! standard: TokenStandard }\nunion Asset = Token",
+	} {
+		if err := service.AddCustomSchema(schemaStore, sdl); err != nil {
+			t.Fatalf("AddCustomSchema(%q) failed: %v", sdl, err)
+		}
+	}
+
+	_, customs, err := service.ListSchemas(schemaStore)
+	if err != nil {
+		t.Fatalf("ListSchemas failed: %v", err)
+	}
+	if strings.Join(customs, ",") != "TokenStandard,Hex,Node,TokenFilter,Token,Asset" {
+		t.Errorf("unexpected custom types: %v", customs)
+	}
+
+	def, err := service.GetSchemaTypeDefinition(schemaStore, "TokenStandard")
+	if err != nil || !strings.HasPrefix(def, "enum TokenStandard {") {
+		t.Errorf("unexpected enum definition %q (err: %v)", def, err)
+	}
+
+	if err := service.AddCustomSchema(schemaStore, "scalar Hex"); err == nil || !strings.Contains(err.Error(), "scalar Hex already exists in the custom schema") {
+		t.Errorf("expected duplicate custom scalar to be rejected, got: %v", err)
+	}
+	if err := service.AddCustomSchema(schemaStore, "enum Block { A }"); err == nil || !strings.Contains(err.Error(), "already exists in the default schema") {
+		t.Errorf("expected clash with default Block to be rejected, got: %v", err)
+	}
+	if err := service.AddCustomSchema(schemaStore, "directive @mine on OBJECT"); err == nil {
+		t.Error("expected directive definitions to be rejected")
+	}
+
+	if err := service.RemoveCustomSchema(schemaStore, "TokenStandard", service.SchemaChangeOptions{}); err == nil || !strings.Contains(err.Error(), "still referenced by TokenFilter, Token") {
+		t.Errorf("expected removal of a referenced enum to be refused, got: %v", err)
+	}
+	if err := service.RemoveCustomSchema(schemaStore, "Hex", service.SchemaChangeOptions{}); err != nil {
+		t.Fatalf("RemoveCustomSchema failed for scalar: %v", err)
+	}
+	if err := service.RemoveCustomSchema(schemaStore, "Block", service.SchemaChangeOptions{}); err == nil || !strings.Contains(err.Error(), "belongs to the default schema") {
+		t.Errorf("expected default type removal to be refused, got: %v", err)
+	}
+}
+
+func TestSchemaFieldEditing(t *testing.T) {
+	schemaStore, err := fileschema.NewFileSchemaStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+
+	if err := service.AddCustomSchema(schemaStore, "# a token\ntype Token {\n  address: String\n}\n\nenum Status { ACTIVE }"); err != nil {
+		t.Fatalf("AddCustomSchema failed: %v", err)
+	}
+
+	if err := service.AddSchemaField(schemaStore, "Token", "symbol: String @index"); err != nil {
+		t.Fatalf("AddSchemaField failed: %v", err)
+	}
+	if err := service.AddSchemaField(schemaStore, "Token", "block: Block"); err != nil {
+		t.Fatalf("AddSchemaField with a default type failed: %v", err)
+	}
+	if err := service.AddSchemaField(schemaStore, "Status", "ARCHIVED"); err != nil {
+		t.Fatalf("AddSchemaField on enum failed: %v", err)
+	}
+
+	for _, bad := range []struct{ typeName, field, want string }{
+		{"Token", "symbol: Int", "already exists"},
+		{"Token", "owner: Wallet", "undefined type Wallet"},
+		{"Token", "a: String b: String", "exactly one field"},
+		{"Block", "extra: String", "belongs to the default schema"},
+		{"Missing", "x: String", "not found in custom schema"},
+	} {
+		if err := service.AddSchemaField(schemaStore, bad.typeName, bad.field); err == nil || !strings.Contains(err.Error(), bad.want) {
+			t.Errorf("AddSchemaField(%s, %q): expected %q, got %v", bad.typeName, bad.field, bad.want, err)
+		}
+	}
+
+	if err := service.RenameSchemaField(schemaStore, "Token", "address", "contract", service.SchemaChangeOptions{}); err != nil {
+		t.Fatalf("RenameSchemaField failed: %v", err)
+	}
+	if err := service.RenameSchemaField(schemaStore, "Token", "symbol", "contract", service.SchemaChangeOptions{}); err == nil {
+		t.Error("expected rename onto an existing field to fail")
+	}
+	if err := service.RemoveSchemaField(schemaStore, "Token", "block", service.SchemaChangeOptions{}); err != nil {
+		t.Fatalf("RemoveSchemaField failed: %v", err)
+	}
+	if err := service.RemoveSchemaField(schemaStore, "Token", "block", service.SchemaChangeOptions{}); err == nil {
+		t.Error("expected removing a missing field to fail")
+	}
+
+	def, err := schemaStore.GetDefinition("Token")
+	if err != nil {
+		t.Fatalf("GetDefinition failed: %v", err)
+	}
+	want := "# a token\ntype Token {\n  contract: String\n  symbol: String @index\n}"
+	if def.Source != want {
+		t.Errorf("unexpected definition:\n%s\nwant:\n%s", def.Source, want)
+	}
+
+	status, err := schemaStore.GetDefinition("Status")
+	if err != nil || status.Field("ARCHIVED") == nil {
+		t.Errorf("expected ARCHIVED value on Status, got %+v (err: %v)", status.Fields, err)
+	}
+}
+
+func TestReplaceCustomDefinition(t *testing.T) {
+	schemaStore, err := fileschema.NewFileSchemaStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+
+	if err := service.AddCustomSchema(schemaStore, "type Token { address: String }\n\ntype Pool { id: String }"); err != nil {
+		t.Fatalf("AddCustomSchema failed: %v", err)
+	}
+
+	if err := service.ReplaceCustomDefinition(schemaStore, "Token", "type Token { address: String\n decimals: Int }", service.SchemaChangeOptions{}); err != nil {
+		t.Fatalf("ReplaceCustomDefinition failed: %v", err)
+	}
+	def, err := schemaStore.GetDefinition("Token")
+	if err != nil || def.Field("decimals") == nil {
+		t.Errorf("expected decimals field, got %+v (err: %v)", def.Fields, err)
+	}
+
+	if err := service.ReplaceCustomDefinition(schemaStore, "Token", "type Pool { id: String }", service.SchemaChangeOptions{}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected rename onto Pool to fail, got %v", err)
+	}
+	if err := service.ReplaceCustomDefinition(schemaStore, "Token", "type A { x: String }\ntype B { y: String }", service.SchemaChangeOptions{}); err == nil {
+		t.Error("expected two definitions to be rejected")
+	}
+	if err := service.ReplaceCustomDefinition(schemaStore, "Token", "type Token { x: ", service.SchemaChangeOptions{}); err == nil {
+		t.Error("expected a syntax error")
+	}
+
+	custom, err := schemaStore.LoadCustom()
+	if err != nil {
+		t.Fatalf("LoadCustom failed: %v", err)
+	}
+	if !strings.HasSuffix(custom, "type Pool {\n  id: String\n}\n") {
+		t.Errorf("expected Pool to be kept, got:\n%s", custom)
+	}
+}
+
+func TestReplaceCustomDefinitionRenameChecksReferences(t *testing.T) {
+	schemaStore, err := fileschema.NewFileSchemaStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+
+	if err := service.AddCustomSchema(schemaStore, "type Token { address: String }\n\ntype Pool { token: Token }"); err != nil {
+		t.Fatalf("AddCustomSchema failed: %v", err)
+	}
+
+	err = service.ReplaceCustomDefinition(schemaStore, "Token", "type Asset { address: String }", service.SchemaChangeOptions{Force: true})
+	if err == nil || !strings.Contains(err.Error(), "cannot rename Token to Asset: still referenced by Pool") {
+		t.Fatalf("expected rename of a referenced type to be refused, got %v", err)
+	}
+	if _, err := schemaStore.GetDefinition("Token"); err != nil {
+		t.Errorf("expected Token to be kept: %v", err)
+	}
+
+	if err := service.ReplaceCustomDefinition(schemaStore, "Pool", "type Pool { token: Asset }", service.SchemaChangeOptions{}); err == nil || !strings.Contains(err.Error(), "undefined type Asset") {
+		t.Errorf("expected an undefined reference to be rejected, got %v", err)
+	}
+
+	if err := service.ReplaceCustomDefinition(schemaStore, "Pool", "type Venue { id: String }", service.SchemaChangeOptions{}); err != nil {
+		t.Fatalf("expected rename of an unreferenced type to succeed: %v", err)
+	}
+	if err := service.ReplaceCustomDefinition(schemaStore, "Token", "type Asset { address: String }", service.SchemaChangeOptions{}); err != nil {
+		t.Fatalf("expected rename to succeed once nothing references Token: %v", err)
+	}
+}
+
+func TestSchemaFieldEditingWithDefraScalars(t *testing.T) {
+	schemaStore, err := fileschema.NewFileSchemaStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+
+	if err := service.AddCustomSchema(schemaStore, "type Transfer { payload: JSON at: DateTime }\n\ntype Other { id: String }"); err != nil {
+		t.Fatalf("AddCustomSchema failed: %v", err)
+	}
+
+	if err := service.AddSchemaField(schemaStore, "Other", "name: String"); err != nil {
+		t.Errorf("expected editing Other to succeed while Transfer uses DefraDB scalars: %v", err)
+	}
+	if err := service.AddSchemaField(schemaStore, "Transfer", "raw: Blob"); err != nil {
+		t.Errorf("expected a DefraDB scalar field to be accepted: %v", err)
+	}
+	if err := service.RenameSchemaField(schemaStore, "Transfer", "at", "timestamp", service.SchemaChangeOptions{}); err != nil {
+		t.Errorf("expected renaming a DateTime field to succeed: %v", err)
+	}
+	if err := service.AddSchemaField(schemaStore, "Other", "owner: Wallet"); err == nil || !strings.Contains(err.Error(), "undefined type Wallet") {
+		t.Errorf("expected an undefined type to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package service_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/core/schema/source"
+	"github.com/shinzonetwork/view-creator/core/schema/store/fileschema"
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/shinzonetwork/view-creator/core/view/store/local"
+	"github.com/shinzonetwork/view-creator/tools"
+)
+
+// updatedDefaultSchema drops Log.data, retypes Block.number, adds Block.chainId and a
+// Receipt type, and removes @index from Transaction.blockHash.
+func updatedDefaultSchema() string {
+	s := strings.Replace(tools.DefaultSchema, "    data: String\n", "", 1)
+	s = strings.Replace(s, "number: Int @index", "number: String @index\n    chainId: Int", 1)
+	s = strings.Replace(s, "blockHash: String @index\n    blockNumber: Int @index\n    from", "blockHash: String\n    blockNumber: Int @index\n    from", 1)
+	return s + "\n\ntype Receipt {\n    status: String\n}\n"
+}
+
+func TestUpdateDefaultSchemaDetectsBreakingChanges(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, updatedDefaultSchema())
+	}))
+	defer server.Close()
+
+	tempDir := t.TempDir()
+	viewStore, err := local.NewLocalStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create view store: %v", err)
+	}
+	schemaStore, err := fileschema.NewFileSchemaStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+	src := source.Source{URL: server.URL + "/{ref}/schema.graphql"}
+
+	if _, err := service.InitView("logs", viewStore); err != nil {
+		t.Fatalf("InitView failed: %v", err)
+	}
+	if _, err := service.UpdateQuery("logs", "Log { address data }", viewStore, schemaStore, service.QueryOptions{}); err != nil {
+		t.Fatalf("UpdateQuery failed: %v", err)
+	}
+
+	plan, err := service.PlanDefaultSchemaUpdate(schemaStore, src, source.FetchOptions{Ref: "v2"}, viewStore)
+	if err != nil {
+		t.Fatalf("PlanDefaultSchemaUpdate failed: %v", err)
+	}
+
+	got := map[string]bool{}
+	for _, c := range plan.Changes {
+		got[c.String()] = c.Breaking
+	}
+	want := map[string]bool{
+		"field Log.data removed":                              true,
+		"field Block.number changed type from Int to String":  true,
+		"field Block.chainId: Int added":                      false,
+		"type Receipt added":                                  false,
+		"directive @index removed from Transaction.blockHash": true,
+	}
+	if len(got) != len(want) {
+		t.Errorf("expected %d changes, got %v", len(want), got)
+	}
+	for change, breaking := range want {
+		if b, ok := got[change]; !ok || b != breaking {
+			t.Errorf("expected change %q (breaking: %v), got %v", change, breaking, got)
+		}
+	}
+	if !plan.Breaking() {
+		t.Error("expected the plan to be breaking")
+	}
+	if plan.Location != server.URL+"/v2/schema.graphql" {
+		t.Errorf("unexpected location %q", plan.Location)
+	}
+	if len(plan.Affected) != 1 || plan.Affected[0].String() != "logs: Log.data (Log.data)" {
+		t.Errorf("unexpected affected views: %v", plan.Affected)
+	}
+
+	original, _ := schemaStore.LoadDefault()
+
+	opts := service.SchemaChangeOptions{Views: viewStore}
+	if _, err := service.UpdateDefaultSchemas(schemaStore, src, source.FetchOptions{Ref: "v2"}, opts); !errors.Is(err, service.ErrBreakingChange) {
+		t.Fatalf("expected the update to be refused, got %v", err)
+	}
+	if current, _ := schemaStore.LoadDefault(); current != original {
+		t.Fatal("a refused update must not change the default schema")
+	}
+
+	opts.Force = true
+	if _, err := service.UpdateDefaultSchemas(schemaStore, src, source.FetchOptions{Ref: "v2"}, opts); err != nil {
+		t.Fatalf("forced update failed: %v", err)
+	}
+	if _, err := schemaStore.GetDefinition("Receipt"); err != nil {
+		t.Errorf("expected Receipt after update: %v", err)
+	}
+
+	plan, err = service.RevertDefaultSchema(schemaStore, service.SchemaChangeOptions{Views: viewStore})
+	if err != nil {
+		t.Fatalf("RevertDefaultSchema failed: %v", err)
+	}
+	if len(plan.Affected) != 0 {
+		t.Errorf("reverting should not break the view, got %v", plan.Affected)
+	}
+	if _, err := schemaStore.GetDefinition("Receipt"); err == nil {
+		t.Error("expected Receipt to be gone after revert")
+	}
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shinzonetwork/view-creator/core/schema"
+	"github.com/shinzonetwork/view-creator/core/schema/store"
+	viewstore "github.com/shinzonetwork/view-creator/core/view/store"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+var ErrBreakingChange = errors.New("schema change would break stored views")
+
+// SchemaUsage is a view query selection that depends on a schema type or field.
+type SchemaUsage struct {
+	View  string
+	Path  string // selection path in the query, such as "Log.block.number"
+	Type  string
+	Field string // empty when the view selects the type at the query root
+}
+
+func (u SchemaUsage) String() string {
+	if u.Field == "" {
+		return fmt.Sprintf("%s: %s", u.View, u.Path)
+	}
+	return fmt.Sprintf("%s: %s (%s.%s)", u.View, u.Path, u.Type, u.Field)
+}
+
+// BreakingChangeError lists the view selections a schema change would invalidate.
+type BreakingChangeError struct {
+	Usages []SchemaUsage
+}
+
+func (e *BreakingChangeError) Error() string {
+	lines := []string{fmt.Sprintf("%s; use --force to apply anyway:", ErrBreakingChange)}
+	for _, u := range e.Usages {
+		lines = append(lines, "  • "+u.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (e *BreakingChangeError) Unwrap() error {
+	return ErrBreakingChange
+}
+
+// SchemaChangeOptions controls the impact check run before a custom schema is saved.
+// Without Views no check is made.
+type SchemaChangeOptions struct {
+	Views viewstore.ViewStore
+	Force bool
+	// Warn is called for each stored view the change could not be checked against.
+	Warn func(SkippedView)
+}
+
+// SkippedView is a stored view whose query no longer resolves against the current schema,
+// so schema changes cannot be checked against it.
+type SkippedView struct {
+	View string
+	Err  error
+}
+
+func (v SkippedView) String() string {
+	return fmt.Sprintf("%s: %v", v.View, v.Err)
+}
+
+// viewRefs resolves the queries of the views that target profile. Views whose query does
+// not resolve are returned as skipped rather than failing the whole lookup.
+func viewRefs(schemaSource string, profile string, views viewstore.ViewStore) (map[string][]schema.FieldRef, []string, []SkippedView, error) {
+	list, err := views.List()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to list views: %w", err)
+	}
+
+	refs := map[string][]schema.FieldRef{}
+	var names []string
+	var skipped []SkippedView
+	for _, view := range list {
+		if view.Query == nil || strings.TrimSpace(*view.Query) == "" || viewProfile(view) != profile {
+			continue
+		}
+		r, err := schema.QueryRefs(schemaSource, *view.Query)
+		if err != nil {
+			skipped = append(skipped, SkippedView{View: view.Name, Err: err})
+			continue
+		}
+		refs[view.Name] = r
+		names = append(names, view.Name)
+	}
+	return refs, names, skipped, nil
+}
+
+func loadSchemaSource(schemaStore store.SchemaStore) (string, string, error) {
+	defaultSchema, err := schemaStore.LoadDefault()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to load default schema: %w", err)
+	}
+	customSchema, err := schemaStore.LoadCustom()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to load custom schema: %w", err)
+	}
+	return defaultSchema, customSchema, nil
+}
+
+// FindSchemaUsages lists the view selections that use typeName, either by selecting its
+// fields or through a field of that type, and the views that could not be searched.
+func FindSchemaUsages(schemaStore store.SchemaStore, views viewstore.ViewStore, typeName string) ([]SchemaUsage, []SkippedView, error) {
+	if _, err := schemaStore.GetDefinition(typeName); err != nil {
+		return nil, nil, err
+	}
+
+	defaultSchema, customSchema, err := loadSchemaSource(schemaStore)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refs, names, skipped, err := viewRefs(defaultSchema+"\n\n"+customSchema, schemaStore.Profile(), views)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var usages []SchemaUsage
+	for _, name := range names {
+		for _, ref := range refs[name] {
+			if ref.Type == typeName || namedType(ref.FieldType) == typeName {
+				usages = append(usages, SchemaUsage{View: name, Path: ref.Path, Type: ref.Type, Field: ref.Field})
+			}
+		}
+	}
+	return usages, skipped, nil
+}
+
+func namedType(typeRef string) string {
+	return strings.Trim(typeRef, "[]!")
+}
+
+// BreakingUsages lists the view selections that would no longer resolve if the schema were
+// replaced by newDefault and newCustom: a type or field is gone or a field changed type.
+// Views whose query does not resolve against the current schema are returned as skipped.
+func BreakingUsages(schemaStore store.SchemaStore, views viewstore.ViewStore, newDefault string, newCustom string) ([]SchemaUsage, []SkippedView, error) {
+	defaultSchema, customSchema, err := loadSchemaSource(schemaStore)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refs, names, skipped, err := viewRefs(defaultSchema+"\n\n"+customSchema, schemaStore.Profile(), views)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	doc, err := store.ParseDocument("schema.graphql", newDefault+"\n\n"+newCustom)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	var usages []SchemaUsage
+	for _, name := range names {
+		for _, ref := range refs[name] {
+			if !refResolves(doc.Definitions, ref) {
+				usages = append(usages, SchemaUsage{View: name, Path: ref.Path, Type: ref.Type, Field: ref.Field})
+			}
+		}
+	}
+	return usages, skipped, nil
+}
+
+func refResolves(defs ast.DefinitionList, ref schema.FieldRef) bool {
+	def := defs.ForName(ref.Type)
+	if def == nil {
+		return false
+	}
+	if ref.Field == "" {
+		return def.Kind == ast.Object
+	}
+	field := def.Fields.ForName(ref.Field)
+	return field != nil && field.Type.String() == ref.FieldType
+}
+
+// checkSchemaChange fails with a BreakingChangeError when replacing the custom schema with
+// newCustom would break a stored view, unless opts.Force is set.
+func checkSchemaChange(schemaStore store.SchemaStore, newCustom string, opts SchemaChangeOptions) error {
+	if opts.Views == nil || opts.Force {
+		return nil
+	}
+
+	defaultSchema, err := schemaStore.LoadDefault()
+	if err != nil {
+		return fmt.Errorf("failed to load default schema: %w", err)
+	}
+	usages, skipped, err := BreakingUsages(schemaStore, opts.Views, defaultSchema, newCustom)
+	if err != nil {
+		return err
+	}
+	if opts.Warn != nil {
+		for _, v := range skipped {
+			opts.Warn(v)
+		}
+	}
+	if len(usages) > 0 {
+		return &BreakingChangeError{Usages: usages}
+	}
+	return nil
+}
+
+func saveCustomChecked(schemaStore store.SchemaStore, newCustom string, opts SchemaChangeOptions) error {
+	if err := checkSchemaChange(schemaStore, newCustom, opts); err != nil {
+		return err
+	}
+	return schemaStore.SaveCustom(newCustom)
+}
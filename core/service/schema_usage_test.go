@@ -0,0 +1,134 @@
+package service_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/core/schema/store/fileschema"
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/shinzonetwork/view-creator/core/view/store/local"
+)
+
+func TestSchemaChangeImpact(t *testing.T) {
+	tempDir := t.TempDir()
+
+	viewStore, err := local.NewLocalStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create view store: %v", err)
+	}
+	schemaStore, err := fileschema.NewFileSchemaStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+
+	if err := service.AddCustomSchema(schemaStore, "type Token { address: String symbol: String log: Log }\n\ntype Pool { id: String }"); err != nil {
+		t.Fatalf("AddCustomSchema failed: %v", err)
+	}
+
+	if _, err := service.InitView("tokens", viewStore); err != nil {
+		t.Fatalf("InitView failed: %v", err)
+	}
+	if _, err := service.UpdateQuery("tokens", "Token { address log { address } }", viewStore, schemaStore, service.QueryOptions{}); err != nil {
+		t.Fatalf("UpdateQuery failed: %v", err)
+	}
+
+	usages, _, err := service.FindSchemaUsages(schemaStore, viewStore, "Token")
+	if err != nil {
+		t.Fatalf("FindSchemaUsages failed: %v", err)
+	}
+	var got []string
+	for _, u := range usages {
+		got = append(got, u.String())
+	}
+	want := "tokens: Token|tokens: Token.address (Token.address)|tokens: Token.log (Token.log)"
+	if strings.Join(got, "|") != want {
+		t.Errorf("unexpected usages:\n%s\nwant:\n%s", strings.Join(got, "|"), want)
+	}
+
+	logUsages, _, err := service.FindSchemaUsages(schemaStore, viewStore, "Log")
+	if err != nil || len(logUsages) != 2 {
+		t.Errorf("expected Token.log and Token.log.address to use Log, got %v (err: %v)", logUsages, err)
+	}
+
+	opts := service.SchemaChangeOptions{Views: viewStore}
+
+	// unused parts of the schema can change freely
+	if err := service.RemoveSchemaField(schemaStore, "Token", "symbol", opts); err != nil {
+		t.Errorf("removing an unused field failed: %v", err)
+	}
+	if err := service.RemoveCustomSchema(schemaStore, "Pool", opts); err != nil {
+		t.Errorf("removing an unused type failed: %v", err)
+	}
+
+	err = service.RemoveCustomSchema(schemaStore, "Token", opts)
+	var breaking *service.BreakingChangeError
+	if !errors.As(err, &breaking) || !errors.Is(err, service.ErrBreakingChange) {
+		t.Fatalf("expected a breaking change error, got %v", err)
+	}
+	if len(breaking.Usages) != 3 || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("unexpected breaking change report: %v", err)
+	}
+
+	if err := service.RenameSchemaField(schemaStore, "Token", "address", "contract", opts); !errors.Is(err, service.ErrBreakingChange) {
+		t.Errorf("expected renaming a used field to be refused, got %v", err)
+	}
+	if err := service.ReplaceCustomDefinition(schemaStore, "Token", "type Token { address: Int log: Log }", opts); !errors.Is(err, service.ErrBreakingChange) {
+		t.Errorf("expected changing a used field's type to be refused, got %v", err)
+	}
+	if err := service.ResetCustomSchemas(schemaStore, opts); !errors.Is(err, service.ErrBreakingChange) {
+		t.Errorf("expected reset to be refused, got %v", err)
+	}
+
+	opts.Force = true
+	if err := service.RemoveCustomSchema(schemaStore, "Token", opts); err != nil {
+		t.Fatalf("forced removal failed: %v", err)
+	}
+	if _, err := schemaStore.GetDefinition("Token"); err == nil {
+		t.Error("expected Token to be removed")
+	}
+}
+
+func TestSchemaChangeSkipsUnresolvedViews(t *testing.T) {
+	tempDir := t.TempDir()
+
+	viewStore, err := local.NewLocalStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create view store: %v", err)
+	}
+	schemaStore, err := fileschema.NewFileSchemaStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create schema store: %v", err)
+	}
+
+	if err := service.AddCustomSchema(schemaStore, "type Token { address: String }\n\ntype Pool { id: String }"); err != nil {
+		t.Fatalf("AddCustomSchema failed: %v", err)
+	}
+
+	// a view whose stored query no longer parses, such as one edited by hand
+	view, err := service.InitView("stale", viewStore)
+	if err != nil {
+		t.Fatalf("InitView failed: %v", err)
+	}
+	query := "Token { address"
+	view.Query = &query
+	if _, err := viewStore.Save("stale", view); err != nil {
+		t.Fatalf("failed to save view: %v", err)
+	}
+
+	var warned []string
+	opts := service.SchemaChangeOptions{Views: viewStore, Warn: func(v service.SkippedView) {
+		warned = append(warned, v.String())
+	}}
+	if err := service.RemoveCustomSchema(schemaStore, "Pool", opts); err != nil {
+		t.Fatalf("expected the change to go through despite the stale view: %v", err)
+	}
+	if len(warned) != 1 || !strings.HasPrefix(warned[0], "stale: ") {
+		t.Errorf("expected a warning for the stale view, got %v", warned)
+	}
+
+	_, skipped, err := service.FindSchemaUsages(schemaStore, viewStore, "Token")
+	if err != nil || len(skipped) != 1 || skipped[0].View != "stale" {
+		t.Errorf("expected the stale view to be skipped, got %v (err: %v)", skipped, err)
+	}
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/shinzonetwork/view-creator/core/models"
+	"github.com/shinzonetwork/view-creator/core/schema"
+	viewstore "github.com/shinzonetwork/view-creator/core/view/store"
+	"github.com/shinzonetwork/view-creator/core/wasm"
+)
+
+var ErrPayloadTooLarge = errors.New("view payload exceeds the maximum deploy size")
+
+type SizeEntry struct {
+	Part    string
+	Raw     int // bytes as stored in the view
+	Encoded int // bytes once inlined into the deploy payload (base64 for lenses)
+}
+
+type SizeReport struct {
+	Entries []SizeEntry
+	Payload int // total size of the deploy payload
+}
+
+// BuildDeployPayload returns the view blob that is registered on-chain, with every lens
+// inlined as base64. The blob is canonical: the query is normalized, the SDL formatted
+// without comments and object keys sorted, so the same view always yields the same bytes
+// and view ID.
+func BuildDeployPayload(name string, s viewstore.ViewStore) ([]byte, error) {
+	view, err := s.Load(name)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range view.Transform.Lenses {
+		lens := &view.Transform.Lenses[i]
+		blob, err := s.GetAssetBlob(name, lens.Label)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get blob for lens %q: %w", lens.Label, err)
+		}
+		lens.Path = blob
+		// sources, output shapes and digests are kept for local review and checks, and are
+		// not part of the registered view
+		lens.Source = ""
+		lens.Output = ""
+		lens.Digest = ""
+	}
+
+	if view.Query != nil {
+		query, err := normalizedQuery(view)
+		if err != nil {
+			return nil, err
+		}
+		view.Query = &query
+	}
+	if view.Sdl != nil {
+		sdl, err := canonicalSDL(view)
+		if err != nil {
+			return nil, err
+		}
+		view.Sdl = &sdl
+	}
+
+	viewLite := ViewLite{
+		Query:     view.Query,
+		Sdl:       view.Sdl,
+		Transform: view.Transform,
+	}
+
+	data, err := canonicalJSON(viewLite)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal view: %w", err)
+	}
+	return data, nil
+}
+
+// canonicalSDL returns the view SDL formatted without comments, or "" without one.
+func canonicalSDL(view models.View) (string, error) {
+	if view.Sdl == nil || *view.Sdl == "" {
+		return "", nil
+	}
+	sdl, err := schema.CanonicalSDL(*view.Sdl)
+	if err != nil {
+		return "", fmt.Errorf("failed to format SDL: %w", err)
+	}
+	return sdl, nil
+}
+
+// canonicalJSON encodes v with the keys of every object sorted and no insignificant
+// whitespace. Numbers keep their literal form.
+func canonicalJSON(v any) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var tree any
+	if err := dec.Decode(&tree); err != nil {
+		return nil, err
+	}
+	// maps are encoded with sorted keys
+	return json.Marshal(tree)
+}
+
+// CheckPayloadSize fails when data is larger than max bytes. A max of zero disables the check.
+func CheckPayloadSize(data []byte, max int) error {
+	if max > 0 && len(data) > max {
+		return fmt.Errorf("%w: %d bytes, limit is %d", ErrPayloadTooLarge, len(data), max)
+	}
+	return nil
+}
+
+// ViewSize breaks the deploy payload of a view down by query, SDL and lens.
+func ViewSize(name string, s viewstore.ViewStore) (SizeReport, error) {
+	view, err := s.Load(name)
+	if err != nil {
+		return SizeReport{}, err
+	}
+
+	query, err := normalizedQuery(view)
+	if err != nil {
+		return SizeReport{}, err
+	}
+	sdl, err := canonicalSDL(view)
+	if err != nil {
+		return SizeReport{}, err
+	}
+
+	var report SizeReport
+	report.Entries = append(report.Entries, textSize("query", query))
+	report.Entries = append(report.Entries, textSize("sdl", sdl))
+
+	for _, lens := range view.Transform.Lenses {
+		blob, err := s.GetAssetBlob(name, lens.Label)
+		if err != nil {
+			return SizeReport{}, fmt.Errorf("failed to get blob for lens %q: %w", lens.Label, err)
+		}
+		raw, err := base64.StdEncoding.DecodeString(blob)
+		if err != nil {
+			return SizeReport{}, fmt.Errorf("failed to decode lens %q: %w", lens.Label, err)
+		}
+		report.Entries = append(report.Entries, SizeEntry{
+			Part:    "lens " + lens.Label,
+			Raw:     len(raw),
+			Encoded: len(blob),
+		})
+	}
+
+	payload, err := BuildDeployPayload(name, s)
+	if err != nil {
+		return SizeReport{}, err
+	}
+	report.Payload = len(payload)
+
+	return report, nil
+}
+
+func textSize(part string, text string) SizeEntry {
+	encoded, _ := json.Marshal(text)
+	return SizeEntry{Part: part, Raw: len(text), Encoded: len(encoded) - 2}
+}
+
+// CheckLensPolicy verifies every lens of a view against the sandbox policy.
+func CheckLensPolicy(name string, s viewstore.ViewStore, policy wasm.Policy) error {
+	view, err := s.Load(name)
+	if err != nil {
+		return err
+	}
+
+	for _, lens := range view.Transform.Lenses {
+		encoded, err := s.GetAssetBlob(name, lens.Label)
+		if err != nil {
+			return fmt.Errorf("failed to get blob for lens %q: %w", lens.Label, err)
+		}
+		blob, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("failed to decode lens %q: %w", lens.Label, err)
+		}
+		if err := policy.Check(blob); err != nil {
+			return fmt.Errorf("lens %q rejected: %w", lens.Label, err)
+		}
+	}
+	return nil
+}
+
+// StripResult describes what StripLens changed in a lens asset.
+type StripResult struct {
+	Before  int      // asset size before stripping
+	After   int      // asset size after stripping
+	Removed []string // names of the removed sections
+	Path    string   // asset the lens now points to
+}
+
+// StripLens removes custom sections (names, debug info, producers) from a lens asset.
+//
+// The stripped module is stored next to the original as <label>.stripped.wasm and the view
+// is saved pointing to it, so the change is recorded as a revision. The original asset and
+// any text source are kept, and rolling back the revision restores both. The stripped lens
+// has no source, since its source would no longer assemble to it.
+func StripLens(name string, label string, s viewstore.ViewStore) (StripResult, error) {
+	view, err := s.Load(name)
+	if err != nil {
+		return StripResult{}, err
+	}
+
+	var lens *models.Lens
+	for i := range view.Transform.Lenses {
+		if view.Transform.Lenses[i].Label == label {
+			lens = &view.Transform.Lenses[i]
+			break
+		}
+	}
+	if lens == nil {
+		return StripResult{}, fmt.Errorf("lens with label %q not found", label)
+	}
+
+	encoded, err := s.GetAssetBlob(name, label)
+	if err != nil {
+		return StripResult{}, err
+	}
+
+	blob, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return StripResult{}, fmt.Errorf("failed to decode lens %q: %w", label, err)
+	}
+
+	stripped, removed, err := wasm.Strip(blob)
+	if err != nil {
+		return StripResult{}, fmt.Errorf("failed to strip lens %q: %w", label, err)
+	}
+
+	result := StripResult{Before: len(blob), After: len(stripped), Removed: removed, Path: lens.Path}
+	if len(removed) == 0 {
+		return result, nil
+	}
+
+	if _, err := s.UploadAsset(name, label+".stripped", bytes.NewReader(stripped)); err != nil {
+		return StripResult{}, fmt.Errorf("failed to upload asset: %w", err)
+	}
+
+	lens.Path = fmt.Sprintf("assets/%s.stripped.wasm", label)
+	lens.Source = ""
+	lens.Digest = assetDigest(stripped)
+	if _, err := s.Save(name, view); err != nil {
+		return StripResult{}, err
+	}
+	result.Path = lens.Path
+	return result, nil
+}
+
+// assetDigest returns the sha256 of a lens asset as stored in the view.
+func assetDigest(blob []byte) string {
+	sum := sha256.Sum256(blob)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
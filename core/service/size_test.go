@@ -0,0 +1,124 @@
+package service_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/core/service"
+	"github.com/shinzonetwork/view-creator/core/view/store/local"
+)
+
+func TestViewSizeAndStrip(t *testing.T) {
+	tempDir := t.TempDir()
+
+	viewStore, err := local.NewLocalStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create view store: %v", err)
+	}
+
+	name := "sized"
+	if _, err := service.InitView(name, viewStore); err != nil {
+		t.Fatalf("InitView failed: %v", err)
+	}
+
+	watPath := filepath.Join(tempDir, "noop.wat")
+	if err := os.WriteFile(watPath, []byte(`(module $noop (func $transform (export "transform")))`), 0644); err != nil {
+		t.Fatalf("failed to write wat: %v", err)
+	}
+	if _, err := service.InitLens(name, "noop", watPath, nil, viewStore, service.LensOptions{}); err != nil {
+		t.Fatalf("InitLens failed: %v", err)
+	}
+
+	report, err := service.ViewSize(name, viewStore)
+	if err != nil {
+		t.Fatalf("ViewSize failed: %v", err)
+	}
+	if len(report.Entries) != 3 || report.Entries[2].Part != "lens noop" {
+		t.Fatalf("expected query, sdl and lens entries, got %+v", report.Entries)
+	}
+	lens := report.Entries[2]
+	if lens.Encoded != (lens.Raw+2)/3*4 {
+		t.Errorf("expected base64 size of %d raw bytes, got %d", lens.Raw, lens.Encoded)
+	}
+
+	payload, err := service.BuildDeployPayload(name, viewStore)
+	if err != nil {
+		t.Fatalf("BuildDeployPayload failed: %v", err)
+	}
+	if report.Payload != len(payload) {
+		t.Errorf("expected payload size %d, got %d", len(payload), report.Payload)
+	}
+
+	var decoded service.ViewLite
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		t.Fatalf("payload is not valid JSON: %v", err)
+	}
+	if decoded.Transform.Lenses[0].Source != "" {
+		t.Errorf("expected lens source to be left out of the payload")
+	}
+
+	if err := service.CheckPayloadSize(payload, len(payload)-1); !errors.Is(err, service.ErrPayloadTooLarge) {
+		t.Errorf("expected ErrPayloadTooLarge, got %v", err)
+	}
+	if err := service.CheckPayloadSize(payload, 0); err != nil {
+		t.Errorf("expected a zero limit to disable the check, got %v", err)
+	}
+
+	view, err := viewStore.Load(name)
+	if err != nil {
+		t.Fatalf("failed to load view: %v", err)
+	}
+	total, version := view.Metadata.Total, view.Metadata.Version
+
+	result, err := service.StripLens(name, "noop", viewStore)
+	if err != nil {
+		t.Fatalf("StripLens failed: %v", err)
+	}
+	after := result.After
+	if result.Before != lens.Raw || after >= result.Before || len(result.Removed) != 1 || result.Path != "assets/noop.stripped.wasm" {
+		t.Errorf("unexpected strip result: %+v", result)
+	}
+
+	view, err = viewStore.Load(name)
+	if err != nil {
+		t.Fatalf("failed to load view: %v", err)
+	}
+	if view.Metadata.Total != total+1 {
+		t.Errorf("expected strip to record a revision, total went from %d to %d", total, view.Metadata.Total)
+	}
+	stripped := view.Transform.Lenses[0]
+	if stripped.Path != result.Path || stripped.Source != "" || stripped.Digest == "" {
+		t.Errorf("expected the lens to point to the stripped asset without a source, got %+v", stripped)
+	}
+	for _, kept := range []string{"noop.wat", "noop.wasm"} {
+		if _, err := os.Stat(filepath.Join(viewStore.BasePath, name, "assets", kept)); err != nil {
+			t.Errorf("expected %s to be kept: %v", kept, err)
+		}
+	}
+
+	report, err = service.ViewSize(name, viewStore)
+	if err != nil {
+		t.Fatalf("ViewSize failed: %v", err)
+	}
+	if report.Entries[2].Raw != after {
+		t.Errorf("expected stripped asset of %d bytes, got %d", after, report.Entries[2].Raw)
+	}
+
+	// rolling back restores the original asset and its source
+	if _, err := viewStore.Rollback(name, version); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	report, err = service.ViewSize(name, viewStore)
+	if err != nil {
+		t.Fatalf("ViewSize failed: %v", err)
+	}
+	if report.Entries[2].Raw != result.Before {
+		t.Errorf("expected the original asset of %d bytes after rollback, got %d", result.Before, report.Entries[2].Raw)
+	}
+	if _, err := viewStore.GetAssetSource(name, "noop"); err != nil {
+		t.Errorf("expected the source to be restored: %v", err)
+	}
+}
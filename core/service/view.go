@@ -2,19 +2,21 @@ package service
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/shinzonetwork/view-creator/core/cache"
 	"github.com/shinzonetwork/view-creator/core/models"
 	"github.com/shinzonetwork/view-creator/core/schema"
 	schemastore "github.com/shinzonetwork/view-creator/core/schema/store"
 	"github.com/shinzonetwork/view-creator/core/util"
 	viewstore "github.com/shinzonetwork/view-creator/core/view/store"
+	"github.com/shinzonetwork/view-creator/core/wasm"
 )
 
 func InitView(name string, s viewstore.ViewStore) (models.View, error) {
@@ -29,17 +31,43 @@ func DeleteView(name string, s viewstore.ViewStore) error {
 	return s.Delete(name)
 }
 
-func UpdateQuery(name string, query string, viewstore viewstore.ViewStore, schemastore schemastore.SchemaStore) (models.View, error) {
+// QueryOptions controls what UpdateQuery records about the schema.
+type QueryOptions struct {
+	// Snapshot embeds the SDL of the types the query uses alongside the schema fingerprint.
+	Snapshot bool
+}
+
+// UpdateQuery validates the query against the current schema of the view's profile and pins
+// the view to that schema.
+func UpdateQuery(name string, query string, viewstore viewstore.ViewStore, schemastore schemastore.SchemaStore, opts QueryOptions) (models.View, error) {
 	view, err := viewstore.Load(name)
 	if err != nil {
 		return models.View{}, err
 	}
 
+	schemastore, err = ViewSchemaStore(view, schemastore)
+	if err != nil {
+		return models.View{}, err
+	}
+
 	if err := schema.ValidateQuery(schemastore, query); err != nil {
 		return models.View{}, err
 	}
 
-	view.Query = &query
+	pin, err := pinSchema(schemastore, query, opts.Snapshot)
+	if err != nil {
+		return models.View{}, err
+	}
+
+	// a change in whitespace only keeps the stored text and, while the schema is the same,
+	// the stored pin, so it adds no revision
+	unchanged := view.Query != nil && sameFormatted(*view.Query, query, schema.FormatQuery)
+	if !unchanged {
+		view.Query = &query
+	}
+	if !unchanged || view.Schema == nil || view.Schema.Fingerprint != pin.Fingerprint || view.Schema.Snapshot != pin.Snapshot {
+		view.Schema = &pin
+	}
 
 	view, err = viewstore.Save(name, view)
 	if err != nil {
@@ -49,6 +77,51 @@ func UpdateQuery(name string, query string, viewstore viewstore.ViewStore, schem
 	return view, nil
 }
 
+// BuildViewQuery builds a query from field paths against the schema of the view's profile.
+func BuildViewQuery(name string, spec schema.QuerySpec, viewstore viewstore.ViewStore, schemastore schemastore.SchemaStore) (string, error) {
+	view, err := viewstore.Load(name)
+	if err != nil {
+		return "", err
+	}
+
+	schemastore, err = ViewSchemaStore(view, schemastore)
+	if err != nil {
+		return "", err
+	}
+
+	defs, err := schemastore.Definitions()
+	if err != nil {
+		return "", err
+	}
+	return schema.BuildQuery(defs, spec)
+}
+
+// InferViewSDL derives an SDL type from the view's query. Without a type name, the type is
+// named after the view.
+func InferViewSDL(name string, opts schema.InferOptions, viewstore viewstore.ViewStore, schemastore schemastore.SchemaStore) (string, error) {
+	view, err := viewstore.Load(name)
+	if err != nil {
+		return "", err
+	}
+	if view.Query == nil || *view.Query == "" {
+		return "", fmt.Errorf("view %s has no query to infer an SDL from", name)
+	}
+
+	schemastore, err = ViewSchemaStore(view, schemastore)
+	if err != nil {
+		return "", err
+	}
+	source, err := schemastore.Load()
+	if err != nil {
+		return "", err
+	}
+
+	if opts.TypeName == "" {
+		opts.TypeName = schema.TypeNameFor(name)
+	}
+	return schema.InferSDL(source, *view.Query, opts)
+}
+
 func UpdateSDL(name string, sdl string, s viewstore.ViewStore) (models.View, error) {
 	view, err := s.Load(name)
 	if err != nil {
@@ -59,7 +132,9 @@ func UpdateSDL(name string, sdl string, s viewstore.ViewStore) (models.View, err
 		return models.View{}, err
 	}
 
-	view.Sdl = &sdl
+	if view.Sdl == nil || !sameFormatted(*view.Sdl, sdl, schema.FormatSDL) {
+		view.Sdl = &sdl
+	}
 
 	view, err = s.Save(name, view)
 	if err != nil {
@@ -87,11 +162,25 @@ func ClearQuery(name string, s viewstore.ViewStore) (models.View, error) {
 	}
 
 	view.Query = nil
+	view.Schema = nil
 
 	return s.Save(name, view)
 }
 
-func InitLens(name string, label string, path string, args map[string]any, s viewstore.ViewStore) (models.View, error) {
+// LensOptions controls how InitLens resolves the wasm for a new lens.
+type LensOptions struct {
+	// Downloads serves remote lenses. A cache in the default location is used when nil.
+	Downloads *cache.DownloadCache
+	// SHA256 pins the expected digest of the wasm.
+	SHA256 string
+	// Policy is the sandbox policy the module must satisfy. The default policy is used when nil.
+	Policy *wasm.Policy
+	// Output declares the fields the lens produces, such as "hash: String, blockNumber: Int",
+	// so view check can compare them with the SDL.
+	Output string
+}
+
+func InitLens(name string, label string, path string, args map[string]any, s viewstore.ViewStore, opts LensOptions) (models.View, error) {
 	view, err := s.Load(name)
 	if err != nil {
 		return models.View{}, err
@@ -103,27 +192,47 @@ func InitLens(name string, label string, path string, args map[string]any, s vie
 		}
 	}
 
-	// Read file into memory
-	var wasmBytes []byte
-	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
-		resp, err := http.Get(path)
-		if err != nil {
-			return models.View{}, fmt.Errorf("failed to download from URL: %w", err)
+	if opts.Output != "" {
+		if _, err := schema.ParseOutput(opts.Output); err != nil {
+			return models.View{}, fmt.Errorf("lens %q: %w", label, err)
 		}
-		defer resp.Body.Close()
+	}
 
-		if resp.StatusCode != http.StatusOK {
-			return models.View{}, fmt.Errorf("download failed with HTTP %d", resp.StatusCode)
+	// Resolve remote lenses through the download cache
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		downloads := opts.Downloads
+		if downloads == nil {
+			downloads, err = cache.NewDownloadCache(cache.DefaultOptions())
+			if err != nil {
+				return models.View{}, err
+			}
 		}
 
-		wasmBytes, err = io.ReadAll(resp.Body)
+		path, err = downloads.Fetch(path, opts.SHA256)
 		if err != nil {
-			return models.View{}, fmt.Errorf("failed to read wasm from response: %w", err)
+			return models.View{}, fmt.Errorf("failed to download lens: %w", err)
+		}
+	}
+
+	wasmBytes, err := os.ReadFile(path)
+	if err != nil {
+		return models.View{}, fmt.Errorf("failed to read local wasm file: %w", err)
+	}
+
+	if opts.SHA256 != "" {
+		sum := sha256.Sum256(wasmBytes)
+		if !strings.EqualFold(hex.EncodeToString(sum[:]), strings.TrimSpace(opts.SHA256)) {
+			return models.View{}, fmt.Errorf("wasm sha256 mismatch: expected %s, got %x", opts.SHA256, sum)
 		}
-	} else {
-		wasmBytes, err = os.ReadFile(path)
+	}
+
+	// Assemble lenses written in WebAssembly text format, keeping the source for review
+	var source []byte
+	if wasm.IsWat(wasmBytes) {
+		source = wasmBytes
+		wasmBytes, err = wasm.Assemble(source)
 		if err != nil {
-			return models.View{}, fmt.Errorf("failed to read local wasm file: %w", err)
+			return models.View{}, fmt.Errorf("failed to assemble wat file: %w", err)
 		}
 	}
 
@@ -132,6 +241,14 @@ func InitLens(name string, label string, path string, args map[string]any, s vie
 		return models.View{}, fmt.Errorf("invalid wasm file: %w", err)
 	}
 
+	policy := wasm.DefaultPolicy()
+	if opts.Policy != nil {
+		policy = *opts.Policy
+	}
+	if err := policy.Check(wasmBytes); err != nil {
+		return models.View{}, fmt.Errorf("lens %q rejected: %w", label, err)
+	}
+
 	// Upload asset
 	if _, err := s.UploadAsset(name, label, bytes.NewReader(wasmBytes)); err != nil {
 		return models.View{}, fmt.Errorf("failed to upload asset: %w", err)
@@ -142,6 +259,15 @@ func InitLens(name string, label string, path string, args map[string]any, s vie
 		Label:     label,
 		Arguments: args,
 		Path:      fmt.Sprintf("assets/%s.wasm", label),
+		Output:    opts.Output,
+		Digest:    assetDigest(wasmBytes),
+	}
+
+	if source != nil {
+		if _, err := s.UploadAssetSource(name, label, bytes.NewReader(source)); err != nil {
+			return models.View{}, fmt.Errorf("failed to upload asset source: %w", err)
+		}
+		newLens.Source = fmt.Sprintf("assets/%s.wat", label)
 	}
 	view.Transform.Lenses = append(view.Transform.Lenses, newLens)
 
@@ -183,3 +309,13 @@ func RemoveLens(name string, label string, s viewstore.ViewStore) (models.View,
 func Rollback(name string, version int, s viewstore.ViewStore) (models.View, error) {
 	return s.Rollback(name, version)
 }
+
+// sameFormatted reports whether two texts format the same, i.e. differ only cosmetically.
+func sameFormatted(a string, b string, format func(string) (string, error)) bool {
+	fa, err := format(a)
+	if err != nil {
+		return false
+	}
+	fb, err := format(b)
+	return err == nil && fa == fb
+}
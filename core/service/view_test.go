@@ -9,6 +9,7 @@ import (
 	"github.com/shinzonetwork/view-creator/core/schema/store/fileschema"
 	"github.com/shinzonetwork/view-creator/core/service"
 	"github.com/shinzonetwork/view-creator/core/view/store/local"
+	"github.com/shinzonetwork/view-creator/core/wasm"
 )
 
 func TestViewService_CRUD(t *testing.T) {
@@ -43,7 +44,7 @@ func TestViewService_CRUD(t *testing.T) {
 		t.Fatalf("failed to write test schema: %v", err)
 	}
 
-	view, err = service.UpdateQuery(name, query, viewStore, schemaStore)
+	view, err = service.UpdateQuery(name, query, viewStore, schemaStore, service.QueryOptions{})
 	if err != nil {
 		t.Fatalf("UpdateQuery failed: %v", err)
 	}
@@ -51,6 +52,21 @@ func TestViewService_CRUD(t *testing.T) {
 		t.Errorf("expected query to contain 'address', got %v", view.Query)
 	}
 
+	// pin an old validation time, so a new pin would always differ from the stored one
+	view.Schema.ValidatedAt = "0"
+	if view, err = viewStore.Save(name, view); err != nil {
+		t.Fatalf("failed to save view: %v", err)
+	}
+	total := view.Metadata.Total
+
+	view, err = service.UpdateQuery(name, "TempLog {\n  address\n}", viewStore, schemaStore, service.QueryOptions{})
+	if err != nil {
+		t.Fatalf("UpdateQuery failed: %v", err)
+	}
+	if view.Metadata.Total != total || view.Schema.ValidatedAt != "0" {
+		t.Errorf("expected a whitespace-only change to keep the query and pin, total went from %d to %d, pin %+v", total, view.Metadata.Total, view.Schema)
+	}
+
 	sdl := "type Something @materialized(if: false) { x: String }"
 	view, err = service.UpdateSDL(name, sdl, viewStore)
 	if err != nil {
@@ -104,7 +120,7 @@ func TestViewService_LensLifecycle(t *testing.T) {
 		t.Fatalf("failed to write valid wasm file: %v", err)
 	}
 
-	view, err := service.InitLens(name, "testlens", wasmPath, map[string]any{"arg": "val"}, viewStore)
+	view, err := service.InitLens(name, "testlens", wasmPath, map[string]any{"arg": "val"}, viewStore, service.LensOptions{})
 	if err != nil {
 		t.Fatalf("InitLens failed: %v", err)
 	}
@@ -120,3 +136,38 @@ func TestViewService_LensLifecycle(t *testing.T) {
 		t.Error("lens was not removed properly")
 	}
 }
+
+func TestInitLensEnforcesPolicy(t *testing.T) {
+	tempDir := t.TempDir()
+
+	viewStore, err := local.NewLocalStore(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create view store: %v", err)
+	}
+
+	name := "sandboxed"
+	if _, err := service.InitView(name, viewStore); err != nil {
+		t.Fatalf("InitView failed: %v", err)
+	}
+
+	watPath := filepath.Join(tempDir, "reader.wat")
+	src := `(module (import "wasi_snapshot_preview1" "fd_read" (func (param i32 i32 i32 i32) (result i32))))`
+	if err := os.WriteFile(watPath, []byte(src), 0644); err != nil {
+		t.Fatalf("failed to write wat: %v", err)
+	}
+
+	_, err = service.InitLens(name, "reader", watPath, nil, viewStore, service.LensOptions{})
+	if err == nil || !strings.Contains(err.Error(), "wasi_snapshot_preview1.fd_read is not allowed") {
+		t.Fatalf("expected the WASI import to be rejected, got: %v", err)
+	}
+
+	policy := wasm.DefaultPolicy()
+	policy.AllowedImports = append(policy.AllowedImports, "wasi_snapshot_preview1.fd_read")
+	if _, err := service.InitLens(name, "reader", watPath, nil, viewStore, service.LensOptions{Policy: &policy}); err != nil {
+		t.Fatalf("expected a custom policy to allow the import, got: %v", err)
+	}
+
+	if err := service.CheckLensPolicy(name, viewStore, wasm.DefaultPolicy()); err == nil {
+		t.Error("expected deploy-time check to reject the lens under the default policy")
+	}
+}
@@ -0,0 +1,222 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// DefraVersion is the DefraDB release views are tested and deployed against.
+const DefraVersion = "0.18.0"
+
+// defraPrelude holds, per DefraDB version, the scalars, enums, inputs and directives a node
+// declares for collection SDL. Each entry is one named definition, so that names an SDL
+// declares itself can be left out.
+var defraPrelude = map[string][]string{
+	"0.18.0": {
+		"scalar DateTime",
+		"scalar JSON",
+		"scalar Blob",
+		"scalar Float32",
+		"scalar Float64",
+		"enum Ordering { ASC DESC }",
+		"enum CRDTType { lww pcounter pncounter }",
+		"input IndexFieldInput { field: String, direction: Ordering }",
+		"directive @index(name: String, unique: Boolean, direction: Ordering, includes: [IndexFieldInput]) on OBJECT | FIELD_DEFINITION",
+		"directive @relation(name: String) on FIELD_DEFINITION",
+		"directive @primary on FIELD_DEFINITION",
+		"directive @default(bool: Boolean, int: Int, float: Float, float32: Float32, float64: Float64, dateTime: DateTime, string: String, json: JSON, blob: Blob) on FIELD_DEFINITION",
+		"directive @crdt(type: CRDTType) on FIELD_DEFINITION",
+		"directive @policy(id: String, resource: String) on OBJECT",
+		"directive @materialized(if: Boolean) on OBJECT",
+		"directive @branchable(if: Boolean) on OBJECT",
+	},
+}
+
+// DefraVersions returns the DefraDB versions SDL can be validated against.
+func DefraVersions() []string {
+	versions := make([]string, 0, len(defraPrelude))
+	for v := range defraPrelude {
+		versions = append(versions, v)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// IsDefraType reports whether name is a scalar, enum or input type DefraDB declares, such
+// as JSON or DateTime.
+func IsDefraType(name string) bool {
+	for _, entry := range defraPrelude[DefraVersion] {
+		fields := strings.Fields(entry)
+		if fields[0] != "directive" && fields[1] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// defraPreludeFor returns the prelude of version, without the definitions doc declares.
+func defraPreludeFor(version string, doc *ast.SchemaDocument) (string, error) {
+	entries, ok := defraPrelude[version]
+	if !ok {
+		return "", fmt.Errorf("no directive set for DefraDB %s. Known versions: %s", version, strings.Join(DefraVersions(), ", "))
+	}
+
+	var kept []string
+	for _, entry := range entries {
+		fields := strings.Fields(entry)
+		if fields[0] != "directive" && doc.Definitions.ForName(fields[1]) != nil {
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	return strings.Join(kept, "\n"), nil
+}
+
+// checkViewConstructs rejects the parts of SDL that DefraDB does not accept for views.
+func checkViewConstructs(doc *ast.SchemaDocument) error {
+	if len(doc.Schema) > 0 || len(doc.SchemaExtension) > 0 {
+		return fmt.Errorf("schema definitions are not supported in view SDL")
+	}
+	if len(doc.Directives) > 0 {
+		return fmt.Errorf("directive @%s: view SDL cannot declare directives; DefraDB provides them", doc.Directives[0].Name)
+	}
+	if len(doc.Extensions) > 0 {
+		return fmt.Errorf("extend %s: type extensions are not supported in view SDL", doc.Extensions[0].Name)
+	}
+
+	for _, def := range doc.Definitions {
+		switch def.Kind {
+		case ast.Interface, ast.Union, ast.InputObject:
+			return fmt.Errorf("%s %s: %s types are not supported in view SDL", strings.ToLower(string(def.Kind)), def.Name, strings.ToLower(string(def.Kind)))
+		}
+		for _, field := range def.Fields {
+			if len(field.Arguments) > 0 {
+				return fmt.Errorf("%s.%s: field arguments are not supported in view SDL", def.Name, field.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// checkDirectiveUses verifies the argument values of every directive in doc, and that
+// directives which are not repeatable appear once per location.
+func checkDirectiveUses(schema *ast.Schema, doc *ast.SchemaDocument) error {
+	for _, def := range doc.Definitions {
+		if err := checkDirectiveList(schema, def.Directives, def.Name); err != nil {
+			return err
+		}
+		for _, field := range def.Fields {
+			if err := checkDirectiveList(schema, field.Directives, def.Name+"."+field.Name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkDirectiveList(schema *ast.Schema, list ast.DirectiveList, at string) error {
+	seen := map[string]bool{}
+	for _, d := range list {
+		def := schema.Directives[d.Name]
+		if def == nil {
+			return fmt.Errorf("%s: unknown directive @%s", at, d.Name)
+		}
+		if seen[d.Name] && !def.IsRepeatable {
+			return fmt.Errorf("%s: @%s can only be used once", at, d.Name)
+		}
+		seen[d.Name] = true
+
+		for _, arg := range d.Arguments {
+			argDef := def.Arguments.ForName(arg.Name)
+			if argDef == nil {
+				return fmt.Errorf("%s: @%s has no argument %s", at, d.Name, arg.Name)
+			}
+			if err := checkValue(schema, arg.Value, argDef.Type); err != nil {
+				return fmt.Errorf("%s: argument %s of @%s: %w", at, arg.Name, d.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// checkValue checks a literal against the type of the argument it is passed to.
+func checkValue(schema *ast.Schema, v *ast.Value, t *ast.Type) error {
+	switch v.Kind {
+	case ast.Variable:
+		return fmt.Errorf("variables are not allowed in SDL")
+	case ast.NullValue:
+		if t.NonNull {
+			return fmt.Errorf("expected %s, got null", t.String())
+		}
+		return nil
+	}
+
+	if t.Elem != nil {
+		if v.Kind != ast.ListValue {
+			// a single value is accepted where a list is expected
+			return checkValue(schema, v, t.Elem)
+		}
+		for _, child := range v.Children {
+			if err := checkValue(schema, child.Value, t.Elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	def := schema.Types[t.NamedType]
+	if def == nil {
+		return fmt.Errorf("unknown type %s", t.NamedType)
+	}
+
+	mismatch := fmt.Errorf("expected %s, got %s", t.NamedType, v.String())
+	switch def.Kind {
+	case ast.Enum:
+		if v.Kind != ast.EnumValue || def.EnumValues.ForName(v.Raw) == nil {
+			return mismatch
+		}
+	case ast.InputObject:
+		if v.Kind != ast.ObjectValue {
+			return mismatch
+		}
+		for _, child := range v.Children {
+			field := def.Fields.ForName(child.Name)
+			if field == nil {
+				return fmt.Errorf("%s has no field %s", def.Name, child.Name)
+			}
+			if err := checkValue(schema, child.Value, field.Type); err != nil {
+				return err
+			}
+		}
+	case ast.Scalar:
+		var ok bool
+		switch def.Name {
+		case "Int":
+			ok = v.Kind == ast.IntValue
+		case "Float", "Float32", "Float64":
+			ok = v.Kind == ast.IntValue || v.Kind == ast.FloatValue
+		case "Boolean":
+			ok = v.Kind == ast.BooleanValue
+		case "ID":
+			ok = v.Kind == ast.StringValue || v.Kind == ast.IntValue
+		case "String", "DateTime", "Blob":
+			ok = v.Kind == ast.StringValue || v.Kind == ast.BlockValue
+		default:
+			// JSON and custom scalars take any literal
+			ok = true
+		}
+		if !ok {
+			return mismatch
+		}
+	}
+	return nil
+}
+
+// parseSDL parses sdl without validating it, so its constructs can be inspected first.
+func parseSDL(sdl string) (*ast.SchemaDocument, error) {
+	return parser.ParseSchema(&ast.Source{Name: "sdl.graphql", Input: sdl})
+}
@@ -9,37 +9,53 @@ import (
 	"github.com/vektah/gqlparser/v2/parser"
 )
 
+// ValidateSDL validates a view SDL against the directives of DefraVersion.
 func ValidateSDL(sdl string) error {
-	directives := `
-directive @materialized(if: Boolean) on OBJECT
-`
-	sdlWithDirectives := directives + "\n" + sdl
+	return ValidateSDLFor(sdl, DefraVersion)
+}
 
-	schema, err := gqlparser.LoadSchema(&ast.Source{
-		Name:  "sdl.graphql",
-		Input: sdlWithDirectives,
-	})
+// ValidateSDLFor validates a view SDL against the scalars and directives the given DefraDB
+// version declares: directives must be placed where DefraDB allows them and their arguments
+// must have the declared types. Constructs DefraDB does not accept for views, such as
+// interfaces or field arguments, are rejected.
+func ValidateSDLFor(sdl string, version string) error {
+	doc, err := parseSDL(sdl)
 	if err != nil {
 		return fmt.Errorf("invalid SDL: %w", err)
 	}
+	if err := checkViewConstructs(doc); err != nil {
+		return fmt.Errorf("invalid SDL: %w", err)
+	}
+
+	prelude, err := defraPreludeFor(version, doc)
+	if err != nil {
+		return err
+	}
+
+	schema, err := gqlparser.LoadSchema(
+		&ast.Source{Name: "defradb.graphql", Input: prelude, BuiltIn: true},
+		&ast.Source{Name: "sdl.graphql", Input: sdl},
+	)
+	if err != nil {
+		return fmt.Errorf("invalid SDL: %w", err)
+	}
+	if err := checkDirectiveUses(schema, doc); err != nil {
+		return fmt.Errorf("invalid SDL: %w", err)
+	}
 
 	definedTypes := make(map[string]bool)
 	for typeName := range schema.Types {
 		definedTypes[typeName] = true
 	}
 
-	builtins := map[string]bool{
-		"Int": true, "Float": true, "String": true, "Boolean": true, "ID": true,
-	}
-
 	for _, def := range schema.Types {
-		if strings.HasPrefix(def.Name, "__") || builtins[def.Name] {
+		if strings.HasPrefix(def.Name, "__") || IsBuiltinScalar(def.Name) || def.BuiltIn {
 			continue
 		}
 
 		for _, field := range def.Fields {
-			baseType := unwrapType(field.Type)
-			if !builtins[baseType] && !definedTypes[baseType] {
+			baseType := UnwrapType(field.Type)
+			if !IsBuiltinScalar(baseType) && !definedTypes[baseType] {
 				return fmt.Errorf("undefined type used in SDL: %s (in %s.%s)", baseType, def.Name, field.Name)
 			}
 		}
@@ -61,7 +77,17 @@ func ValidateSchemaBlock(schema string) error {
 	return nil
 }
 
-func unwrapType(t *ast.Type) string {
+var builtinScalars = map[string]bool{
+	"Int": true, "Float": true, "String": true, "Boolean": true, "ID": true,
+}
+
+// IsBuiltinScalar reports whether name is one of the scalars GraphQL itself defines.
+func IsBuiltinScalar(name string) bool {
+	return builtinScalars[name]
+}
+
+// UnwrapType returns the named type under any list and non-null wrappers of t.
+func UnwrapType(t *ast.Type) string {
 	if t == nil {
 		return ""
 	}
@@ -0,0 +1,55 @@
+package util_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/core/util"
+)
+
+func TestValidateSDLDefraDirectives(t *testing.T) {
+	valid := []string{
+		`type Logs @materialized(if: false) { hash: String }`,
+		`type Logs @materialized(if: true) @policy(id: "abc", resource: "logs") {
+  hash: String @index(unique: true)
+  block: Block @relation(name: "block_logs") @primary
+  count: Int @default(int: 0) @crdt(type: pcounter)
+  at: DateTime @default(dateTime: "2024-01-01T00:00:00Z")
+}
+
+type Block @index(includes: [{field: "number", direction: DESC}]) { number: Int logs: [Logs] }`,
+		// an SDL may declare a scalar or enum DefraDB also provides
+		`scalar DateTime
+
+type Logs { at: DateTime }`,
+	}
+	for _, sdl := range valid {
+		if err := util.ValidateSDL(sdl); err != nil {
+			t.Errorf("expected SDL to be valid, got %v\n%s", err, sdl)
+		}
+	}
+
+	invalid := map[string]string{
+		`type Logs @materialized(if: "yes") { hash: String }`:                      `argument if of @materialized: expected Boolean, got "yes"`,
+		`type Logs { hash: String @materialized(if: true) }`:                       "not applicable on FIELD_DEFINITION",
+		`type Logs @materialized(if: true) @materialized(if: false) { a: String }`: "@materialized can only be used once",
+		`type Logs { n: Int @crdt(type: counter) }`:                                "expected CRDTType, got counter",
+		`type Logs { n: Int @default(int: "1") }`:                                  "expected Int",
+		`type Logs @index(includes: [{name: "a"}]) { a: String }`:                  "IndexFieldInput has no field name",
+		`type Logs { a: String @unknown }`:                                         "Undefined directive unknown",
+		`interface Node { id: ID } type Logs { a: String }`:                        "interface types are not supported",
+		`type Logs { a(first: Int): String }`:                                      "Logs.a: field arguments are not supported",
+		`directive @x on OBJECT type Logs @x { a: String }`:                        "view SDL cannot declare directives",
+		`type Logs { a: String } extend type Logs { b: String }`:                   "type extensions are not supported",
+		`type Logs { a: Missing }`:                                                 "Missing",
+	}
+	for sdl, want := range invalid {
+		if err := util.ValidateSDL(sdl); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q to fail with %q, got %v", sdl, want, err)
+		}
+	}
+
+	if err := util.ValidateSDLFor(`type Logs { a: String }`, "0.1.0"); err == nil || !strings.Contains(err.Error(), "no directive set for DefraDB 0.1.0") {
+		t.Errorf("expected an unknown version to be rejected, got %v", err)
+	}
+}
@@ -35,9 +35,15 @@ type ViewStore interface {
 	// Uploads an asset (e.g. .wasm) to a view, identified by label.
 	UploadAsset(viewName string, label string, file io.Reader) (string, error)
 
-	// Deletes an asset by view name and label.
+	// Uploads the text source an asset was built from (e.g. .wat), identified by label.
+	UploadAssetSource(viewName string, label string, file io.Reader) (string, error)
+
+	// Deletes an asset, and its source if any, by view name and label.
 	DeleteAsset(viewName string, label string) error
 
+	// Returns the text source of the lens with the given label.
+	GetAssetSource(viewName string, label string) (string, error)
+
 	GetAssetBlob(viewName string, label string) (string, error)
 
 	// Revert the view back to a previous version
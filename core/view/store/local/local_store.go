@@ -235,17 +235,63 @@ func (s *LocalStore) UploadAsset(name string, label string, file io.Reader) (str
 func (s *LocalStore) DeleteAsset(viewName string, label string) error {
 	folderBasePath := filepath.Join(s.BasePath, viewName)
 	assetFolderPath := filepath.Join(folderBasePath, "assets")
-	assetPath := filepath.Join(assetFolderPath, fmt.Sprintf("%s.wasm", label))
 
-	if err := os.Remove(assetPath); err != nil {
-		if os.IsNotExist(err) {
-			return nil // already deleted or never existed
+	for _, ext := range []string{"wasm", "stripped.wasm", "wat"} {
+		assetPath := filepath.Join(assetFolderPath, fmt.Sprintf("%s.%s", label, ext))
+		if err := os.Remove(assetPath); err != nil {
+			if os.IsNotExist(err) {
+				continue // already deleted or never existed
+			}
+			return fmt.Errorf("failed to delete asset: %w", err)
 		}
-		return fmt.Errorf("failed to delete asset: %w", err)
 	}
 	return nil
 }
 
+func (s *LocalStore) UploadAssetSource(name string, label string, file io.Reader) (string, error) {
+	folderBasePath := filepath.Join(s.BasePath, name)
+	assetFolderPath := filepath.Join(folderBasePath, "assets")
+
+	sourcePath := filepath.Join(assetFolderPath, fmt.Sprintf("%s.wat", label))
+	outFile, err := os.Create(sourcePath)
+	if err != nil {
+		return "", err
+	}
+	defer outFile.Close()
+
+	if _, err := io.Copy(outFile, file); err != nil {
+		return "", err
+	}
+
+	return sourcePath, nil
+}
+
+// GetAssetSource finds the lens with the given label and returns the text source it was assembled from.
+func (s *LocalStore) GetAssetSource(viewName string, lensLabel string) (string, error) {
+	view, err := s.Load(viewName)
+	if err != nil {
+		return "", fmt.Errorf("failed to load view: %w", err)
+	}
+
+	for _, lens := range view.Transform.Lenses {
+		if lens.Label != lensLabel {
+			continue
+		}
+		if lens.Source == "" {
+			return "", fmt.Errorf("lens with label %q has no source", lensLabel)
+		}
+
+		sourcePath := filepath.Join(s.BasePath, viewName, "assets", fmt.Sprintf("%s.wat", lensLabel))
+		data, err := os.ReadFile(sourcePath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read source file %q: %w", sourcePath, err)
+		}
+		return string(data), nil
+	}
+
+	return "", fmt.Errorf("lens with label %q not found", lensLabel)
+}
+
 func (s *LocalStore) Rollback(viewName string, targetVersion int) (models.View, error) {
 	view, err := s.Load(viewName)
 	if err != nil {
@@ -310,10 +356,13 @@ func (s *LocalStore) GetAssetBlob(viewName string, lensLabel string) (string, er
 		return "", fmt.Errorf("lens with label %q not found", lensLabel)
 	}
 
-	// Resolve path to wasm file
+	// Resolve path to wasm file, relative paths being relative to the view folder
 	assetPath := lens.Path
-	if !filepath.IsAbs(assetPath) {
+	switch {
+	case assetPath == "":
 		assetPath = filepath.Join(s.BasePath, viewName, "assets", fmt.Sprintf("%s.wasm", lensLabel))
+	case !filepath.IsAbs(assetPath):
+		assetPath = filepath.Join(s.BasePath, viewName, filepath.FromSlash(assetPath))
 	}
 
 	// Read the file
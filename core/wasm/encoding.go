@@ -0,0 +1,96 @@
+package wasm
+
+import (
+	"fmt"
+)
+
+const (
+	sectionCustom   byte = 0
+	sectionType     byte = 1
+	sectionImport   byte = 2
+	sectionFunction byte = 3
+	sectionTable    byte = 4
+	sectionMemory   byte = 5
+	sectionGlobal   byte = 6
+	sectionExport   byte = 7
+	sectionStart    byte = 8
+	sectionElement  byte = 9
+	sectionCode     byte = 10
+	sectionData     byte = 11
+)
+
+const (
+	kindFunc   byte = 0
+	kindTable  byte = 1
+	kindMemory byte = 2
+	kindGlobal byte = 3
+)
+
+var magicAndVersion = []byte{0x00, 0x61, 0x73, 0x6D, 0x01, 0x00, 0x00, 0x00}
+
+var valueTypes = map[string]byte{
+	"i32":       0x7F,
+	"i64":       0x7E,
+	"f32":       0x7D,
+	"f64":       0x7C,
+	"v128":      0x7B,
+	"funcref":   0x70,
+	"externref": 0x6F,
+}
+
+func appendUleb(b []byte, v uint64) []byte {
+	for {
+		c := byte(v & 0x7F)
+		v >>= 7
+		if v != 0 {
+			c |= 0x80
+		}
+		b = append(b, c)
+		if v == 0 {
+			return b
+		}
+	}
+}
+
+func appendSleb(b []byte, v int64) []byte {
+	for {
+		c := byte(v & 0x7F)
+		v >>= 7
+		done := (v == 0 && c&0x40 == 0) || (v == -1 && c&0x40 != 0)
+		if !done {
+			c |= 0x80
+		}
+		b = append(b, c)
+		if done {
+			return b
+		}
+	}
+}
+
+func appendName(b []byte, s string) []byte {
+	b = appendUleb(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+func appendSection(b []byte, id byte, payload []byte) []byte {
+	b = append(b, id)
+	b = appendUleb(b, uint64(len(payload)))
+	return append(b, payload...)
+}
+
+// readUleb decodes an unsigned LEB128 value and returns it with the number of bytes read.
+func readUleb(b []byte) (uint64, int, error) {
+	var result uint64
+	var shift uint
+	for i, c := range b {
+		if shift >= 64 {
+			return 0, 0, fmt.Errorf("leb128 value overflows")
+		}
+		result |= uint64(c&0x7F) << shift
+		if c&0x80 == 0 {
+			return result, i + 1, nil
+		}
+		shift += 7
+	}
+	return 0, 0, fmt.Errorf("unexpected end of leb128 value")
+}
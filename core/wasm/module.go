@@ -0,0 +1,195 @@
+package wasm
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Section is one top-level section of a binary module.
+type Section struct {
+	ID   byte
+	Name string // set for custom sections only
+	Data []byte // payload, excluding the id and size
+}
+
+// Sections splits a binary module into its top-level sections.
+func Sections(bin []byte) ([]Section, error) {
+	if !bytes.HasPrefix(bin, magicAndVersion) {
+		return nil, fmt.Errorf("not a wasm module: bad magic or version")
+	}
+
+	var sections []Section
+	rest := bin[len(magicAndVersion):]
+	for len(rest) > 0 {
+		id := rest[0]
+		size, n, err := readUleb(rest[1:])
+		if err != nil {
+			return nil, fmt.Errorf("section %d: %w", id, err)
+		}
+		start := 1 + n
+		if uint64(len(rest)-start) < size {
+			return nil, fmt.Errorf("section %d: size %d exceeds module length", id, size)
+		}
+		end := start + int(size)
+
+		section := Section{ID: id, Data: rest[start:end]}
+		if id == sectionCustom {
+			nameLen, m, err := readUleb(section.Data)
+			if err != nil || uint64(len(section.Data)-m) < nameLen {
+				return nil, fmt.Errorf("custom section has a malformed name")
+			}
+			section.Name = string(section.Data[m : m+int(nameLen)])
+		}
+
+		sections = append(sections, section)
+		rest = rest[end:]
+	}
+	return sections, nil
+}
+
+// Strip removes every custom section (names, producers, DWARF debug info, source maps)
+// and returns the smaller module with the names of the removed sections.
+func Strip(bin []byte) ([]byte, []string, error) {
+	sections, err := Sections(bin)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out := append([]byte{}, magicAndVersion...)
+	var removed []string
+	for _, s := range sections {
+		if s.ID == sectionCustom {
+			removed = append(removed, s.Name)
+			continue
+		}
+		out = appendSection(out, s.ID, s.Data)
+	}
+	return out, removed, nil
+}
+
+// Import is one entry of the import section.
+type Import struct {
+	Module string
+	Name   string
+	Kind   byte
+}
+
+// Limits describes the size of a memory in 64KiB pages.
+type Limits struct {
+	Min    uint64
+	Max    uint64
+	HasMax bool
+}
+
+// ModuleInfo is the part of a module's structure relevant to sandbox checks.
+type ModuleInfo struct {
+	Imports  []Import
+	Memories []Limits // imported memories first, then defined ones
+	Exports  []string
+	HasStart bool
+}
+
+type reader struct {
+	b   []byte
+	err error
+}
+
+func (r *reader) byte() byte {
+	if r.err != nil {
+		return 0
+	}
+	if len(r.b) == 0 {
+		r.err = fmt.Errorf("unexpected end of section")
+		return 0
+	}
+	c := r.b[0]
+	r.b = r.b[1:]
+	return c
+}
+
+func (r *reader) uleb() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n, err := readUleb(r.b)
+	if err != nil {
+		r.err = err
+		return 0
+	}
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *reader) name() string {
+	n := r.uleb()
+	if r.err != nil {
+		return ""
+	}
+	if uint64(len(r.b)) < n {
+		r.err = fmt.Errorf("name exceeds section length")
+		return ""
+	}
+	s := string(r.b[:n])
+	r.b = r.b[n:]
+	return s
+}
+
+func (r *reader) limits() Limits {
+	flags := r.byte()
+	l := Limits{Min: r.uleb()}
+	if flags&0x01 != 0 {
+		l.Max = r.uleb()
+		l.HasMax = true
+	}
+	return l
+}
+
+// Inspect reads the imports, memories, exports and start function of a binary module.
+func Inspect(bin []byte) (ModuleInfo, error) {
+	sections, err := Sections(bin)
+	if err != nil {
+		return ModuleInfo{}, err
+	}
+
+	var info ModuleInfo
+	for _, s := range sections {
+		r := &reader{b: s.Data}
+		switch s.ID {
+		case sectionImport:
+			for i, n := uint64(0), r.uleb(); i < n && r.err == nil; i++ {
+				imp := Import{Module: r.name(), Name: r.name(), Kind: r.byte()}
+				switch imp.Kind {
+				case kindFunc:
+					r.uleb()
+				case kindTable:
+					r.byte()
+					r.limits()
+				case kindMemory:
+					info.Memories = append(info.Memories, r.limits())
+				case kindGlobal:
+					r.byte()
+					r.byte()
+				default:
+					r.err = fmt.Errorf("unknown import kind %d", imp.Kind)
+				}
+				info.Imports = append(info.Imports, imp)
+			}
+		case sectionMemory:
+			for i, n := uint64(0), r.uleb(); i < n && r.err == nil; i++ {
+				info.Memories = append(info.Memories, r.limits())
+			}
+		case sectionExport:
+			for i, n := uint64(0), r.uleb(); i < n && r.err == nil; i++ {
+				info.Exports = append(info.Exports, r.name())
+				r.byte()
+				r.uleb()
+			}
+		case sectionStart:
+			info.HasStart = true
+		}
+		if r.err != nil {
+			return ModuleInfo{}, fmt.Errorf("section %d: %w", s.ID, r.err)
+		}
+	}
+	return info, nil
+}
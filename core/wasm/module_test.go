@@ -0,0 +1,49 @@
+package wasm_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/core/wasm"
+)
+
+func TestStripRemovesCustomSections(t *testing.T) {
+	// the assembler emits a "name" section for $ids
+	bin, err := wasm.Assemble([]byte(`(module $lens (func $f (export "f")))`))
+	if err != nil {
+		t.Fatalf("assemble failed: %v", err)
+	}
+
+	stripped, removed, err := wasm.Strip(bin)
+	if err != nil {
+		t.Fatalf("strip failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(removed, []string{"name"}) {
+		t.Errorf("expected the name section to be removed, got %v", removed)
+	}
+	if len(stripped) >= len(bin) {
+		t.Errorf("expected stripped module to be smaller: %d >= %d", len(stripped), len(bin))
+	}
+
+	sections, err := wasm.Sections(stripped)
+	if err != nil {
+		t.Fatalf("stripped module does not parse: %v", err)
+	}
+	for _, s := range sections {
+		if s.ID == 0 {
+			t.Errorf("custom section %q survived stripping", s.Name)
+		}
+	}
+}
+
+func TestSectionsRejectsTruncatedModule(t *testing.T) {
+	bin, err := wasm.Assemble([]byte(`(module (func (export "f")))`))
+	if err != nil {
+		t.Fatalf("assemble failed: %v", err)
+	}
+
+	if _, err := wasm.Sections(bin[:len(bin)-2]); err == nil {
+		t.Error("expected an error for a truncated module")
+	}
+}
@@ -0,0 +1,110 @@
+package wasm
+
+type immediate int
+
+const (
+	immNone immediate = iota
+	immI32
+	immI64
+	immF32
+	immF64
+	immLocal
+	immGlobal
+	immFunc
+	immLabel
+	immLabelTable
+	immMemArg
+	immMemIndex
+	immMemCopy
+	immCallIndirect
+	immBlock
+)
+
+type opcode struct {
+	code  []byte
+	imm   immediate
+	align uint32 // natural alignment (log2) for memory access
+}
+
+var opcodes = map[string]opcode{
+	"unreachable":   {code: []byte{0x00}},
+	"nop":           {code: []byte{0x01}},
+	"block":         {code: []byte{0x02}, imm: immBlock},
+	"loop":          {code: []byte{0x03}, imm: immBlock},
+	"if":            {code: []byte{0x04}, imm: immBlock},
+	"br":            {code: []byte{0x0C}, imm: immLabel},
+	"br_if":         {code: []byte{0x0D}, imm: immLabel},
+	"br_table":      {code: []byte{0x0E}, imm: immLabelTable},
+	"return":        {code: []byte{0x0F}},
+	"call":          {code: []byte{0x10}, imm: immFunc},
+	"call_indirect": {code: []byte{0x11}, imm: immCallIndirect},
+	"drop":          {code: []byte{0x1A}},
+	"select":        {code: []byte{0x1B}},
+	"local.get":     {code: []byte{0x20}, imm: immLocal},
+	"local.set":     {code: []byte{0x21}, imm: immLocal},
+	"local.tee":     {code: []byte{0x22}, imm: immLocal},
+	"global.get":    {code: []byte{0x23}, imm: immGlobal},
+	"global.set":    {code: []byte{0x24}, imm: immGlobal},
+	"memory.size":   {code: []byte{0x3F}, imm: immMemIndex},
+	"memory.grow":   {code: []byte{0x40}, imm: immMemIndex},
+	"i32.const":     {code: []byte{0x41}, imm: immI32},
+	"i64.const":     {code: []byte{0x42}, imm: immI64},
+	"f32.const":     {code: []byte{0x43}, imm: immF32},
+	"f64.const":     {code: []byte{0x44}, imm: immF64},
+	"memory.copy":   {code: []byte{0xFC, 0x0A}, imm: immMemCopy},
+	"memory.fill":   {code: []byte{0xFC, 0x0B}, imm: immMemIndex},
+}
+
+// memory access instructions, in opcode order from 0x28, with their natural alignment
+var memoryOps = []struct {
+	name  string
+	align uint32
+}{
+	{"i32.load", 2}, {"i64.load", 3}, {"f32.load", 2}, {"f64.load", 3},
+	{"i32.load8_s", 0}, {"i32.load8_u", 0}, {"i32.load16_s", 1}, {"i32.load16_u", 1},
+	{"i64.load8_s", 0}, {"i64.load8_u", 0}, {"i64.load16_s", 1}, {"i64.load16_u", 1},
+	{"i64.load32_s", 2}, {"i64.load32_u", 2},
+	{"i32.store", 2}, {"i64.store", 3}, {"f32.store", 2}, {"f64.store", 3},
+	{"i32.store8", 0}, {"i32.store16", 1},
+	{"i64.store8", 0}, {"i64.store16", 1}, {"i64.store32", 2},
+}
+
+// numeric instructions without immediates, in opcode order from 0x45
+var numericOps = []string{
+	"i32.eqz", "i32.eq", "i32.ne", "i32.lt_s", "i32.lt_u", "i32.gt_s", "i32.gt_u", "i32.le_s", "i32.le_u", "i32.ge_s", "i32.ge_u",
+	"i64.eqz", "i64.eq", "i64.ne", "i64.lt_s", "i64.lt_u", "i64.gt_s", "i64.gt_u", "i64.le_s", "i64.le_u", "i64.ge_s", "i64.ge_u",
+	"f32.eq", "f32.ne", "f32.lt", "f32.gt", "f32.le", "f32.ge",
+	"f64.eq", "f64.ne", "f64.lt", "f64.gt", "f64.le", "f64.ge",
+	"i32.clz", "i32.ctz", "i32.popcnt", "i32.add", "i32.sub", "i32.mul", "i32.div_s", "i32.div_u", "i32.rem_s", "i32.rem_u",
+	"i32.and", "i32.or", "i32.xor", "i32.shl", "i32.shr_s", "i32.shr_u", "i32.rotl", "i32.rotr",
+	"i64.clz", "i64.ctz", "i64.popcnt", "i64.add", "i64.sub", "i64.mul", "i64.div_s", "i64.div_u", "i64.rem_s", "i64.rem_u",
+	"i64.and", "i64.or", "i64.xor", "i64.shl", "i64.shr_s", "i64.shr_u", "i64.rotl", "i64.rotr",
+	"f32.abs", "f32.neg", "f32.ceil", "f32.floor", "f32.trunc", "f32.nearest", "f32.sqrt",
+	"f32.add", "f32.sub", "f32.mul", "f32.div", "f32.min", "f32.max", "f32.copysign",
+	"f64.abs", "f64.neg", "f64.ceil", "f64.floor", "f64.trunc", "f64.nearest", "f64.sqrt",
+	"f64.add", "f64.sub", "f64.mul", "f64.div", "f64.min", "f64.max", "f64.copysign",
+	"i32.wrap_i64", "i32.trunc_f32_s", "i32.trunc_f32_u", "i32.trunc_f64_s", "i32.trunc_f64_u",
+	"i64.extend_i32_s", "i64.extend_i32_u", "i64.trunc_f32_s", "i64.trunc_f32_u", "i64.trunc_f64_s", "i64.trunc_f64_u",
+	"f32.convert_i32_s", "f32.convert_i32_u", "f32.convert_i64_s", "f32.convert_i64_u", "f32.demote_f64",
+	"f64.convert_i32_s", "f64.convert_i32_u", "f64.convert_i64_s", "f64.convert_i64_u", "f64.promote_f32",
+	"i32.reinterpret_f32", "i64.reinterpret_f64", "f32.reinterpret_i32", "f64.reinterpret_i64",
+	"i32.extend8_s", "i32.extend16_s", "i64.extend8_s", "i64.extend16_s", "i64.extend32_s",
+}
+
+// saturating truncation instructions, in order from 0xFC 0x00
+var saturatingOps = []string{
+	"i32.trunc_sat_f32_s", "i32.trunc_sat_f32_u", "i32.trunc_sat_f64_s", "i32.trunc_sat_f64_u",
+	"i64.trunc_sat_f32_s", "i64.trunc_sat_f32_u", "i64.trunc_sat_f64_s", "i64.trunc_sat_f64_u",
+}
+
+func init() {
+	for i, op := range memoryOps {
+		opcodes[op.name] = opcode{code: []byte{byte(0x28 + i)}, imm: immMemArg, align: op.align}
+	}
+	for i, name := range numericOps {
+		opcodes[name] = opcode{code: []byte{byte(0x45 + i)}}
+	}
+	for i, name := range saturatingOps {
+		opcodes[name] = opcode{code: []byte{0xFC, byte(i)}}
+	}
+}
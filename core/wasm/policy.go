@@ -0,0 +1,93 @@
+package wasm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Policy restricts what a lens module may ask of its host.
+type Policy struct {
+	// AllowedImports lists permitted imports as "module.name"; "module.*" allows a whole module.
+	AllowedImports []string `json:"allowedImports"`
+	// MaxInitialPages caps the initial size of every memory, in 64KiB pages. Zero disables the check.
+	MaxInitialPages uint64 `json:"maxInitialPages"`
+	// MaxMemoryPages caps the declared maximum of every memory. Zero disables the check.
+	MaxMemoryPages uint64 `json:"maxMemoryPages"`
+	// RequireMaximum rejects memories that do not declare a maximum.
+	RequireMaximum bool `json:"requireMaximum"`
+	// AllowStart permits a start function, which would run during instantiation.
+	AllowStart bool `json:"allowStart"`
+}
+
+// DefaultPolicy allows only the lens.next host function, 16MiB of initial memory and 64MiB of
+// declared maximum, and no start function.
+func DefaultPolicy() Policy {
+	return Policy{
+		AllowedImports:  []string{"lens.next"},
+		MaxInitialPages: 256,
+		MaxMemoryPages:  1024,
+	}
+}
+
+// PolicyError lists every way a module breaks a policy.
+type PolicyError struct {
+	Violations []string
+}
+
+func (e *PolicyError) Error() string {
+	return "module violates sandbox policy: " + strings.Join(e.Violations, "; ")
+}
+
+func (p Policy) allows(imp Import) bool {
+	for _, allowed := range p.AllowedImports {
+		module, name, _ := strings.Cut(allowed, ".")
+		if module == imp.Module && (name == "*" || name == imp.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+var importKinds = map[byte]string{
+	kindFunc:   "function",
+	kindTable:  "table",
+	kindMemory: "memory",
+	kindGlobal: "global",
+}
+
+// Check returns a *PolicyError when bin does not satisfy the policy.
+func (p Policy) Check(bin []byte) error {
+	info, err := Inspect(bin)
+	if err != nil {
+		return err
+	}
+
+	var violations []string
+	for _, imp := range info.Imports {
+		if !p.allows(imp) {
+			violations = append(violations, fmt.Sprintf("import of %s %s.%s is not allowed", importKinds[imp.Kind], imp.Module, imp.Name))
+		}
+	}
+
+	for i, mem := range info.Memories {
+		if p.MaxInitialPages > 0 && mem.Min > p.MaxInitialPages {
+			violations = append(violations, fmt.Sprintf("memory %d starts at %d pages, limit is %d", i, mem.Min, p.MaxInitialPages))
+		}
+		if !mem.HasMax {
+			if p.RequireMaximum {
+				violations = append(violations, fmt.Sprintf("memory %d declares no maximum", i))
+			}
+		} else if p.MaxMemoryPages > 0 && mem.Max > p.MaxMemoryPages {
+			violations = append(violations, fmt.Sprintf("memory %d may grow to %d pages, limit is %d", i, mem.Max, p.MaxMemoryPages))
+		}
+	}
+
+	if info.HasStart && !p.AllowStart {
+		violations = append(violations, "start functions are not allowed")
+	}
+
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+	return nil
+}
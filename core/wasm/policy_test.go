@@ -0,0 +1,69 @@
+package wasm_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/core/wasm"
+)
+
+func mustAssemble(t *testing.T, src string) []byte {
+	t.Helper()
+	bin, err := wasm.Assemble([]byte(src))
+	if err != nil {
+		t.Fatalf("assemble failed: %v", err)
+	}
+	return bin
+}
+
+func TestDefaultPolicyAcceptsLens(t *testing.T) {
+	bin := mustAssemble(t, `(module
+  (import "lens" "next" (func $next (result i32)))
+  (memory (export "memory") 17 64)
+  (func (export "transform") (result i32) (call $next)))`)
+
+	if err := wasm.DefaultPolicy().Check(bin); err != nil {
+		t.Errorf("expected lens to satisfy the default policy, got: %v", err)
+	}
+}
+
+func TestDefaultPolicyReportsEveryViolation(t *testing.T) {
+	bin := mustAssemble(t, `(module
+  (import "wasi_snapshot_preview1" "path_open" (func (param i32 i32 i32 i32 i32 i64 i64 i32 i32) (result i32)))
+  (memory 512 2048)
+  (func $init)
+  (start $init))`)
+
+	err := wasm.DefaultPolicy().Check(bin)
+
+	var policyErr *wasm.PolicyError
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("expected a PolicyError, got: %v", err)
+	}
+
+	expected := []string{
+		"import of function wasi_snapshot_preview1.path_open is not allowed",
+		"memory 0 starts at 512 pages, limit is 256",
+		"memory 0 may grow to 2048 pages, limit is 1024",
+		"start functions are not allowed",
+	}
+	if strings.Join(policyErr.Violations, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected violations:\n%s", strings.Join(policyErr.Violations, "\n"))
+	}
+}
+
+func TestPolicyWildcardImports(t *testing.T) {
+	bin := mustAssemble(t, `(module (import "env" "abort" (func (param i32 i32 i32 i32))))`)
+
+	policy := wasm.DefaultPolicy()
+	policy.AllowedImports = append(policy.AllowedImports, "env.*")
+	if err := policy.Check(bin); err != nil {
+		t.Errorf("expected env.* to allow env.abort, got: %v", err)
+	}
+
+	policy.RequireMaximum = true
+	if err := policy.Check(mustAssemble(t, `(module (memory 1))`)); err == nil {
+		t.Error("expected a memory without maximum to be rejected")
+	}
+}
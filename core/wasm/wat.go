@@ -0,0 +1,1439 @@
+package wasm
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// IsWat reports whether src looks like WebAssembly text format rather than a binary module.
+func IsWat(src []byte) bool {
+	if bytes.HasPrefix(src, magicAndVersion[:4]) {
+		return false
+	}
+	trimmed := bytes.TrimLeft(src, " \t\r\n")
+	return bytes.HasPrefix(trimmed, []byte("(")) || bytes.HasPrefix(trimmed, []byte(";;"))
+}
+
+type funcType struct {
+	params  []byte
+	results []byte
+}
+
+func (t funcType) equal(o funcType) bool {
+	return bytes.Equal(t.params, o.params) && bytes.Equal(t.results, o.results)
+}
+
+// namespace maps symbolic $ids of one index space to their indices.
+type namespace struct {
+	kind  string
+	ids   map[string]uint32
+	count uint32
+}
+
+func newNamespace(kind string) *namespace {
+	return &namespace{kind: kind, ids: map[string]uint32{}}
+}
+
+func (ns *namespace) add(id *node) (uint32, error) {
+	idx := ns.count
+	ns.count++
+	if id != nil {
+		if _, exists := ns.ids[id.tok.text]; exists {
+			return 0, id.errorf("duplicate %s %s", ns.kind, id.tok.text)
+		}
+		ns.ids[id.tok.text] = idx
+	}
+	return idx, nil
+}
+
+func (ns *namespace) resolve(n *node) (uint32, error) {
+	if n == nil || !n.isAtom() {
+		return 0, fmt.Errorf("expected %s index", ns.kind)
+	}
+	if n.isID() {
+		idx, ok := ns.ids[n.tok.text]
+		if !ok {
+			return 0, n.errorf("unknown %s %s", ns.kind, n.tok.text)
+		}
+		return idx, nil
+	}
+	idx, ok := parseIndexLiteral(n.tok.text)
+	if !ok {
+		return 0, n.errorf("invalid %s index %q", ns.kind, n.tok.text)
+	}
+	return idx, nil
+}
+
+type funcDef struct {
+	src        *node
+	typeIdx    uint32
+	paramIDs   []*node
+	localTypes []byte
+	localIDs   map[string]uint32
+	body       []*node
+}
+
+type assembler struct {
+	name string
+
+	types   []funcType
+	typeNS  *namespace
+	funcNS  *namespace
+	tableNS *namespace
+	memNS   *namespace
+	globNS  *namespace
+
+	imports   []byte
+	nImports  int
+	funcs     []*funcDef
+	funcNames map[uint32]string
+	tables    []byte
+	nTables   int
+	memories  []byte
+	nMemories int
+	globals   []byte
+	nGlobals  int
+	exports   []byte
+	nExports  int
+	start     *uint32
+	elems     []byte
+	nElems    int
+	data      []byte
+	nData     int
+
+	pending      []func() error
+	definedKinds map[string]bool
+}
+
+// Assemble converts a module in WebAssembly text format into its binary encoding.
+//
+// It covers the core MVP instruction set plus sign extension, saturating truncation
+// and bulk memory copy/fill, in both flat and folded form.
+func Assemble(src []byte) ([]byte, error) {
+	nodes, err := parseSExpressions(string(src))
+	if err != nil {
+		return nil, fmt.Errorf("wat: %w", err)
+	}
+
+	fields := nodes
+	if len(nodes) == 1 && nodes[0].head() == "module" {
+		fields = nodes[0].list[1:]
+	}
+
+	a := &assembler{
+		typeNS:       newNamespace("type"),
+		funcNS:       newNamespace("func"),
+		tableNS:      newNamespace("table"),
+		memNS:        newNamespace("memory"),
+		globNS:       newNamespace("global"),
+		funcNames:    map[uint32]string{},
+		definedKinds: map[string]bool{},
+	}
+
+	if len(fields) > 0 && fields[0].isID() && len(nodes) == 1 {
+		a.name = strings.TrimPrefix(fields[0].tok.text, "$")
+		fields = fields[1:]
+	}
+
+	out, err := a.assemble(fields)
+	if err != nil {
+		return nil, fmt.Errorf("wat: %w", err)
+	}
+	return out, nil
+}
+
+func (a *assembler) assemble(fields []*node) ([]byte, error) {
+	// explicit types come first so implicit ones from type uses are appended after them
+	for _, f := range fields {
+		if f.head() == "type" {
+			if err := a.defineType(f); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, f := range fields {
+		var err error
+		switch f.head() {
+		case "type":
+		case "import":
+			err = a.defineImport(f)
+		case "func":
+			err = a.defineFunc(f)
+		case "table":
+			err = a.defineTable(f)
+		case "memory":
+			err = a.defineMemory(f)
+		case "global":
+			err = a.defineGlobal(f)
+		case "export":
+			f := f
+			a.pending = append(a.pending, func() error { return a.defineExport(f) })
+		case "start":
+			f := f
+			a.pending = append(a.pending, func() error { return a.defineStart(f) })
+		case "elem":
+			f := f
+			a.pending = append(a.pending, func() error { return a.defineElem(f) })
+		case "data":
+			f := f
+			a.pending = append(a.pending, func() error { return a.defineData(f) })
+		default:
+			err = f.errorf("unexpected module field %q", f.head())
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, p := range a.pending {
+		if err := p(); err != nil {
+			return nil, err
+		}
+	}
+
+	var code []byte
+	code = appendUleb(code, uint64(len(a.funcs)))
+	for _, fn := range a.funcs {
+		body, err := a.encodeFunc(fn)
+		if err != nil {
+			return nil, err
+		}
+		code = appendUleb(code, uint64(len(body)))
+		code = append(code, body...)
+	}
+
+	out := append([]byte{}, magicAndVersion...)
+
+	if len(a.types) > 0 {
+		var types []byte
+		types = appendUleb(types, uint64(len(a.types)))
+		for _, t := range a.types {
+			types = append(types, 0x60)
+			types = appendUleb(types, uint64(len(t.params)))
+			types = append(types, t.params...)
+			types = appendUleb(types, uint64(len(t.results)))
+			types = append(types, t.results...)
+		}
+		out = appendSection(out, sectionType, types)
+	}
+
+	out = appendVecSection(out, sectionImport, a.nImports, a.imports)
+
+	if len(a.funcs) > 0 {
+		var decls []byte
+		decls = appendUleb(decls, uint64(len(a.funcs)))
+		for _, fn := range a.funcs {
+			decls = appendUleb(decls, uint64(fn.typeIdx))
+		}
+		out = appendSection(out, sectionFunction, decls)
+	}
+
+	out = appendVecSection(out, sectionTable, a.nTables, a.tables)
+	out = appendVecSection(out, sectionMemory, a.nMemories, a.memories)
+	out = appendVecSection(out, sectionGlobal, a.nGlobals, a.globals)
+	out = appendVecSection(out, sectionExport, a.nExports, a.exports)
+
+	if a.start != nil {
+		out = appendSection(out, sectionStart, appendUleb(nil, uint64(*a.start)))
+	}
+
+	out = appendVecSection(out, sectionElement, a.nElems, a.elems)
+
+	if len(a.funcs) > 0 {
+		out = appendSection(out, sectionCode, code)
+	}
+
+	out = appendVecSection(out, sectionData, a.nData, a.data)
+
+	if names := a.nameSection(); names != nil {
+		out = appendSection(out, sectionCustom, names)
+	}
+
+	return out, nil
+}
+
+func appendVecSection(out []byte, id byte, count int, items []byte) []byte {
+	if count == 0 {
+		return out
+	}
+	payload := appendUleb(nil, uint64(count))
+	return appendSection(out, id, append(payload, items...))
+}
+
+func (a *assembler) nameSection() []byte {
+	if a.name == "" && len(a.funcNames) == 0 {
+		return nil
+	}
+
+	payload := appendName(nil, "name")
+
+	if a.name != "" {
+		sub := appendName(nil, a.name)
+		payload = append(payload, 0)
+		payload = appendUleb(payload, uint64(len(sub)))
+		payload = append(payload, sub...)
+	}
+
+	if len(a.funcNames) > 0 {
+		sub := appendUleb(nil, uint64(len(a.funcNames)))
+		for idx := uint32(0); idx < a.funcNS.count; idx++ {
+			if name, ok := a.funcNames[idx]; ok {
+				sub = appendUleb(sub, uint64(idx))
+				sub = appendName(sub, name)
+			}
+		}
+		payload = append(payload, 1)
+		payload = appendUleb(payload, uint64(len(sub)))
+		payload = append(payload, sub...)
+	}
+
+	// the caller wraps this in a custom section, whose payload starts with its name
+	return payload
+}
+
+// takeID pops a leading $id from args.
+func takeID(args []*node) (*node, []*node) {
+	if len(args) > 0 && args[0].isID() {
+		return args[0], args[1:]
+	}
+	return nil, args
+}
+
+func parseValueType(n *node) (byte, error) {
+	if !n.isAtom() {
+		return 0, n.errorf("expected value type")
+	}
+	t, ok := valueTypes[n.tok.text]
+	if !ok {
+		return 0, n.errorf("unknown value type %q", n.tok.text)
+	}
+	return t, nil
+}
+
+func (a *assembler) defineType(f *node) error {
+	id, rest := takeID(f.list[1:])
+	if len(rest) != 1 || rest[0].head() != "func" {
+		return f.errorf("expected (type $id? (func ...))")
+	}
+
+	ft, _, remaining, err := parseSignature(rest[0].list[1:])
+	if err != nil {
+		return err
+	}
+	if len(remaining) > 0 {
+		return remaining[0].errorf("unexpected token in function type")
+	}
+
+	if _, err := a.typeNS.add(id); err != nil {
+		return err
+	}
+	a.types = append(a.types, ft)
+	return nil
+}
+
+// parseSignature reads leading (param ...) and (result ...) lists.
+func parseSignature(args []*node) (funcType, []*node, []*node, error) {
+	var ft funcType
+	var paramIDs []*node
+
+	i := 0
+	for ; i < len(args) && args[i].head() == "param"; i++ {
+		items := args[i].list[1:]
+		if len(items) > 0 && items[0].isID() {
+			if len(items) != 2 {
+				return ft, nil, nil, args[i].errorf("named param must have exactly one type")
+			}
+			t, err := parseValueType(items[1])
+			if err != nil {
+				return ft, nil, nil, err
+			}
+			ft.params = append(ft.params, t)
+			paramIDs = append(paramIDs, items[0])
+			continue
+		}
+		for _, item := range items {
+			t, err := parseValueType(item)
+			if err != nil {
+				return ft, nil, nil, err
+			}
+			ft.params = append(ft.params, t)
+			paramIDs = append(paramIDs, nil)
+		}
+	}
+
+	for ; i < len(args) && args[i].head() == "result"; i++ {
+		for _, item := range args[i].list[1:] {
+			t, err := parseValueType(item)
+			if err != nil {
+				return ft, nil, nil, err
+			}
+			ft.results = append(ft.results, t)
+		}
+	}
+
+	return ft, paramIDs, args[i:], nil
+}
+
+// parseTypeUse reads an optional (type idx) followed by params and results and returns
+// the index of the matching type, adding an implicit type when needed.
+func (a *assembler) parseTypeUse(args []*node) (uint32, []*node, []*node, error) {
+	var explicit *node
+	if len(args) > 0 && args[0].head() == "type" {
+		explicit = args[0]
+		args = args[1:]
+	}
+
+	ft, paramIDs, rest, err := parseSignature(args)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	if explicit != nil {
+		if len(explicit.list) != 2 {
+			return 0, nil, nil, explicit.errorf("expected (type idx)")
+		}
+		idx, err := a.typeNS.resolve(explicit.list[1])
+		if err != nil {
+			return 0, nil, nil, err
+		}
+		if int(idx) >= len(a.types) {
+			return 0, nil, nil, explicit.errorf("type index %d out of range", idx)
+		}
+		if (len(ft.params) > 0 || len(ft.results) > 0) && !a.types[idx].equal(ft) {
+			return 0, nil, nil, explicit.errorf("inline signature does not match type %d", idx)
+		}
+		if len(paramIDs) == 0 {
+			paramIDs = make([]*node, len(a.types[idx].params))
+		}
+		return idx, paramIDs, rest, nil
+	}
+
+	for i, t := range a.types {
+		if t.equal(ft) {
+			return uint32(i), paramIDs, rest, nil
+		}
+	}
+	a.types = append(a.types, ft)
+	a.typeNS.count++
+	return uint32(len(a.types) - 1), paramIDs, rest, nil
+}
+
+// takeInlineExports pops (export "name") abbreviations and records them for the item.
+func (a *assembler) takeInlineExports(args []*node, kind byte, idx uint32) ([]*node, error) {
+	for len(args) > 0 && args[0].head() == "export" {
+		e := args[0]
+		if len(e.list) != 2 || !e.list[1].isString() {
+			return nil, e.errorf(`expected (export "name")`)
+		}
+		a.addExport(e.list[1].tok.text, kind, idx)
+		args = args[1:]
+	}
+	return args, nil
+}
+
+func (a *assembler) addExport(name string, kind byte, idx uint32) {
+	a.exports = appendName(a.exports, name)
+	a.exports = append(a.exports, kind)
+	a.exports = appendUleb(a.exports, uint64(idx))
+	a.nExports++
+}
+
+// takeInlineImport pops an (import "module" "name") abbreviation.
+func takeInlineImport(args []*node) (*node, []*node, error) {
+	if len(args) > 0 && args[0].head() == "import" {
+		imp := args[0]
+		if len(imp.list) != 3 || !imp.list[1].isString() || !imp.list[2].isString() {
+			return nil, nil, imp.errorf(`expected (import "module" "name")`)
+		}
+		return imp, args[1:], nil
+	}
+	return nil, args, nil
+}
+
+func (a *assembler) checkImportOrder(f *node, kind string) error {
+	if a.definedKinds[kind] {
+		return f.errorf("imports must come before %s definitions", kind)
+	}
+	return nil
+}
+
+func (a *assembler) appendImportHeader(imp *node) {
+	a.imports = appendName(a.imports, imp.list[1].tok.text)
+	a.imports = appendName(a.imports, imp.list[2].tok.text)
+	a.nImports++
+}
+
+func (a *assembler) defineImport(f *node) error {
+	if len(f.list) != 4 || !f.list[1].isString() || !f.list[2].isString() || !f.list[3].isList {
+		return f.errorf(`expected (import "module" "name" (desc))`)
+	}
+
+	desc := f.list[3]
+	inline := &node{tok: f.tok, isList: true, list: []*node{f.list[0], f.list[1], f.list[2]}}
+	items := append([]*node{desc.list[0], inline}, desc.list[1:]...)
+
+	// (import "m" "n" (func $id ...)) is the same as (func $id (import "m" "n") ...)
+	if id, rest := takeID(desc.list[1:]); id != nil {
+		items = append([]*node{desc.list[0], id, inline}, rest...)
+	}
+	rewritten := &node{tok: desc.tok, isList: true, list: items}
+
+	switch desc.head() {
+	case "func":
+		return a.defineFunc(rewritten)
+	case "table":
+		return a.defineTable(rewritten)
+	case "memory":
+		return a.defineMemory(rewritten)
+	case "global":
+		return a.defineGlobal(rewritten)
+	default:
+		return desc.errorf("unknown import kind %q", desc.head())
+	}
+}
+
+func (a *assembler) defineFunc(f *node) error {
+	id, rest := takeID(f.list[1:])
+
+	idx, err := a.funcNS.add(id)
+	if err != nil {
+		return err
+	}
+	if id != nil {
+		a.funcNames[idx] = strings.TrimPrefix(id.tok.text, "$")
+	}
+
+	rest, err = a.takeInlineExports(rest, kindFunc, idx)
+	if err != nil {
+		return err
+	}
+
+	imp, rest, err := takeInlineImport(rest)
+	if err != nil {
+		return err
+	}
+
+	typeIdx, paramIDs, rest, err := a.parseTypeUse(rest)
+	if err != nil {
+		return err
+	}
+
+	if imp != nil {
+		if err := a.checkImportOrder(f, "func"); err != nil {
+			return err
+		}
+		if len(rest) > 0 {
+			return rest[0].errorf("imported function cannot have a body")
+		}
+		a.appendImportHeader(imp)
+		a.imports = append(a.imports, kindFunc)
+		a.imports = appendUleb(a.imports, uint64(typeIdx))
+		return nil
+	}
+	a.definedKinds["func"] = true
+
+	fn := &funcDef{src: f, typeIdx: typeIdx, paramIDs: paramIDs, localIDs: map[string]uint32{}}
+	for i, pid := range paramIDs {
+		if pid != nil {
+			fn.localIDs[pid.tok.text] = uint32(i)
+		}
+	}
+
+	for len(rest) > 0 && rest[0].head() == "local" {
+		items := rest[0].list[1:]
+		if len(items) > 0 && items[0].isID() {
+			if len(items) != 2 {
+				return rest[0].errorf("named local must have exactly one type")
+			}
+			t, err := parseValueType(items[1])
+			if err != nil {
+				return err
+			}
+			if _, exists := fn.localIDs[items[0].tok.text]; exists {
+				return items[0].errorf("duplicate local %s", items[0].tok.text)
+			}
+			fn.localIDs[items[0].tok.text] = uint32(len(paramIDs) + len(fn.localTypes))
+			fn.localTypes = append(fn.localTypes, t)
+		} else {
+			for _, item := range items {
+				t, err := parseValueType(item)
+				if err != nil {
+					return err
+				}
+				fn.localTypes = append(fn.localTypes, t)
+			}
+		}
+		rest = rest[1:]
+	}
+
+	fn.body = rest
+	a.funcs = append(a.funcs, fn)
+	return nil
+}
+
+func parseLimits(args []*node) ([]byte, []*node, error) {
+	var nums []uint64
+	for len(args) > 0 && args[0].isAtom() && !args[0].isID() {
+		if _, isType := valueTypes[args[0].tok.text]; isType {
+			break
+		}
+		v, err := parseInt(args[0].tok.text, 32)
+		if err != nil {
+			return nil, nil, args[0].errorf("%v", err)
+		}
+		nums = append(nums, v)
+		args = args[1:]
+	}
+
+	switch len(nums) {
+	case 1:
+		return appendUleb([]byte{0x00}, nums[0]), args, nil
+	case 2:
+		if nums[1] < nums[0] {
+			return nil, nil, fmt.Errorf("maximum %d is smaller than minimum %d", nums[1], nums[0])
+		}
+		return appendUleb(appendUleb([]byte{0x01}, nums[0]), nums[1]), args, nil
+	default:
+		return nil, nil, fmt.Errorf("expected limits: min max?")
+	}
+}
+
+func (a *assembler) defineTable(f *node) error {
+	id, rest := takeID(f.list[1:])
+
+	idx, err := a.tableNS.add(id)
+	if err != nil {
+		return err
+	}
+
+	rest, err = a.takeInlineExports(rest, kindTable, idx)
+	if err != nil {
+		return err
+	}
+
+	imp, rest, err := takeInlineImport(rest)
+	if err != nil {
+		return err
+	}
+
+	// (table reftype (elem func*)) declares a table sized to its inline segment
+	if len(rest) == 2 && rest[0].isAtom() && rest[1].head() == "elem" {
+		refType, err := parseValueType(rest[0])
+		if err != nil {
+			return err
+		}
+		funcs := rest[1].list[1:]
+		limits := appendUleb(appendUleb([]byte{0x01}, uint64(len(funcs))), uint64(len(funcs)))
+
+		a.definedKinds["table"] = true
+		a.tables = append(a.tables, refType)
+		a.tables = append(a.tables, limits...)
+		a.nTables++
+
+		a.pending = append(a.pending, func() error {
+			return a.appendActiveElem(idx, []byte{0x41, 0x00, 0x0B}, funcs)
+		})
+		return nil
+	}
+
+	limits, rest, err := parseLimits(rest)
+	if err != nil {
+		return f.errorf("%v", err)
+	}
+	if len(rest) != 1 {
+		return f.errorf("expected table reference type")
+	}
+	refType, err := parseValueType(rest[0])
+	if err != nil {
+		return err
+	}
+
+	if imp != nil {
+		if err := a.checkImportOrder(f, "table"); err != nil {
+			return err
+		}
+		a.appendImportHeader(imp)
+		a.imports = append(a.imports, kindTable, refType)
+		a.imports = append(a.imports, limits...)
+		return nil
+	}
+
+	a.definedKinds["table"] = true
+	a.tables = append(a.tables, refType)
+	a.tables = append(a.tables, limits...)
+	a.nTables++
+	return nil
+}
+
+func (a *assembler) defineMemory(f *node) error {
+	id, rest := takeID(f.list[1:])
+
+	idx, err := a.memNS.add(id)
+	if err != nil {
+		return err
+	}
+
+	rest, err = a.takeInlineExports(rest, kindMemory, idx)
+	if err != nil {
+		return err
+	}
+
+	imp, rest, err := takeInlineImport(rest)
+	if err != nil {
+		return err
+	}
+
+	// (memory (data "...")) declares a memory sized to its inline data
+	if imp == nil && len(rest) == 1 && rest[0].head() == "data" {
+		var content []byte
+		for _, s := range rest[0].list[1:] {
+			if !s.isString() {
+				return s.errorf("expected string in inline data")
+			}
+			content = append(content, s.tok.text...)
+		}
+		pages := uint64((len(content) + 65535) / 65536)
+
+		a.definedKinds["memory"] = true
+		a.memories = append(a.memories, 0x01)
+		a.memories = appendUleb(a.memories, pages)
+		a.memories = appendUleb(a.memories, pages)
+		a.nMemories++
+
+		a.data = appendUleb(append(a.data, 0x02), uint64(idx))
+		a.data = append(a.data, 0x41, 0x00, 0x0B)
+		a.data = appendUleb(a.data, uint64(len(content)))
+		a.data = append(a.data, content...)
+		a.nData++
+		return nil
+	}
+
+	limits, rest, err := parseLimits(rest)
+	if err != nil {
+		return f.errorf("%v", err)
+	}
+	if len(rest) > 0 {
+		return rest[0].errorf("unexpected token in memory definition")
+	}
+
+	if imp != nil {
+		if err := a.checkImportOrder(f, "memory"); err != nil {
+			return err
+		}
+		a.appendImportHeader(imp)
+		a.imports = append(a.imports, kindMemory)
+		a.imports = append(a.imports, limits...)
+		return nil
+	}
+
+	a.definedKinds["memory"] = true
+	a.memories = append(a.memories, limits...)
+	a.nMemories++
+	return nil
+}
+
+func parseGlobalType(n *node) ([]byte, error) {
+	if n.head() == "mut" {
+		if len(n.list) != 2 {
+			return nil, n.errorf("expected (mut valtype)")
+		}
+		t, err := parseValueType(n.list[1])
+		if err != nil {
+			return nil, err
+		}
+		return []byte{t, 0x01}, nil
+	}
+	t, err := parseValueType(n)
+	if err != nil {
+		return nil, err
+	}
+	return []byte{t, 0x00}, nil
+}
+
+func (a *assembler) defineGlobal(f *node) error {
+	id, rest := takeID(f.list[1:])
+
+	idx, err := a.globNS.add(id)
+	if err != nil {
+		return err
+	}
+
+	rest, err = a.takeInlineExports(rest, kindGlobal, idx)
+	if err != nil {
+		return err
+	}
+
+	imp, rest, err := takeInlineImport(rest)
+	if err != nil {
+		return err
+	}
+
+	if len(rest) == 0 {
+		return f.errorf("expected global type")
+	}
+	globalType, err := parseGlobalType(rest[0])
+	if err != nil {
+		return err
+	}
+
+	if imp != nil {
+		if err := a.checkImportOrder(f, "global"); err != nil {
+			return err
+		}
+		if len(rest) > 1 {
+			return rest[1].errorf("imported global cannot have an initializer")
+		}
+		a.appendImportHeader(imp)
+		a.imports = append(a.imports, kindGlobal)
+		a.imports = append(a.imports, globalType...)
+		return nil
+	}
+
+	a.definedKinds["global"] = true
+	// initializers may only read imported globals, which are all declared by now
+	expr, err := a.encodeConstExpr(rest[1:])
+	if err != nil {
+		return err
+	}
+	a.globals = append(a.globals, globalType...)
+	a.globals = append(a.globals, expr...)
+	a.nGlobals++
+	return nil
+}
+
+func (a *assembler) defineExport(f *node) error {
+	if len(f.list) != 3 || !f.list[1].isString() || !f.list[2].isList || len(f.list[2].list) != 2 {
+		return f.errorf(`expected (export "name" (kind idx))`)
+	}
+
+	desc := f.list[2]
+	var kind byte
+	var ns *namespace
+	switch desc.head() {
+	case "func":
+		kind, ns = kindFunc, a.funcNS
+	case "table":
+		kind, ns = kindTable, a.tableNS
+	case "memory":
+		kind, ns = kindMemory, a.memNS
+	case "global":
+		kind, ns = kindGlobal, a.globNS
+	default:
+		return desc.errorf("unknown export kind %q", desc.head())
+	}
+
+	idx, err := ns.resolve(desc.list[1])
+	if err != nil {
+		return err
+	}
+	a.addExport(f.list[1].tok.text, kind, idx)
+	return nil
+}
+
+func (a *assembler) defineStart(f *node) error {
+	if len(f.list) != 2 {
+		return f.errorf("expected (start idx)")
+	}
+	idx, err := a.funcNS.resolve(f.list[1])
+	if err != nil {
+		return err
+	}
+	if a.start != nil {
+		return f.errorf("multiple start functions")
+	}
+	a.start = &idx
+	return nil
+}
+
+// takeOffset reads (offset instr*) or a single folded constant instruction.
+func (a *assembler) takeOffset(args []*node) ([]byte, []*node, error) {
+	if len(args) == 0 || !args[0].isList {
+		return nil, args, nil
+	}
+	var instrs []*node
+	if args[0].head() == "offset" {
+		instrs = args[0].list[1:]
+	} else {
+		instrs = args[:1]
+	}
+	expr, err := a.encodeConstExpr(instrs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return expr, args[1:], nil
+}
+
+func (a *assembler) resolveFuncs(items []*node) ([]byte, error) {
+	out := appendUleb(nil, uint64(len(items)))
+	for _, item := range items {
+		idx, err := a.funcNS.resolve(item)
+		if err != nil {
+			return nil, err
+		}
+		out = appendUleb(out, uint64(idx))
+	}
+	return out, nil
+}
+
+func (a *assembler) appendActiveElem(table uint32, offset []byte, funcs []*node) error {
+	indices, err := a.resolveFuncs(funcs)
+	if err != nil {
+		return err
+	}
+	if table == 0 {
+		a.elems = append(a.elems, 0x00)
+		a.elems = append(a.elems, offset...)
+	} else {
+		a.elems = appendUleb(append(a.elems, 0x02), uint64(table))
+		a.elems = append(a.elems, offset...)
+		a.elems = append(a.elems, 0x00)
+	}
+	a.elems = append(a.elems, indices...)
+	a.nElems++
+	return nil
+}
+
+func (a *assembler) defineElem(f *node) error {
+	_, rest := takeID(f.list[1:])
+
+	mode := "passive"
+	if len(rest) > 0 && rest[0].isAtom() && rest[0].tok.text == "declare" {
+		mode = "declare"
+		rest = rest[1:]
+	}
+
+	var table uint32
+	if len(rest) > 0 && rest[0].head() == "table" {
+		if len(rest[0].list) != 2 {
+			return rest[0].errorf("expected (table idx)")
+		}
+		idx, err := a.tableNS.resolve(rest[0].list[1])
+		if err != nil {
+			return err
+		}
+		table = idx
+		rest = rest[1:]
+	}
+
+	offset, rest, err := a.takeOffset(rest)
+	if err != nil {
+		return err
+	}
+	if offset != nil {
+		mode = "active"
+	}
+
+	if len(rest) > 0 && rest[0].isAtom() && rest[0].tok.text == "func" {
+		rest = rest[1:]
+	}
+
+	switch mode {
+	case "active":
+		return a.appendActiveElem(table, offset, rest)
+	default:
+		indices, err := a.resolveFuncs(rest)
+		if err != nil {
+			return err
+		}
+		flag := byte(0x01)
+		if mode == "declare" {
+			flag = 0x03
+		}
+		a.elems = append(a.elems, flag, 0x00)
+		a.elems = append(a.elems, indices...)
+		a.nElems++
+		return nil
+	}
+}
+
+func (a *assembler) defineData(f *node) error {
+	_, rest := takeID(f.list[1:])
+
+	var memory uint32
+	if len(rest) > 0 && rest[0].head() == "memory" {
+		if len(rest[0].list) != 2 {
+			return rest[0].errorf("expected (memory idx)")
+		}
+		idx, err := a.memNS.resolve(rest[0].list[1])
+		if err != nil {
+			return err
+		}
+		memory = idx
+		rest = rest[1:]
+	}
+
+	offset, rest, err := a.takeOffset(rest)
+	if err != nil {
+		return err
+	}
+
+	var content []byte
+	for _, s := range rest {
+		if !s.isString() {
+			return s.errorf("expected string in data segment")
+		}
+		content = append(content, s.tok.text...)
+	}
+
+	switch {
+	case offset == nil:
+		a.data = append(a.data, 0x01)
+	case memory == 0:
+		a.data = append(a.data, 0x00)
+		a.data = append(a.data, offset...)
+	default:
+		a.data = appendUleb(append(a.data, 0x02), uint64(memory))
+		a.data = append(a.data, offset...)
+	}
+	a.data = appendUleb(a.data, uint64(len(content)))
+	a.data = append(a.data, content...)
+	a.nData++
+	return nil
+}
+
+func (a *assembler) encodeConstExpr(instrs []*node) ([]byte, error) {
+	ctx := &bodyContext{a: a, locals: map[string]uint32{}}
+	if err := ctx.instrs(instrs); err != nil {
+		return nil, err
+	}
+	return append(ctx.out, 0x0B), nil
+}
+
+func (a *assembler) encodeFunc(fn *funcDef) ([]byte, error) {
+	var out []byte
+
+	// locals are run-length encoded by type
+	var groups [][2]uint64
+	for _, t := range fn.localTypes {
+		if n := len(groups); n > 0 && groups[n-1][1] == uint64(t) {
+			groups[n-1][0]++
+			continue
+		}
+		groups = append(groups, [2]uint64{1, uint64(t)})
+	}
+	out = appendUleb(out, uint64(len(groups)))
+	for _, g := range groups {
+		out = appendUleb(out, g[0])
+		out = append(out, byte(g[1]))
+	}
+
+	ctx := &bodyContext{a: a, locals: fn.localIDs}
+	if err := ctx.instrs(fn.body); err != nil {
+		return nil, err
+	}
+	if len(ctx.labels) > 0 {
+		return nil, fn.src.errorf("unterminated block in function body")
+	}
+
+	out = append(out, ctx.out...)
+	return append(out, 0x0B), nil
+}
+
+type bodyContext struct {
+	a      *assembler
+	locals map[string]uint32
+	labels []string
+	out    []byte
+}
+
+func (c *bodyContext) pushLabel(id *node) {
+	if id != nil {
+		c.labels = append(c.labels, id.tok.text)
+	} else {
+		c.labels = append(c.labels, "")
+	}
+}
+
+func (c *bodyContext) popLabel(n *node) error {
+	if len(c.labels) == 0 {
+		return n.errorf("'end' without matching block")
+	}
+	c.labels = c.labels[:len(c.labels)-1]
+	return nil
+}
+
+func (c *bodyContext) resolveLabel(n *node) (uint32, error) {
+	if n == nil || !n.isAtom() {
+		return 0, fmt.Errorf("expected label")
+	}
+	if n.isID() {
+		for i := len(c.labels) - 1; i >= 0; i-- {
+			if c.labels[i] == n.tok.text {
+				return uint32(len(c.labels) - 1 - i), nil
+			}
+		}
+		return 0, n.errorf("unknown label %s", n.tok.text)
+	}
+	idx, ok := parseIndexLiteral(n.tok.text)
+	if !ok {
+		return 0, n.errorf("invalid label %q", n.tok.text)
+	}
+	return idx, nil
+}
+
+func (c *bodyContext) resolveLocal(n *node) (uint32, error) {
+	if n == nil || !n.isAtom() {
+		return 0, fmt.Errorf("expected local index")
+	}
+	if n.isID() {
+		idx, ok := c.locals[n.tok.text]
+		if !ok {
+			return 0, n.errorf("unknown local %s", n.tok.text)
+		}
+		return idx, nil
+	}
+	idx, ok := parseIndexLiteral(n.tok.text)
+	if !ok {
+		return 0, n.errorf("invalid local index %q", n.tok.text)
+	}
+	return idx, nil
+}
+
+func isIndexAtom(n *node) bool {
+	if n.isID() {
+		return true
+	}
+	_, ok := parseIndexLiteral(n.tok.text)
+	return n.isAtom() && ok
+}
+
+// blockType reads an optional label and block signature.
+func (c *bodyContext) blockType(args []*node) ([]byte, *node, []*node, error) {
+	label, args := takeID(args)
+
+	hasSig := len(args) > 0 && (args[0].head() == "type" || args[0].head() == "param" || args[0].head() == "result")
+	if !hasSig {
+		return []byte{0x40}, label, args, nil
+	}
+
+	if args[0].head() == "result" && (len(args) < 2 || args[1].head() != "result") && len(args[0].list) == 2 {
+		t, err := parseValueType(args[0].list[1])
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return []byte{t}, label, args[1:], nil
+	}
+	if args[0].head() == "result" && len(args[0].list) == 1 {
+		return []byte{0x40}, label, args[1:], nil
+	}
+
+	idx, _, rest, err := c.a.parseTypeUse(args)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return appendSleb(nil, int64(idx)), label, rest, nil
+}
+
+// immediates encodes the immediates of op from args and returns how many nodes were consumed.
+func (c *bodyContext) immediates(name *node, op opcode, args []*node) ([]byte, int, error) {
+	atom := func() (*node, error) {
+		if len(args) == 0 || !args[0].isAtom() {
+			return nil, name.errorf("%s expects an immediate", name.tok.text)
+		}
+		return args[0], nil
+	}
+
+	switch op.imm {
+	case immNone:
+		return nil, 0, nil
+	case immI32, immI64:
+		n, err := atom()
+		if err != nil {
+			return nil, 0, err
+		}
+		bits := uint(32)
+		if op.imm == immI64 {
+			bits = 64
+		}
+		v, err := parseInt(n.tok.text, bits)
+		if err != nil {
+			return nil, 0, n.errorf("%v", err)
+		}
+		if bits == 32 {
+			return appendSleb(nil, int64(int32(uint32(v)))), 1, nil
+		}
+		return appendSleb(nil, int64(v)), 1, nil
+	case immF32, immF64:
+		n, err := atom()
+		if err != nil {
+			return nil, 0, err
+		}
+		bits := 32
+		if op.imm == immF64 {
+			bits = 64
+		}
+		v, err := parseFloat(n.tok.text, bits)
+		if err != nil {
+			return nil, 0, n.errorf("%v", err)
+		}
+		out := make([]byte, bits/8)
+		for i := range out {
+			out[i] = byte(v >> (8 * i))
+		}
+		return out, 1, nil
+	case immLocal:
+		n, err := atom()
+		if err != nil {
+			return nil, 0, err
+		}
+		idx, err := c.resolveLocal(n)
+		if err != nil {
+			return nil, 0, err
+		}
+		return appendUleb(nil, uint64(idx)), 1, nil
+	case immGlobal, immFunc:
+		n, err := atom()
+		if err != nil {
+			return nil, 0, err
+		}
+		ns := c.a.globNS
+		if op.imm == immFunc {
+			ns = c.a.funcNS
+		}
+		idx, err := ns.resolve(n)
+		if err != nil {
+			return nil, 0, err
+		}
+		return appendUleb(nil, uint64(idx)), 1, nil
+	case immLabel:
+		n, err := atom()
+		if err != nil {
+			return nil, 0, err
+		}
+		depth, err := c.resolveLabel(n)
+		if err != nil {
+			return nil, 0, err
+		}
+		return appendUleb(nil, uint64(depth)), 1, nil
+	case immLabelTable:
+		var depths []uint32
+		for _, n := range args {
+			if !n.isAtom() {
+				break
+			}
+			if _, isOp := opcodes[n.tok.text]; isOp || n.tok.text == "end" || n.tok.text == "else" {
+				break
+			}
+			depth, err := c.resolveLabel(n)
+			if err != nil {
+				return nil, 0, err
+			}
+			depths = append(depths, depth)
+		}
+		if len(depths) == 0 {
+			return nil, 0, name.errorf("br_table expects at least one label")
+		}
+		out := appendUleb(nil, uint64(len(depths)-1))
+		for _, d := range depths {
+			out = appendUleb(out, uint64(d))
+		}
+		return out, len(depths), nil
+	case immMemArg:
+		align, offset := uint64(op.align), uint64(0)
+		consumed := 0
+		for _, n := range args {
+			if !n.isAtom() {
+				break
+			}
+			text := n.tok.text
+			var err error
+			if strings.HasPrefix(text, "offset=") {
+				offset, err = parseInt(text[len("offset="):], 32)
+			} else if strings.HasPrefix(text, "align=") {
+				var bytes uint64
+				bytes, err = parseInt(text[len("align="):], 32)
+				if err == nil && (bytes == 0 || bytes&(bytes-1) != 0) {
+					err = fmt.Errorf("alignment must be a power of two")
+				}
+				align = 0
+				for ; bytes > 1; bytes >>= 1 {
+					align++
+				}
+			} else {
+				break
+			}
+			if err != nil {
+				return nil, 0, n.errorf("%v", err)
+			}
+			consumed++
+		}
+		return appendUleb(appendUleb(nil, align), offset), consumed, nil
+	case immMemIndex:
+		return []byte{0x00}, 0, nil
+	case immMemCopy:
+		return []byte{0x00, 0x00}, 0, nil
+	case immCallIndirect:
+		table := uint32(0)
+		consumed := 0
+		if len(args) > 0 && isIndexAtom(args[0]) {
+			idx, err := c.a.tableNS.resolve(args[0])
+			if err != nil {
+				return nil, 0, err
+			}
+			table = idx
+			consumed++
+		}
+		typeIdx, _, rest, err := c.a.parseTypeUse(args[consumed:])
+		if err != nil {
+			return nil, 0, err
+		}
+		consumed = len(args) - len(rest)
+		return appendUleb(appendUleb(nil, uint64(typeIdx)), uint64(table)), consumed, nil
+	}
+	return nil, 0, name.errorf("unsupported instruction %s", name.tok.text)
+}
+
+// instrs encodes a sequence of flat and folded instructions.
+func (c *bodyContext) instrs(nodes []*node) error {
+	for i := 0; i < len(nodes); i++ {
+		n := nodes[i]
+
+		if n.isList {
+			if err := c.folded(n); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !n.isAtom() {
+			return n.errorf("unexpected string in instruction sequence")
+		}
+
+		switch n.tok.text {
+		case "end", "else":
+			if n.tok.text == "end" {
+				if err := c.popLabel(n); err != nil {
+					return err
+				}
+				c.out = append(c.out, 0x0B)
+			} else {
+				if len(c.labels) == 0 {
+					return n.errorf("'else' without matching if")
+				}
+				c.out = append(c.out, 0x05)
+			}
+			// an optional repeated label may follow
+			if i+1 < len(nodes) && nodes[i+1].isID() {
+				i++
+			}
+			continue
+		}
+
+		op, ok := opcodes[n.tok.text]
+		if !ok {
+			return n.errorf("unknown instruction %q", n.tok.text)
+		}
+
+		if op.imm == immBlock {
+			bt, label, rest, err := c.blockType(nodes[i+1:])
+			if err != nil {
+				return err
+			}
+			c.out = append(c.out, op.code...)
+			c.out = append(c.out, bt...)
+			c.pushLabel(label)
+			i = len(nodes) - len(rest) - 1
+			continue
+		}
+
+		imm, consumed, err := c.immediates(n, op, nodes[i+1:])
+		if err != nil {
+			return err
+		}
+		c.out = append(c.out, op.code...)
+		c.out = append(c.out, imm...)
+		i += consumed
+	}
+	return nil
+}
+
+func (c *bodyContext) folded(n *node) error {
+	if len(n.list) == 0 || !n.list[0].isAtom() {
+		return n.errorf("expected instruction")
+	}
+	name := n.list[0]
+	args := n.list[1:]
+
+	op, ok := opcodes[name.tok.text]
+	if !ok {
+		return name.errorf("unknown instruction %q", name.tok.text)
+	}
+
+	switch name.tok.text {
+	case "block", "loop":
+		bt, label, body, err := c.blockType(args)
+		if err != nil {
+			return err
+		}
+		c.out = append(c.out, op.code...)
+		c.out = append(c.out, bt...)
+		c.pushLabel(label)
+		if err := c.instrs(body); err != nil {
+			return err
+		}
+		c.out = append(c.out, 0x0B)
+		return c.popLabel(n)
+	case "if":
+		bt, label, rest, err := c.blockType(args)
+		if err != nil {
+			return err
+		}
+
+		var thenBranch, elseBranch *node
+		var conditions []*node
+		for _, item := range rest {
+			switch item.head() {
+			case "then":
+				thenBranch = item
+			case "else":
+				elseBranch = item
+			default:
+				if thenBranch != nil {
+					return item.errorf("unexpected instruction after (then ...)")
+				}
+				conditions = append(conditions, item)
+			}
+		}
+		if thenBranch == nil {
+			return n.errorf("folded if requires a (then ...) branch")
+		}
+
+		if err := c.instrs(conditions); err != nil {
+			return err
+		}
+		c.out = append(c.out, op.code...)
+		c.out = append(c.out, bt...)
+		c.pushLabel(label)
+		if err := c.instrs(thenBranch.list[1:]); err != nil {
+			return err
+		}
+		if elseBranch != nil {
+			c.out = append(c.out, 0x05)
+			if err := c.instrs(elseBranch.list[1:]); err != nil {
+				return err
+			}
+		}
+		c.out = append(c.out, 0x0B)
+		return c.popLabel(n)
+	}
+
+	imm, consumed, err := c.immediates(name, op, args)
+	if err != nil {
+		return err
+	}
+
+	for _, operand := range args[consumed:] {
+		if !operand.isList {
+			return operand.errorf("unexpected %q in folded instruction", operand.tok.text)
+		}
+		if err := c.folded(operand); err != nil {
+			return err
+		}
+	}
+
+	c.out = append(c.out, op.code...)
+	c.out = append(c.out, imm...)
+	return nil
+}
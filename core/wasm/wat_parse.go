@@ -0,0 +1,376 @@
+package wasm
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenLParen tokenKind = iota
+	tokenRParen
+	tokenAtom
+	tokenString
+	tokenEOF
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+	col  int
+}
+
+// node is an S-expression: either a parenthesised list or a single atom/string.
+type node struct {
+	tok    token
+	list   []*node
+	isList bool
+}
+
+func (n *node) pos() string {
+	return fmt.Sprintf("%d:%d", n.tok.line, n.tok.col)
+}
+
+// head returns the keyword a list starts with, or "" for atoms and unlabeled lists.
+func (n *node) head() string {
+	if !n.isList || len(n.list) == 0 || n.list[0].isList || n.list[0].tok.kind != tokenAtom {
+		return ""
+	}
+	return n.list[0].tok.text
+}
+
+func (n *node) isAtom() bool {
+	return !n.isList && n.tok.kind == tokenAtom
+}
+
+func (n *node) isString() bool {
+	return !n.isList && n.tok.kind == tokenString
+}
+
+func (n *node) isID() bool {
+	return n.isAtom() && strings.HasPrefix(n.tok.text, "$")
+}
+
+func (n *node) errorf(format string, args ...any) error {
+	return fmt.Errorf("%s: %s", n.pos(), fmt.Sprintf(format, args...))
+}
+
+type lexer struct {
+	src  string
+	i    int
+	line int
+	col  int
+}
+
+func (l *lexer) advance(n int) {
+	for _, r := range l.src[l.i : l.i+n] {
+		if r == '\n' {
+			l.line++
+			l.col = 1
+		} else {
+			l.col++
+		}
+	}
+	l.i += n
+}
+
+func (l *lexer) skipSpaceAndComments() error {
+	for l.i < len(l.src) {
+		c := l.src[l.i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			l.advance(1)
+		case strings.HasPrefix(l.src[l.i:], ";;"):
+			end := strings.IndexByte(l.src[l.i:], '\n')
+			if end < 0 {
+				end = len(l.src) - l.i
+			}
+			l.advance(end)
+		case strings.HasPrefix(l.src[l.i:], "(;"):
+			line, col := l.line, l.col
+			depth := 0
+			for {
+				if l.i >= len(l.src) {
+					return fmt.Errorf("%d:%d: unterminated block comment", line, col)
+				}
+				if strings.HasPrefix(l.src[l.i:], "(;") {
+					depth++
+					l.advance(2)
+				} else if strings.HasPrefix(l.src[l.i:], ";)") {
+					depth--
+					l.advance(2)
+					if depth == 0 {
+						break
+					}
+				} else {
+					l.advance(1)
+				}
+			}
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (l *lexer) next() (token, error) {
+	if err := l.skipSpaceAndComments(); err != nil {
+		return token{}, err
+	}
+
+	tok := token{line: l.line, col: l.col}
+	if l.i >= len(l.src) {
+		tok.kind = tokenEOF
+		return tok, nil
+	}
+
+	switch c := l.src[l.i]; c {
+	case '(':
+		tok.kind = tokenLParen
+		l.advance(1)
+		return tok, nil
+	case ')':
+		tok.kind = tokenRParen
+		l.advance(1)
+		return tok, nil
+	case '"':
+		text, err := l.readString()
+		if err != nil {
+			return token{}, fmt.Errorf("%d:%d: %w", tok.line, tok.col, err)
+		}
+		tok.kind = tokenString
+		tok.text = text
+		return tok, nil
+	}
+
+	start := l.i
+	for l.i < len(l.src) {
+		c := l.src[l.i]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '(' || c == ')' || c == '"' || c == ';' {
+			break
+		}
+		l.advance(1)
+	}
+	tok.kind = tokenAtom
+	tok.text = l.src[start:l.i]
+	return tok, nil
+}
+
+func (l *lexer) readString() (string, error) {
+	var sb strings.Builder
+	l.advance(1)
+	for {
+		if l.i >= len(l.src) {
+			return "", fmt.Errorf("unterminated string")
+		}
+		c := l.src[l.i]
+		if c == '"' {
+			l.advance(1)
+			return sb.String(), nil
+		}
+		if c == '\n' {
+			return "", fmt.Errorf("newline in string")
+		}
+		if c != '\\' {
+			sb.WriteByte(c)
+			l.advance(1)
+			continue
+		}
+
+		if l.i+1 >= len(l.src) {
+			return "", fmt.Errorf("unterminated escape sequence")
+		}
+		e := l.src[l.i+1]
+		switch e {
+		case 'n':
+			sb.WriteByte('\n')
+		case 't':
+			sb.WriteByte('\t')
+		case 'r':
+			sb.WriteByte('\r')
+		case '\\', '\'', '"':
+			sb.WriteByte(e)
+		case 'u':
+			end := strings.IndexByte(l.src[l.i:], '}')
+			if end < 0 || l.i+2 >= len(l.src) || l.src[l.i+2] != '{' {
+				return "", fmt.Errorf("invalid unicode escape")
+			}
+			r, err := strconv.ParseUint(strings.ReplaceAll(l.src[l.i+3:l.i+end], "_", ""), 16, 32)
+			if err != nil || !utf8.ValidRune(rune(r)) {
+				return "", fmt.Errorf("invalid unicode escape")
+			}
+			sb.WriteRune(rune(r))
+			l.advance(end + 1)
+			continue
+		default:
+			if l.i+2 >= len(l.src) {
+				return "", fmt.Errorf("invalid escape sequence")
+			}
+			b, err := strconv.ParseUint(l.src[l.i+1:l.i+3], 16, 8)
+			if err != nil {
+				return "", fmt.Errorf("invalid escape sequence \\%s", l.src[l.i+1:l.i+3])
+			}
+			sb.WriteByte(byte(b))
+			l.advance(3)
+			continue
+		}
+		l.advance(2)
+	}
+}
+
+// parseSExpressions reads every top-level S-expression in src.
+func parseSExpressions(src string) ([]*node, error) {
+	l := &lexer{src: src, line: 1, col: 1}
+
+	var stack [][]*node
+	var opens []token
+	var top []*node
+
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok.kind {
+		case tokenEOF:
+			if len(stack) > 0 {
+				open := opens[len(opens)-1]
+				return nil, fmt.Errorf("%d:%d: unclosed parenthesis", open.line, open.col)
+			}
+			return top, nil
+		case tokenLParen:
+			stack = append(stack, nil)
+			opens = append(opens, tok)
+		case tokenRParen:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("%d:%d: unexpected ')'", tok.line, tok.col)
+			}
+			list := &node{tok: opens[len(opens)-1], list: stack[len(stack)-1], isList: true}
+			stack = stack[:len(stack)-1]
+			opens = opens[:len(opens)-1]
+			if len(stack) == 0 {
+				top = append(top, list)
+			} else {
+				stack[len(stack)-1] = append(stack[len(stack)-1], list)
+			}
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("%d:%d: unexpected %q outside of a list", tok.line, tok.col, tok.text)
+			}
+			stack[len(stack)-1] = append(stack[len(stack)-1], &node{tok: tok})
+		}
+	}
+}
+
+// parseInt parses a WAT integer literal into a two's complement value of the given width.
+func parseInt(text string, bits uint) (uint64, error) {
+	s := strings.ReplaceAll(text, "_", "")
+	neg := false
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+
+	base := 10
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		base = 16
+		s = s[2:]
+	}
+
+	v, err := strconv.ParseUint(s, base, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer %q", text)
+	}
+
+	if neg {
+		if bits < 64 && v > 1<<(bits-1) || bits == 64 && v > 1<<63 {
+			return 0, fmt.Errorf("integer %q out of range", text)
+		}
+		v = -v
+	} else if bits < 64 && v > 1<<bits-1 {
+		return 0, fmt.Errorf("integer %q out of range", text)
+	}
+
+	if bits < 64 {
+		v &= 1<<bits - 1
+	}
+	return v, nil
+}
+
+func parseIndexLiteral(text string) (uint32, bool) {
+	v, err := strconv.ParseUint(strings.ReplaceAll(text, "_", ""), 0, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint32(v), true
+}
+
+// parseFloat parses a WAT float literal and returns its IEEE 754 bit pattern.
+func parseFloat(text string, bits int) (uint64, error) {
+	s := strings.ReplaceAll(text, "_", "")
+	neg := false
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+
+	var result uint64
+	switch {
+	case s == "inf":
+		if bits == 32 {
+			result = uint64(math.Float32bits(float32(math.Inf(1))))
+		} else {
+			result = math.Float64bits(math.Inf(1))
+		}
+	case s == "nan":
+		if bits == 32 {
+			result = 0x7FC00000
+		} else {
+			result = 0x7FF8000000000000
+		}
+	case strings.HasPrefix(s, "nan:0x"):
+		payload, err := strconv.ParseUint(s[len("nan:0x"):], 16, 64)
+		if err != nil || payload == 0 {
+			return 0, fmt.Errorf("invalid nan payload %q", text)
+		}
+		if bits == 32 {
+			if payload >= 1<<23 {
+				return 0, fmt.Errorf("nan payload %q out of range", text)
+			}
+			result = 0x7F800000 | payload
+		} else {
+			if payload >= 1<<52 {
+				return 0, fmt.Errorf("nan payload %q out of range", text)
+			}
+			result = 0x7FF0000000000000 | payload
+		}
+	default:
+		if (strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X")) && !strings.ContainsAny(s, "pP") {
+			s += "p0"
+		}
+		v, err := strconv.ParseFloat(s, bits)
+		if err != nil {
+			return 0, fmt.Errorf("invalid float %q", text)
+		}
+		if bits == 32 {
+			result = uint64(math.Float32bits(float32(v)))
+		} else {
+			result = math.Float64bits(v)
+		}
+	}
+
+	if neg {
+		if bits == 32 {
+			result |= 1 << 31
+		} else {
+			result |= 1 << 63
+		}
+	}
+	return result, nil
+}
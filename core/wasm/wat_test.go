@@ -0,0 +1,319 @@
+package wasm_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/shinzonetwork/view-creator/core/wasm"
+)
+
+func TestAssembleMinimalModule(t *testing.T) {
+	src := `(module (func (export "f") (result i32) i32.const 42))`
+
+	out, err := wasm.Assemble([]byte(src))
+	if err != nil {
+		t.Fatalf("assemble failed: %v", err)
+	}
+
+	expected := []byte{
+		0x00, 0x61, 0x73, 0x6D, 0x01, 0x00, 0x00, 0x00,
+		0x01, 0x05, 0x01, 0x60, 0x00, 0x01, 0x7F, // type section
+		0x03, 0x02, 0x01, 0x00, // function section
+		0x07, 0x05, 0x01, 0x01, 'f', 0x00, 0x00, // export section
+		0x0A, 0x06, 0x01, 0x04, 0x00, 0x41, 0x2A, 0x0B, // code section
+	}
+	if !bytes.Equal(out, expected) {
+		t.Errorf("unexpected binary.\nGot:      % x\nExpected: % x", out, expected)
+	}
+}
+
+func TestAssembleFoldedAndFlatAreEquivalent(t *testing.T) {
+	folded := `(module
+  (import "lens" "next" (func $next (result i32)))
+  (func $f (param $x i32) (result i32)
+    (if (result i32) (i32.eqz (local.get $x))
+      (then (call $next))
+      (else (i32.load offset=4 (local.get $x))))))`
+
+	flat := `(module
+  (import "lens" "next" (func $next (result i32)))
+  (func $f (param $x i32) (result i32)
+    local.get $x
+    i32.eqz
+    if (result i32)
+      call $next
+    else
+      local.get $x
+      i32.load offset=4
+    end))`
+
+	a, err := wasm.Assemble([]byte(folded))
+	if err != nil {
+		t.Fatalf("assemble folded failed: %v", err)
+	}
+	b, err := wasm.Assemble([]byte(flat))
+	if err != nil {
+		t.Fatalf("assemble flat failed: %v", err)
+	}
+	if !bytes.Equal(a, b) {
+		t.Errorf("folded and flat forms differ.\nFolded: % x\nFlat:   % x", a, b)
+	}
+}
+
+func TestAssembleReportsPosition(t *testing.T) {
+	src := "(module\n  (func (result i32)\n    i32.bogus))"
+
+	_, err := wasm.Assemble([]byte(src))
+	if err == nil {
+		t.Fatal("expected an error for an unknown instruction")
+	}
+	if !strings.Contains(err.Error(), "3:5") || !strings.Contains(err.Error(), "i32.bogus") {
+		t.Errorf("expected error to point at 3:5 and name the instruction, got: %v", err)
+	}
+}
+
+func TestIsWat(t *testing.T) {
+	if !wasm.IsWat([]byte("  ;; lens\n(module)")) {
+		t.Error("expected text module to be detected as wat")
+	}
+	if wasm.IsWat([]byte("\x00asm\x01\x00\x00\x00")) {
+		t.Error("expected binary module not to be detected as wat")
+	}
+}
+
+var wasmHeader = []byte{0x00, 0x61, 0x73, 0x6D, 0x01, 0x00, 0x00, 0x00}
+
+func module(sections ...[]byte) []byte {
+	out := append([]byte{}, wasmHeader...)
+	for _, s := range sections {
+		out = append(out, s...)
+	}
+	return out
+}
+
+// assembleCase pairs a text module with its known-good binary encoding.
+type assembleCase struct {
+	name     string
+	src      string
+	expected []byte
+}
+
+func assembleCases(t *testing.T, tests []assembleCase) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := wasm.Assemble([]byte(tt.src))
+			if err != nil {
+				t.Fatalf("assemble failed: %v", err)
+			}
+			if !bytes.Equal(out, tt.expected) {
+				t.Errorf("unexpected binary.\nGot:      % x\nExpected: % x", out, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAssembleModuleFields(t *testing.T) {
+	assembleCases(t, []assembleCase{
+		{
+			name: "memory with maximum",
+			src:  `(module (memory 1 2))`,
+			expected: module(
+				[]byte{0x05, 0x04, 0x01, 0x01, 0x01, 0x02}, // memory section
+			),
+		},
+		{
+			name: "exported memory",
+			src:  `(module (memory (export "mem") 1))`,
+			expected: module(
+				[]byte{0x05, 0x03, 0x01, 0x00, 0x01},                      // memory section
+				[]byte{0x07, 0x07, 0x01, 0x03, 'm', 'e', 'm', 0x02, 0x00}, // export section
+			),
+		},
+		{
+			name: "table",
+			src:  `(module (table 2 funcref))`,
+			expected: module(
+				[]byte{0x04, 0x04, 0x01, 0x70, 0x00, 0x02}, // table section
+			),
+		},
+		{
+			name: "active elem",
+			src:  `(module (table 1 funcref) (func) (elem (i32.const 0) 0))`,
+			expected: module(
+				[]byte{0x01, 0x04, 0x01, 0x60, 0x00, 0x00},                   // type section
+				[]byte{0x03, 0x02, 0x01, 0x00},                               // function section
+				[]byte{0x04, 0x04, 0x01, 0x70, 0x00, 0x01},                   // table section
+				[]byte{0x09, 0x07, 0x01, 0x00, 0x41, 0x00, 0x0B, 0x01, 0x00}, // element section
+				[]byte{0x0A, 0x04, 0x01, 0x02, 0x00, 0x0B},                   // code section
+			),
+		},
+		{
+			name: "passive and declared elems",
+			src:  `(module (func) (elem func 0) (elem declare func 0))`,
+			expected: module(
+				[]byte{0x01, 0x04, 0x01, 0x60, 0x00, 0x00},                               // type section
+				[]byte{0x03, 0x02, 0x01, 0x00},                                           // function section
+				[]byte{0x09, 0x09, 0x02, 0x01, 0x00, 0x01, 0x00, 0x03, 0x00, 0x01, 0x00}, // element section
+				[]byte{0x0A, 0x04, 0x01, 0x02, 0x00, 0x0B},                               // code section
+			),
+		},
+		{
+			name: "active data",
+			src:  `(module (memory 1) (data (i32.const 8) "hi"))`,
+			expected: module(
+				[]byte{0x05, 0x03, 0x01, 0x00, 0x01},                             // memory section
+				[]byte{0x0B, 0x08, 0x01, 0x00, 0x41, 0x08, 0x0B, 0x02, 'h', 'i'}, // data section
+			),
+		},
+		{
+			name: "passive data",
+			src:  `(module (memory 1) (data "x" "y"))`,
+			expected: module(
+				[]byte{0x05, 0x03, 0x01, 0x00, 0x01},           // memory section
+				[]byte{0x0B, 0x05, 0x01, 0x01, 0x02, 'x', 'y'}, // data section
+			),
+		},
+		{
+			name: "globals",
+			src:  `(module (global (mut i32) (i32.const -1)) (global i64 (i64.const 128)))`,
+			expected: module(
+				[]byte{0x06, 0x0C, 0x02,
+					0x7F, 0x01, 0x41, 0x7F, 0x0B,
+					0x7E, 0x00, 0x42, 0x80, 0x01, 0x0B}, // global section
+			),
+		},
+		{
+			name: "global initialized from an import",
+			src:  `(module (import "env" "g" (global i32)) (global i32 (global.get 0)))`,
+			expected: module(
+				[]byte{0x02, 0x0A, 0x01, 0x03, 'e', 'n', 'v', 0x01, 'g', 0x03, 0x7F, 0x00}, // import section
+				[]byte{0x06, 0x06, 0x01, 0x7F, 0x00, 0x23, 0x00, 0x0B},                     // global section
+			),
+		},
+	})
+}
+
+func TestAssembleInstructions(t *testing.T) {
+	assembleCases(t, []assembleCase{
+		{
+			name: "br_table",
+			src: `(module (func (param i32)
+  block $outer
+    block $inner
+      local.get 0
+      br_table $inner $outer $inner
+    end
+  end))`,
+			expected: module(
+				[]byte{0x01, 0x05, 0x01, 0x60, 0x01, 0x7F, 0x00}, // type section
+				[]byte{0x03, 0x02, 0x01, 0x00},                   // function section
+				[]byte{0x0A, 0x11, 0x01, 0x0F, 0x00,
+					0x02, 0x40, 0x02, 0x40,
+					0x20, 0x00,
+					0x0E, 0x02, 0x00, 0x01, 0x00,
+					0x0B, 0x0B, 0x0B}, // code section
+			),
+		},
+		{
+			name: "call_indirect",
+			src: `(module
+  (type (func (param i32) (result i32)))
+  (table 1 funcref)
+  (func (result i32)
+    i32.const 7
+    i32.const 0
+    call_indirect (type 0)))`,
+			expected: module(
+				[]byte{0x01, 0x0A, 0x02, 0x60, 0x01, 0x7F, 0x01, 0x7F, 0x60, 0x00, 0x01, 0x7F}, // type section
+				[]byte{0x03, 0x02, 0x01, 0x01},             // function section
+				[]byte{0x04, 0x04, 0x01, 0x70, 0x00, 0x01}, // table section
+				[]byte{0x0A, 0x0B, 0x01, 0x09, 0x00,
+					0x41, 0x07, 0x41, 0x00,
+					0x11, 0x00, 0x00,
+					0x0B}, // code section
+			),
+		},
+	})
+}
+
+func TestAssembleFloatLiterals(t *testing.T) {
+	// each case is a function returning one constant
+	f32 := func(literal string, bits ...byte) assembleCase {
+		return assembleCase{
+			name: "f32 " + literal,
+			src:  `(module (func (result f32) f32.const ` + literal + `))`,
+			expected: module(
+				[]byte{0x01, 0x05, 0x01, 0x60, 0x00, 0x01, 0x7D},
+				[]byte{0x03, 0x02, 0x01, 0x00},
+				append(append([]byte{0x0A, 0x09, 0x01, 0x07, 0x00, 0x43}, bits...), 0x0B),
+			),
+		}
+	}
+	f64 := func(literal string, bits ...byte) assembleCase {
+		return assembleCase{
+			name: "f64 " + literal,
+			src:  `(module (func (result f64) f64.const ` + literal + `))`,
+			expected: module(
+				[]byte{0x01, 0x05, 0x01, 0x60, 0x00, 0x01, 0x7C},
+				[]byte{0x03, 0x02, 0x01, 0x00},
+				append(append([]byte{0x0A, 0x0D, 0x01, 0x0B, 0x00, 0x44}, bits...), 0x0B),
+			),
+		}
+	}
+
+	tests := []assembleCase{
+		f32("nan", 0x00, 0x00, 0xC0, 0x7F),
+		f32("-nan", 0x00, 0x00, 0xC0, 0xFF),
+		f32("nan:0x200000", 0x00, 0x00, 0xA0, 0x7F),
+		f32("inf", 0x00, 0x00, 0x80, 0x7F),
+		f32("-inf", 0x00, 0x00, 0x80, 0xFF),
+		f32("0x1p-1", 0x00, 0x00, 0x00, 0x3F),
+		f32("0x1.8p1", 0x00, 0x00, 0x40, 0x40),
+		f64("nan", 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xF8, 0x7F),
+		f64("-inf", 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xF0, 0xFF),
+		f64("-0x1p+0", 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xF0, 0xBF),
+		f64("0x10", 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x30, 0x40),
+		f64("1_000.5", 0x00, 0x00, 0x00, 0x00, 0x00, 0x44, 0x8F, 0x40),
+	}
+	assembleCases(t, tests)
+}
+
+func TestAssembleStringEscapes(t *testing.T) {
+	tests := []struct {
+		name     string
+		literal  string
+		expected []byte
+	}{
+		{"plain", `"abc"`, []byte("abc")},
+		{"control characters", `"\n\t\r"`, []byte{'\n', '\t', '\r'}},
+		{"quotes and backslash", `"\"\'\\"`, []byte{'"', '\'', '\\'}},
+		{"hex bytes", `"\00\ff\7A"`, []byte{0x00, 0xFF, 0x7A}},
+		{"unicode", `"\u{e9}\u{1F600}"`, []byte{0xC3, 0xA9, 0xF0, 0x9F, 0x98, 0x80}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := wasm.Assemble([]byte(`(module (memory 1) (data (i32.const 0) ` + tt.literal + `))`))
+			if err != nil {
+				t.Fatalf("assemble failed: %v", err)
+			}
+
+			segment := append([]byte{0x01, 0x00, 0x41, 0x00, 0x0B, byte(len(tt.expected))}, tt.expected...)
+			expected := module(
+				[]byte{0x05, 0x03, 0x01, 0x00, 0x01},
+				append([]byte{0x0B, byte(len(segment))}, segment...),
+			)
+			if !bytes.Equal(out, expected) {
+				t.Errorf("unexpected binary.\nGot:      % x\nExpected: % x", out, expected)
+			}
+		})
+	}
+
+	for _, literal := range []string{`"\q"`, `"\u{110000}"`, `"\u{41"`} {
+		if _, err := wasm.Assemble([]byte(`(module (memory 1) (data (i32.const 0) ` + literal + `))`)); err == nil {
+			t.Errorf("expected an error for %s", literal)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+package tools
+
+import "embed"
+
+//go:embed lens
+var LensTemplates embed.FS